@@ -1,15 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/777genius/claude-notifications/internal/config"
 	"github.com/777genius/claude-notifications/internal/errorhandler"
 	"github.com/777genius/claude-notifications/internal/hooks"
 	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/webhook"
 )
 
+// selfTestTimeout bounds the test-webhook command
+const selfTestTimeout = 30 * time.Second
+
 const version = "1.3.0"
 
 func main() {
@@ -37,6 +44,8 @@ func main() {
 			os.Exit(1)
 		}
 		handleHook(os.Args[2])
+	case "test-webhook":
+		testWebhook()
 	case "version", "--version", "-v":
 		fmt.Printf("claude-notifications v%s\n", version)
 	case "help", "--help", "-h":
@@ -76,6 +85,37 @@ func handleHook(hookEvent string) {
 	}
 }
 
+func testWebhook() {
+	pluginRoot := getPluginRoot()
+
+	if _, err := logging.InitLogger(pluginRoot); err != nil {
+		errorhandler.HandleCriticalError(err, "Failed to initialize logger")
+		os.Exit(1)
+	}
+	defer logging.Close()
+
+	cfg, err := config.LoadFromPluginRoot(pluginRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	sender, err := webhook.NewSender(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid config: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), selfTestTimeout)
+	defer cancel()
+
+	if err := sender.SelfTest(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Webhook self-test failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Webhook self-test sent successfully")
+}
+
 func getPluginRoot() string {
 	// Try CLAUDE_PLUGIN_ROOT environment variable first
 	if root := os.Getenv("CLAUDE_PLUGIN_ROOT"); root != "" {
@@ -109,12 +149,14 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Println("  claude-notifications handle-hook <HookName>")
+	fmt.Println("  claude-notifications test-webhook")
 	fmt.Println("  claude-notifications version")
 	fmt.Println("  claude-notifications help")
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  handle-hook <HookName>  Handle a Claude Code hook event")
 	fmt.Println("                          HookName: PreToolUse, Stop, SubagentStop, Notification")
+	fmt.Println("  test-webhook            Send a test notification to every webhook destination")
 	fmt.Println("  version                 Show version information")
 	fmt.Println("  help                    Show this help message")
 	fmt.Println()
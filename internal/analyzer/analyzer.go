@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/777genius/claude-notifications/internal/config"
@@ -41,6 +42,24 @@ const (
 	StatusUnknown             Status = "unknown"
 )
 
+// builtinStatuses are the statuses produced by the analyzer itself
+var builtinStatuses = []Status{
+	StatusTaskComplete, StatusReviewComplete, StatusQuestion, StatusPlanReady,
+	StatusSessionLimitReached, StatusAPIError, StatusUnknown,
+}
+
+// RegisterStatus registers a custom status with its title, emoji and color,
+// so formatters and status lookups use them instead of the unknown-status defaults
+// Built-in statuses can't be registered; override them in the statuses config instead
+func RegisterStatus(status Status, info config.StatusInfo) error {
+	for _, builtin := range builtinStatuses {
+		if status == builtin {
+			return fmt.Errorf("status %s is built in", status)
+		}
+	}
+	return config.RegisterStatus(string(status), info)
+}
+
 // AnalyzeTranscript analyzes a transcript file and determines the current status
 func AnalyzeTranscript(transcriptPath string, cfg *config.Config) (Status, error) {
 	// Parse JSONL file
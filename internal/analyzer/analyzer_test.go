@@ -752,3 +752,23 @@ func TestContains(t *testing.T) {
 		t.Error("expected contains not to find anything in empty slice")
 	}
 }
+
+func TestRegisterStatus(t *testing.T) {
+	status := Status("deploy_finished")
+	t.Cleanup(func() { config.UnregisterStatus(string(status)) })
+
+	if err := RegisterStatus(status, config.StatusInfo{Title: "Deploy Finished", Emoji: "🚀"}); err != nil {
+		t.Fatalf("Expected custom status to register, got %v", err)
+	}
+	info, ok := config.DefaultConfig().GetStatusInfo(string(status))
+	if !ok || info.Title != "Deploy Finished" {
+		t.Errorf("Expected registered status info, got %+v (found %v)", info, ok)
+	}
+
+	if err := RegisterStatus(StatusTaskComplete, config.StatusInfo{Title: "Done"}); err == nil {
+		t.Error("Expected built-in status registration to fail")
+	}
+	if err := RegisterStatus(status, config.StatusInfo{Color: "blue"}); err == nil {
+		t.Error("Expected invalid color to be rejected")
+	}
+}
@@ -3,8 +3,14 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/777genius/claude-notifications/internal/platform"
 )
@@ -13,15 +19,68 @@ import (
 type Config struct {
 	Notifications NotificationsConfig   `json:"notifications"`
 	Statuses      map[string]StatusInfo `json:"statuses"`
+	State         StateConfig           `json:"state"`
+	LogLevel      string                `json:"logLevel"` // Minimum level written to notification-debug.log: debug, info (default), warn, error
 }
 
+// StateConfig represents session state storage settings
+type StateConfig struct {
+	Backend       string `json:"backend"`       // "file" (default), "sqlite", or "memory" (not persisted between hook runs)
+	Path          string `json:"path"`          // SQLite database path, default: claude-notifications-state.db in dir if set, otherwise in the temp dir
+	Dir           string `json:"dir"`           // Directory for state, lock and webhook spool files, default: <temp>/claude-notifications
+	EncryptionKey string `json:"encryptionKey"` // Passphrase for AES-GCM encryption of file-backed state; empty stores plaintext JSON
+}
+
+// hexColorPattern matches #rrggbb colors accepted as status color overrides
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Priorities are the notification priority names accepted as status priority overrides, lowest first
+var Priorities = []string{"min", "low", "default", "high", "urgent"}
+
+// isPriority reports whether name is one of Priorities
+func isPriority(name string) bool {
+	for _, p := range Priorities {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CustomPayloadFields are the fields of the built-in JSON webhook payload that fieldMap can rename
+var CustomPayloadFields = []string{"status", "message", "timestamp", "session_id", "source", "title", "project", "git_branch", "git_commit"}
+
+// isCustomPayloadField reports whether field is one of CustomPayloadFields
+func isCustomPayloadField(field string) bool {
+	for _, f := range CustomPayloadFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// StateDirEnv overrides State.Dir when set
+const StateDirEnv = "CLAUDE_NOTIFICATIONS_STATE_DIR"
+
+// DisabledEnv turns off all notifications when set, regardless of config
+const DisabledEnv = "CLAUDE_NOTIFICATIONS_DISABLED"
+
 // NotificationsConfig represents notification settings
 type NotificationsConfig struct {
 	Desktop                                     DesktopConfig `json:"desktop"`
 	Webhook                                     WebhookConfig `json:"webhook"`
 	SuppressQuestionAfterTaskCompleteSeconds    int           `json:"suppressQuestionAfterTaskCompleteSeconds"`
 	SuppressQuestionAfterAnyNotificationSeconds int           `json:"suppressQuestionAfterAnyNotificationSeconds"`
-	NotifyOnSubagentStop                        bool          `json:"notifyOnSubagentStop"` // Send notifications when subagents (Task tool) complete, default: false
+	NotifyOnSubagentStop                        bool          `json:"notifyOnSubagentStop"`           // Send notifications when subagents (Task tool) complete, default: false
+	MessageNormalization                        []string      `json:"messageNormalization"`           // Extra rules for duplicate message comparison: strip-emoji, collapse-whitespace, strip-markdown, strip-punctuation
+	DedupLockTTLSeconds                         int           `json:"dedupLockTTLSeconds"`            // How long a hook lock blocks duplicate hook runs, default: 2
+	DedupContentLockTTLSeconds                  int           `json:"dedupContentLockTTLSeconds"`     // How long a content lock holds back identical messages, default: 5
+	MutedStatuses                               []string      `json:"mutedStatuses"`                  // Statuses that never produce a notification, e.g. review_complete
+	CleanupMaxAgeSeconds                        int           `json:"cleanupMaxAgeSeconds"`           // Lock and state files older than this are removed on cleanup, default: 60
+	MinNotificationIntervalSeconds              int           `json:"minNotificationIntervalSeconds"` // At most one notification of any kind per session per interval, default: 0 (off)
+	DuplicateMessageWindowSeconds               int           `json:"duplicateMessageWindowSeconds"`  // Identical text is sent at most once per session per window, default: suppressQuestionAfterAnyNotificationSeconds
+	DedupRecentMessages                         bool          `json:"dedupRecentMessages"`            // Compare against the session's last 20 messages within the window, not just the last one, default: false
 }
 
 // DesktopConfig represents desktop notification settings
@@ -34,43 +93,182 @@ type DesktopConfig struct {
 
 // WebhookConfig represents webhook settings
 type WebhookConfig struct {
-	Enabled        bool                 `json:"enabled"`
-	Preset         string               `json:"preset"`
-	URL            string               `json:"url"`
-	ChatID         string               `json:"chat_id"`
-	Format         string               `json:"format"`
-	Headers        map[string]string    `json:"headers"`
-	Retry          RetryConfig          `json:"retry"`
-	CircuitBreaker CircuitBreakerConfig `json:"circuitBreaker"`
-	RateLimit      RateLimitConfig      `json:"rateLimit"`
+	Enabled           bool                 `json:"enabled"`
+	Preset            string               `json:"preset"`
+	URL               string               `json:"url"`
+	ChatID            string               `json:"chat_id"`          // Telegram chat ID, or Slack channel ID with slackThreads
+	Topic             string               `json:"topic"`            // ntfy topic or Zulip topic
+	Stream            string               `json:"stream"`           // Zulip stream
+	Token             string               `json:"token"`            // Pushover or Gotify application token, or OpsGenie API key
+	User              string               `json:"user"`             // Pushover user or group key
+	RoutingKey        string               `json:"routing_key"`      // PagerDuty integration key
+	SlackBlocks       bool                 `json:"slackBlocks"`      // Slack: use the Block Kit layout instead of legacy attachments
+	SlackThreads      bool                 `json:"slackThreads"`     // Slack: reply in one thread per session, needs the chat.postMessage API and chat_id
+	DiscordButtonURL  string               `json:"discordButtonUrl"` // Discord: link button URL, {sessionId} is replaced with the session ID
+	Mention           string               `json:"mention"`          // Slack user/group ID or Discord user ID (&ID for a role) to @mention
+	MentionStatuses   []string             `json:"mentionStatuses"`  // statuses that trigger the mention, default: question
+	Template          string               `json:"template"`         // Go text/template payload body, used with format "template"
+	FooterTemplate    string               `json:"footerTemplate"`   // Go text/template for the formatters' footer line, default "Session: {{.SessionID}}" plus project and branch
+	FieldMap          map[string]string    `json:"fieldMap"`         // renames fields of the built-in JSON payload, e.g. {"status": "event"}
+	Format            string               `json:"format"`
+	Headers           map[string]string    `json:"headers"`
+	UserAgent         string               `json:"userAgent"`         // default: claude-notifications/1.0
+	CorrelationHeader string               `json:"correlationHeader"` // header carrying the session ID, e.g. X-Correlation-ID
+	SuccessMatch      SuccessMatchConfig   `json:"successMatch"`
+	MessageRefPath    string               `json:"messageRefPath"`     // dot-separated path to the message ID in the success response, e.g. "result.message_id"
+	DeleteOnEnd       bool                 `json:"deleteOnSessionEnd"` // delete the session's last recorded message (see messageRefPath) on SessionEnd
+	Retry             RetryConfig          `json:"retry"`
+	CircuitBreaker    CircuitBreakerConfig `json:"circuitBreaker"`
+	RateLimit         RateLimitConfig      `json:"rateLimit"`
+	Signing           SigningConfig        `json:"signing"`
+	Spool             SpoolConfig          `json:"spool"`
+	QuietHours        QuietHoursConfig     `json:"quietHours"`
+	Batch             BatchConfig          `json:"batch"`
+	Proxy             string               `json:"proxy"`          // http://, https:// or socks5:// proxy URL; empty uses HTTPS_PROXY/NO_PROXY
+	Timeout           string               `json:"timeout"`        // per-request HTTP timeout, e.g. "30s", default "10s"
+	MaxConcurrency    int                  `json:"maxConcurrency"` // async sends in flight at once, further sends queue; default 4
+	MaxEventAge       string               `json:"maxEventAge"`    // drop notifications whose event is older than this, e.g. "10m"; default: off
+	AuditLog          string               `json:"auditLog"`       // JSONL file recording every delivery outcome; default: off
+	TLS               TLSConfig            `json:"tls"`
+	PayloadLimit      PayloadLimitConfig   `json:"payloadLimit"`
+	Compression       CompressionConfig    `json:"compression"`
+	DryRun            bool                 `json:"dryRun"`                 // Build and log payloads without sending them
+	VerboseLogging    bool                 `json:"verboseLogging"`         // Log request and response bodies at debug level, with secrets redacted
+	RedactHeaders     []string             `json:"redactHeaders"`          // Headers hidden in verbose logs besides Authorization, cookies and API keys
+	Destinations      []WebhookDestination `json:"destinations,omitempty"` // Optional fan-out targets, overrides preset/url above
+	Fallbacks         []WebhookDestination `json:"fallbacks,omitempty"`    // Tried in order when no routed destination delivers
+	Routes            map[string]string    `json:"routes,omitempty"`       // status -> destination name; unmapped statuses go to every destination
+}
+
+// WebhookDestination represents a single webhook endpoint
+type WebhookDestination struct {
+	Name             string             `json:"name"`
+	Preset           string             `json:"preset"`
+	URL              string             `json:"url"`
+	ChatID           string             `json:"chat_id"`
+	Topic            string             `json:"topic"`
+	Stream           string             `json:"stream"`
+	Token            string             `json:"token"`
+	User             string             `json:"user"`
+	RoutingKey       string             `json:"routing_key"`
+	SlackBlocks      bool               `json:"slackBlocks"`
+	SlackThreads     bool               `json:"slackThreads"`
+	DiscordButtonURL string             `json:"discordButtonUrl"`
+	Mention          string             `json:"mention"`
+	MentionStatuses  []string           `json:"mentionStatuses"`
+	Template         string             `json:"template"`
+	FooterTemplate   string             `json:"footerTemplate"`
+	FieldMap         map[string]string  `json:"fieldMap"`
+	Format           string             `json:"format"`
+	Headers          map[string]string  `json:"headers"`
+	SuccessMatch     SuccessMatchConfig `json:"successMatch"`
+	MessageRefPath   string             `json:"messageRefPath"`
+}
+
+// SuccessMatchConfig describes a response body check that 2xx responses must pass
+// Both checks are optional; when set, all configured checks must match
+type SuccessMatchConfig struct {
+	JSONPath string `json:"jsonPath"` // dot-separated path into the JSON body, e.g. "ok" or "result.status"
+	Equals   string `json:"equals"`   // expected value at jsonPath, compared as text (true, 1, "sent")
+	Regex    string `json:"regex"`    // regular expression the raw body must match
 }
 
 // RetryConfig represents retry settings
 type RetryConfig struct {
-	Enabled        bool   `json:"enabled"`
-	MaxAttempts    int    `json:"maxAttempts"`
-	InitialBackoff string `json:"initialBackoff"` // e.g. "1s"
-	MaxBackoff     string `json:"maxBackoff"`     // e.g. "10s"
+	Enabled        bool             `json:"enabled"`
+	MaxAttempts    int              `json:"maxAttempts"`
+	InitialBackoff string           `json:"initialBackoff"` // e.g. "1s"
+	MaxBackoff     string           `json:"maxBackoff"`     // e.g. "10s"
+	Jitter         string           `json:"jitter"`         // "equal" (default), "full" or "none"
+	MaxElapsedTime string           `json:"maxElapsedTime"` // total retry budget, e.g. "30s"; empty means no limit
+	QuickRetry     QuickRetryConfig `json:"quickRetry"`     // fast retries for DNS and connection-refused errors
+}
+
+// QuickRetryConfig represents short fixed-delay retries for transient connection failures
+// They run before, and don't count towards, the regular exponential retries
+type QuickRetryConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Attempts int    `json:"attempts"` // default: 2
+	Delay    string `json:"delay"`    // e.g. "200ms", default "200ms"
 }
 
 // CircuitBreakerConfig represents circuit breaker settings
 type CircuitBreakerConfig struct {
-	Enabled          bool   `json:"enabled"`
-	FailureThreshold int    `json:"failureThreshold"` // failures before opening
-	Timeout          string `json:"timeout"`          // time to wait in open state, e.g. "30s"
-	SuccessThreshold int    `json:"successThreshold"` // successes needed in half-open
+	Enabled           bool   `json:"enabled"`
+	FailureThreshold  int    `json:"failureThreshold"`  // failures before opening
+	Timeout           string `json:"timeout"`           // time to wait in open state, e.g. "30s"
+	SuccessThreshold  int    `json:"successThreshold"`  // successes needed in half-open
+	MaxHalfOpenProbes int    `json:"maxHalfOpenProbes"` // concurrent requests allowed in half-open, 0 = unlimited
+	MaxTimeout        string `json:"maxTimeout"`        // cap for the open timeout doubling on repeated trips, e.g. "5m"
+	ResetAfter        string `json:"resetAfter"`        // success streak that clears failures after recovery, e.g. "2m"
 }
 
 // RateLimitConfig represents rate limiting settings
 type RateLimitConfig struct {
-	Enabled           bool `json:"enabled"`
-	RequestsPerMinute int  `json:"requestsPerMinute"`
+	Enabled                     bool `json:"enabled"`
+	RequestsPerMinute           int  `json:"requestsPerMinute"`
+	PerSessionRequestsPerMinute int  `json:"perSessionRequestsPerMinute"` // 0 disables the per-session limit
+}
+
+// SigningConfig represents HMAC request signing settings
+type SigningConfig struct {
+	Secret    string `json:"secret"`    // shared secret, signing is skipped when empty
+	Header    string `json:"header"`    // header carrying the signature, default: X-Signature
+	Algorithm string `json:"algorithm"` // "sha256" (default) or "sha1"
+}
+
+// SpoolConfig represents on-disk persistence of async webhook sends
+type SpoolConfig struct {
+	Enabled bool   `json:"enabled"`
+	Dir     string `json:"dir"`    // spool directory, default: spool inside the state directory
+	MaxAge  string `json:"maxAge"` // undelivered entries older than this are dropped, e.g. "1h"
+}
+
+// TLSConfig represents client TLS settings for webhook requests
+type TLSConfig struct {
+	CAFile             string `json:"caFile"`             // PEM bundle of extra trusted CAs, added to the system roots
+	CertFile           string `json:"certFile"`           // PEM client certificate for mutual TLS
+	KeyFile            string `json:"keyFile"`            // PEM private key for CertFile
+	InsecureSkipVerify bool   `json:"insecureSkipVerify"` // disable certificate verification; for testing only
+}
+
+// BatchConfig represents coalescing of rapid notifications for the same session
+type BatchConfig struct {
+	Enabled   bool   `json:"enabled"`
+	Window    string `json:"window"`    // how long to collect notifications after the first, default "5s"
+	Separator string `json:"separator"` // joins the batched messages, default "\n\n---\n\n"
+}
+
+// PayloadLimitConfig caps the size of outgoing webhook bodies
+type PayloadLimitConfig struct {
+	MaxBytes int    `json:"maxBytes"` // 0 means unlimited
+	Overflow string `json:"overflow"` // "truncate" (default) shortens the message to fit, "fail" rejects the notification
+}
+
+// CompressionConfig represents gzip compression of large JSON webhook bodies
+type CompressionConfig struct {
+	Enabled  bool `json:"enabled"`
+	MinBytes int  `json:"minBytes"` // bodies smaller than this are sent uncompressed, default 1024
+}
+
+// QuietHoursConfig represents a recurring window in which webhooks are not sent
+type QuietHoursConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Start    string   `json:"start"`    // "HH:MM", e.g. "22:00"
+	End      string   `json:"end"`      // "HH:MM", may be earlier than start to wrap past midnight; equal to start means all day
+	Timezone string   `json:"timezone"` // IANA name, e.g. "Europe/Berlin"; empty uses local time
+	Days     []string `json:"days"`     // days the window starts on: mon..sun; empty means every day
 }
 
 // StatusInfo represents configuration for a specific status
 type StatusInfo struct {
-	Title string `json:"title"`
-	Sound string `json:"sound"`
+	Title       string `json:"title"`
+	Sound       string `json:"sound"`
+	Emoji       string `json:"emoji"`       // webhook emoji override, default: built-in emoji for the status
+	TitlePrefix string `json:"titlePrefix"` // text prepended to the title in webhook payloads
+	Color       string `json:"color"`       // webhook accent color override as #rrggbb, default: built-in color for the status
+	Priority    string `json:"priority"`    // webhook priority override: min, low, default, high or urgent; default: built-in priority for the status
+	MobileSound string `json:"mobileSound"` // push sound name for the Pushover and Gotify presets, default: built-in sound for the status
 }
 
 // DefaultConfig returns a config with sensible defaults
@@ -101,6 +299,7 @@ func DefaultConfig() *Config {
 					MaxAttempts:    3,
 					InitialBackoff: "1s",
 					MaxBackoff:     "10s",
+					Jitter:         "equal",
 				},
 				CircuitBreaker: CircuitBreakerConfig{
 					Enabled:          true,
@@ -115,6 +314,10 @@ func DefaultConfig() *Config {
 			},
 			SuppressQuestionAfterTaskCompleteSeconds:    12,
 			SuppressQuestionAfterAnyNotificationSeconds: 12,
+			DuplicateMessageWindowSeconds:               12,
+			DedupLockTTLSeconds:                         2,
+			DedupContentLockTTLSeconds:                  5,
+			CleanupMaxAgeSeconds:                        60,
 		},
 		Statuses: map[string]StatusInfo{
 			"task_complete": {
@@ -150,7 +353,9 @@ func DefaultConfig() *Config {
 func Load(path string) (*Config, error) {
 	// If path doesn't exist, use default config
 	if !platform.FileExists(path) {
-		return DefaultConfig(), nil
+		config := DefaultConfig()
+		config.applyEnvOverrides()
+		return config, nil
 	}
 
 	data, err := os.ReadFile(path)
@@ -165,7 +370,23 @@ func Load(path string) (*Config, error) {
 
 	// Expand environment variables in paths
 	config.Notifications.Desktop.AppIcon = platform.ExpandEnv(config.Notifications.Desktop.AppIcon)
-	config.Notifications.Webhook.URL = platform.ExpandEnv(config.Notifications.Webhook.URL)
+	// Unset variables in URLs are kept so the webhook sender can report them
+	config.Notifications.Webhook.URL = platform.ExpandEnvKeepMissing(config.Notifications.Webhook.URL)
+	config.Notifications.Webhook.Signing.Secret = platform.ExpandEnv(config.Notifications.Webhook.Signing.Secret)
+	config.Notifications.Webhook.Proxy = platform.ExpandEnv(config.Notifications.Webhook.Proxy)
+	config.Notifications.Webhook.TLS.CAFile = platform.ExpandEnv(config.Notifications.Webhook.TLS.CAFile)
+	config.Notifications.Webhook.TLS.CertFile = platform.ExpandEnv(config.Notifications.Webhook.TLS.CertFile)
+	config.Notifications.Webhook.TLS.KeyFile = platform.ExpandEnv(config.Notifications.Webhook.TLS.KeyFile)
+	config.Notifications.Webhook.Token = platform.ExpandEnv(config.Notifications.Webhook.Token)
+	config.Notifications.Webhook.RoutingKey = platform.ExpandEnv(config.Notifications.Webhook.RoutingKey)
+	for _, dests := range [][]WebhookDestination{config.Notifications.Webhook.Destinations, config.Notifications.Webhook.Fallbacks} {
+		for i := range dests {
+			dest := &dests[i]
+			dest.URL = platform.ExpandEnvKeepMissing(dest.URL)
+			dest.Token = platform.ExpandEnv(dest.Token)
+			dest.RoutingKey = platform.ExpandEnv(dest.RoutingKey)
+		}
+	}
 
 	// Expand environment variables in sound paths
 	for status, info := range config.Statuses {
@@ -173,12 +394,23 @@ func Load(path string) (*Config, error) {
 		config.Statuses[status] = info
 	}
 
+	config.State.Dir = platform.ExpandEnv(config.State.Dir)
+	config.State.EncryptionKey = platform.ExpandEnv(config.State.EncryptionKey)
+	config.applyEnvOverrides()
+
 	// Apply defaults for missing fields
 	config.ApplyDefaults()
 
 	return config, nil
 }
 
+// applyEnvOverrides applies settings taken from the environment
+func (c *Config) applyEnvOverrides() {
+	if dir := os.Getenv(StateDirEnv); dir != "" {
+		c.State.Dir = dir
+	}
+}
+
 // LoadFromPluginRoot loads configuration from plugin root directory
 func LoadFromPluginRoot(pluginRoot string) (*Config, error) {
 	configPath := filepath.Join(pluginRoot, "config", "config.json")
@@ -203,6 +435,32 @@ func (c *Config) ApplyDefaults() {
 	if c.Notifications.Webhook.Headers == nil {
 		c.Notifications.Webhook.Headers = make(map[string]string)
 	}
+	if c.Notifications.Webhook.Retry.Jitter == "" {
+		c.Notifications.Webhook.Retry.Jitter = "equal"
+	}
+	if c.Notifications.Webhook.Signing.Header == "" {
+		c.Notifications.Webhook.Signing.Header = "X-Signature"
+	}
+	if c.Notifications.Webhook.Signing.Algorithm == "" {
+		c.Notifications.Webhook.Signing.Algorithm = "sha256"
+	}
+	for _, dests := range [][]WebhookDestination{c.Notifications.Webhook.Destinations, c.Notifications.Webhook.Fallbacks} {
+		for i := range dests {
+			dest := &dests[i]
+			if dest.Preset == "" {
+				dest.Preset = "custom"
+			}
+			if dest.Name == "" {
+				dest.Name = dest.Preset
+			}
+			if dest.Format == "" {
+				dest.Format = "json"
+			}
+			if dest.Headers == nil {
+				dest.Headers = make(map[string]string)
+			}
+		}
+	}
 
 	// Cooldown defaults
 	if c.Notifications.SuppressQuestionAfterTaskCompleteSeconds == 0 {
@@ -211,6 +469,19 @@ func (c *Config) ApplyDefaults() {
 	if c.Notifications.SuppressQuestionAfterAnyNotificationSeconds == 0 {
 		c.Notifications.SuppressQuestionAfterAnyNotificationSeconds = 12
 	}
+	// The duplicate window used to be the question cooldown, so unset configs keep that behavior
+	if c.Notifications.DuplicateMessageWindowSeconds == 0 {
+		c.Notifications.DuplicateMessageWindowSeconds = c.Notifications.SuppressQuestionAfterAnyNotificationSeconds
+	}
+	if c.Notifications.DedupLockTTLSeconds == 0 {
+		c.Notifications.DedupLockTTLSeconds = 2
+	}
+	if c.Notifications.DedupContentLockTTLSeconds == 0 {
+		c.Notifications.DedupContentLockTTLSeconds = 5
+	}
+	if c.Notifications.CleanupMaxAgeSeconds == 0 {
+		c.Notifications.CleanupMaxAgeSeconds = 60
+	}
 
 	// Status defaults
 	defaults := DefaultConfig()
@@ -233,59 +504,560 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("desktop volume must be between 0.0 and 1.0 (got %.2f)", c.Notifications.Desktop.Volume)
 	}
 
-	// Validate webhook preset (only if webhooks are enabled)
+	// Validate webhook settings
+	if err := c.Notifications.Webhook.Validate(); err != nil {
+		return err
+	}
+
+	// Validate state backend
+	if backend := c.State.Backend; backend != "" && backend != "file" && backend != "sqlite" && backend != "memory" {
+		return fmt.Errorf("invalid state backend: %s (must be one of: file, sqlite, memory)", backend)
+	}
+	if backend := c.State.Backend; c.State.EncryptionKey != "" && backend != "" && backend != "file" {
+		return fmt.Errorf("state encryptionKey is only supported by the file backend")
+	}
+	if c.State.Backend == "sqlite" && !platform.CgoEnabled {
+		return fmt.Errorf("state backend sqlite is not supported by this build (built without cgo), use file or memory")
+	}
+
+	// Validate log level
+	switch c.LogLevel {
+	case "", "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("invalid log level: %s (must be one of: debug, info, warn, error)", c.LogLevel)
+	}
+
+	// Validate message normalization rules
+	validRules := map[string]bool{
+		"strip-emoji":         true,
+		"collapse-whitespace": true,
+		"strip-markdown":      true,
+		"strip-punctuation":   true,
+	}
+	for _, rule := range c.Notifications.MessageNormalization {
+		if !validRules[rule] {
+			return fmt.Errorf("invalid message normalization rule: %s (must be one of: strip-emoji, collapse-whitespace, strip-markdown, strip-punctuation)", rule)
+		}
+	}
+
+	// Validate status color overrides
+	for status, info := range c.Statuses {
+		if info.Color != "" && !hexColorPattern.MatchString(info.Color) {
+			return fmt.Errorf("invalid color for status %s: %s (must be #rrggbb)", status, info.Color)
+		}
+		if info.Priority != "" && !isPriority(info.Priority) {
+			return fmt.Errorf("invalid priority for status %s: %s (must be one of: min, low, default, high, urgent)", status, info.Priority)
+		}
+	}
+
+	// Validate muted statuses
+	for _, status := range c.Notifications.MutedStatuses {
+		if _, ok := c.GetStatusInfo(status); !ok {
+			return fmt.Errorf("invalid muted status: %s", status)
+		}
+	}
+
+	// Validate webhook mention statuses
+	for _, dest := range c.Notifications.Webhook.GetDestinations() {
+		for _, status := range dest.MentionStatuses {
+			if _, ok := c.GetStatusInfo(status); !ok {
+				return fmt.Errorf("invalid mention status: %s", status)
+			}
+		}
+	}
+
+	// Validate cooldown
+	if c.Notifications.SuppressQuestionAfterTaskCompleteSeconds < 0 {
+		return fmt.Errorf("suppressQuestionAfterTaskCompleteSeconds must be >= 0")
+	}
+
+	// Validate duplicate message window
+	if c.Notifications.DuplicateMessageWindowSeconds < 0 {
+		return fmt.Errorf("duplicateMessageWindowSeconds must be >= 0")
+	}
+
+	// Validate dedup lock TTLs
+	if c.Notifications.DedupLockTTLSeconds < 0 {
+		return fmt.Errorf("dedupLockTTLSeconds must be >= 0")
+	}
+	if c.Notifications.DedupContentLockTTLSeconds < 0 {
+		return fmt.Errorf("dedupContentLockTTLSeconds must be >= 0")
+	}
+
+	// Validate cleanup age
+	if c.Notifications.CleanupMaxAgeSeconds < 0 {
+		return fmt.Errorf("cleanupMaxAgeSeconds must be >= 0")
+	}
+
+	return nil
+}
+
+// Validate checks the webhook settings
+// Destinations are only checked when webhooks are enabled
+func (w *WebhookConfig) Validate() error {
+	// Settings of disabled webhooks are never used, so leftovers don't break desktop notifications
+	if !w.Enabled {
+		return nil
+	}
+
+	// Validate webhook destinations
+	if err := w.validateDestinations(); err != nil {
+		return err
+	}
+
+	// Validate signing algorithm
+	validAlgorithms := map[string]bool{
+		"":       true, // defaults to sha256
+		"sha256": true,
+		"sha1":   true,
+	}
+	if !validAlgorithms[w.Signing.Algorithm] {
+		return fmt.Errorf("invalid webhook signing algorithm: %s (must be one of: sha256, sha1)", w.Signing.Algorithm)
+	}
+
+	// Validate retry attempts and backoff, unparseable durations would silently fall back to defaults
+	if w.Retry.Enabled && w.Retry.MaxAttempts < 1 {
+		return fmt.Errorf("webhook retry maxAttempts must be >= 1 when retry is enabled (got %d)", w.Retry.MaxAttempts)
+	}
+	if backoff := w.Retry.InitialBackoff; backoff != "" {
+		if d, err := time.ParseDuration(backoff); err != nil || d <= 0 {
+			return fmt.Errorf("invalid webhook retry initialBackoff: %s", backoff)
+		}
+	}
+	if backoff := w.Retry.MaxBackoff; backoff != "" {
+		if d, err := time.ParseDuration(backoff); err != nil || d <= 0 {
+			return fmt.Errorf("invalid webhook retry maxBackoff: %s", backoff)
+		}
+	}
+
+	// Validate retry jitter mode
+	if jitter := w.Retry.Jitter; jitter != "" && jitter != "equal" && jitter != "full" && jitter != "none" {
+		return fmt.Errorf("invalid webhook retry jitter: %s (must be one of: equal, full, none)", jitter)
+	}
+
+	// Validate retry time budget
+	if maxElapsed := w.Retry.MaxElapsedTime; maxElapsed != "" {
+		if _, err := time.ParseDuration(maxElapsed); err != nil {
+			return fmt.Errorf("invalid webhook retry maxElapsedTime: %s", maxElapsed)
+		}
+	}
+
+	// Validate quick retry
+	if quick := w.Retry.QuickRetry; quick.Enabled {
+		if quick.Attempts < 0 {
+			return fmt.Errorf("webhook retry quickRetry attempts must be >= 0")
+		}
+		if quick.Delay != "" {
+			if d, err := time.ParseDuration(quick.Delay); err != nil || d < 0 {
+				return fmt.Errorf("invalid webhook retry quickRetry delay: %s", quick.Delay)
+			}
+		}
+	}
+
+	// Validate proxy URL
+	if proxy := w.Proxy; proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid webhook proxy URL: %s", proxy)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+			return fmt.Errorf("invalid webhook proxy scheme: %s (must be one of: http, https, socks5)", u.Scheme)
+		}
+	}
+
+	// Validate quiet hours
+	if qh := w.QuietHours; qh.Enabled {
+		if err := qh.validate(); err != nil {
+			return err
+		}
+	}
+
+	// Validate HTTP timeout
+	if timeout := w.Timeout; timeout != "" {
+		if d, err := time.ParseDuration(timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid webhook timeout: %s", timeout)
+		}
+	}
+
+	// Validate async send concurrency
+	if w.MaxConcurrency < 0 {
+		return fmt.Errorf("webhook maxConcurrency must be >= 0")
+	}
+
+	// Validate max event age
+	if maxAge := w.MaxEventAge; maxAge != "" {
+		if d, err := time.ParseDuration(maxAge); err != nil || d < 0 {
+			return fmt.Errorf("invalid webhook maxEventAge: %s", maxAge)
+		}
+	}
+
+	// Validate TLS client certificate
+	if tlsCfg := w.TLS; (tlsCfg.CertFile == "") != (tlsCfg.KeyFile == "") {
+		return fmt.Errorf("webhook tls certFile and keyFile must be set together")
+	}
+
+	// Validate payload limit
+	if limit := w.PayloadLimit; limit.MaxBytes < 0 {
+		return fmt.Errorf("webhook payloadLimit maxBytes must be >= 0")
+	} else if limit.Overflow != "" && limit.Overflow != "truncate" && limit.Overflow != "fail" {
+		return fmt.Errorf("invalid webhook payloadLimit overflow: %s (must be truncate or fail)", limit.Overflow)
+	}
+
+	// Validate circuit breaker open timeout
+	if timeout := w.CircuitBreaker.Timeout; timeout != "" {
+		if d, err := time.ParseDuration(timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid webhook circuitBreaker timeout: %s", timeout)
+		}
+	}
+
+	// Validate half-open probe limit
+	if w.CircuitBreaker.MaxHalfOpenProbes < 0 {
+		return fmt.Errorf("webhook circuitBreaker maxHalfOpenProbes must be >= 0")
+	}
+
+	// Validate circuit breaker backoff and recovery
+	if cb := w.CircuitBreaker; cb.MaxTimeout != "" {
+		if d, err := time.ParseDuration(cb.MaxTimeout); err != nil || d < 0 {
+			return fmt.Errorf("invalid webhook circuitBreaker maxTimeout: %s", cb.MaxTimeout)
+		}
+	}
+	if cb := w.CircuitBreaker; cb.ResetAfter != "" {
+		if d, err := time.ParseDuration(cb.ResetAfter); err != nil || d < 0 {
+			return fmt.Errorf("invalid webhook circuitBreaker resetAfter: %s", cb.ResetAfter)
+		}
+	}
+
+	// Validate compression threshold
+	if w.Compression.MinBytes < 0 {
+		return fmt.Errorf("webhook compression minBytes must be >= 0")
+	}
+
+	// Validate batch window
+	if batch := w.Batch; batch.Enabled && batch.Window != "" {
+		if d, err := time.ParseDuration(batch.Window); err != nil || d <= 0 {
+			return fmt.Errorf("invalid webhook batch window: %s", batch.Window)
+		}
+	}
+
+	// Validate spool max age
+	if spool := w.Spool; spool.Enabled && spool.MaxAge != "" {
+		if _, err := time.ParseDuration(spool.MaxAge); err != nil {
+			return fmt.Errorf("invalid webhook spool maxAge: %s", spool.MaxAge)
+		}
+	}
+
+	return nil
+}
+
+// validate validates the quiet hours window
+func (q QuietHoursConfig) validate() error {
+	if _, err := time.Parse("15:04", q.Start); err != nil {
+		return fmt.Errorf("invalid webhook quietHours start: %s (must be HH:MM)", q.Start)
+	}
+	if _, err := time.Parse("15:04", q.End); err != nil {
+		return fmt.Errorf("invalid webhook quietHours end: %s (must be HH:MM)", q.End)
+	}
+	if _, err := time.LoadLocation(q.Timezone); err != nil {
+		return fmt.Errorf("invalid webhook quietHours timezone: %s", q.Timezone)
+	}
+	validDays := map[string]bool{"mon": true, "tue": true, "wed": true, "thu": true, "fri": true, "sat": true, "sun": true}
+	for _, day := range q.Days {
+		if !validDays[strings.ToLower(day)] {
+			return fmt.Errorf("invalid webhook quietHours day: %s (must be one of: mon, tue, wed, thu, fri, sat, sun)", day)
+		}
+	}
+	return nil
+}
+
+// validateDestinations validates every webhook destination
+// Errors for named destinations are prefixed with the destination name
+func (w *WebhookConfig) validateDestinations() error {
+	seen := make(map[string]bool)
+	if len(w.Destinations) == 0 {
+		dest := w.GetDestinations()[0]
+		if err := validateDestination(dest); err != nil {
+			return err
+		}
+		seen[dest.Name] = true
+	}
+
+	for _, dest := range w.Destinations {
+		if seen[dest.Name] {
+			return fmt.Errorf("duplicate webhook destination name: %s", dest.Name)
+		}
+		seen[dest.Name] = true
+
+		if err := validateDestination(dest); err != nil {
+			return fmt.Errorf("webhook destination %q: %w", dest.Name, err)
+		}
+	}
+
+	// Fallbacks share the destination namespace so logs and metrics stay unambiguous,
+	// but routes can't point at them
+	fallbacks := make(map[string]bool)
+	for _, dest := range w.Fallbacks {
+		if seen[dest.Name] || fallbacks[dest.Name] {
+			return fmt.Errorf("duplicate webhook destination name: %s", dest.Name)
+		}
+		fallbacks[dest.Name] = true
+
+		if err := validateDestination(dest); err != nil {
+			return fmt.Errorf("webhook fallback %q: %w", dest.Name, err)
+		}
+	}
+
+	// Every route must point at a configured destination
+	for status, name := range w.Routes {
+		if !seen[name] {
+			return fmt.Errorf("webhook route for %s references unknown destination: %s", status, name)
+		}
+	}
+
+	return nil
+}
+
+// validateDestination validates a single webhook destination
+func validateDestination(dest WebhookDestination) error {
+	// Validate webhook preset
 	validPresets := map[string]bool{
-		"slack":    true,
-		"discord":  true,
-		"telegram": true,
-		"lark":     true,
-		"custom":   true,
+		"":           true, // same as custom
+		"slack":      true,
+		"discord":    true,
+		"telegram":   true,
+		"lark":       true,
+		"teams":      true,
+		"googlechat": true,
+		"matrix":     true,
+		"ntfy":       true,
+		"pushover":   true,
+		"rocketchat": true,
+		"pagerduty":  true,
+		"opsgenie":   true,
+		"gotify":     true,
+		"wecom":      true,
+		"zulip":      true,
+		"mattermost": true,
+		"console":    true,
+		"custom":     true,
 	}
-	if c.Notifications.Webhook.Enabled && !validPresets[c.Notifications.Webhook.Preset] {
-		return fmt.Errorf("invalid webhook preset: %s (must be one of: slack, discord, telegram, lark, custom)", c.Notifications.Webhook.Preset)
+	if !validPresets[dest.Preset] {
+		return fmt.Errorf("invalid webhook preset: %s (must be one of: slack, discord, telegram, lark, teams, googlechat, matrix, ntfy, pushover, rocketchat, pagerduty, opsgenie, gotify, wecom, zulip, mattermost, console, custom)", dest.Preset)
 	}
 
-	// Validate webhook format (only if webhooks are enabled)
+	// Validate webhook format
 	validFormats := map[string]bool{
-		"json": true,
-		"text": true,
+		"":         true, // defaults to json
+		"json":     true,
+		"text":     true,
+		"template": true,
 	}
-	if c.Notifications.Webhook.Enabled && !validFormats[c.Notifications.Webhook.Format] {
-		return fmt.Errorf("invalid webhook format: %s (must be one of: json, text)", c.Notifications.Webhook.Format)
+	if !validFormats[dest.Format] {
+		return fmt.Errorf("invalid webhook format: %s (must be one of: json, text, template)", dest.Format)
+	}
+	if dest.Format == "template" && dest.Template == "" {
+		return fmt.Errorf("template is required when webhook format is template")
 	}
 
-	// Validate webhook URL if enabled
-	if c.Notifications.Webhook.Enabled && c.Notifications.Webhook.URL == "" {
+	// Validate field renames of the built-in JSON payload
+	renamed := make(map[string]string, len(dest.FieldMap))
+	for field, name := range dest.FieldMap {
+		if !isCustomPayloadField(field) {
+			return fmt.Errorf("invalid webhook fieldMap field: %s (must be one of: %s)", field, strings.Join(CustomPayloadFields, ", "))
+		}
+		if name == "" {
+			return fmt.Errorf("webhook fieldMap name for %s must not be empty", field)
+		}
+		if other, ok := renamed[name]; ok {
+			return fmt.Errorf("webhook fieldMap maps both %s and %s to %s", other, field, name)
+		}
+		renamed[name] = field
+	}
+	for name, field := range renamed {
+		if _, moved := dest.FieldMap[name]; isCustomPayloadField(name) && !moved {
+			return fmt.Errorf("webhook fieldMap renames %s to %s, which collides with an existing field", field, name)
+		}
+	}
+
+	// Validate webhook URL, the console preset prints instead of sending
+	if dest.URL == "" && dest.Preset != "console" {
 		return fmt.Errorf("webhook URL is required when webhooks are enabled")
 	}
 
 	// Validate Telegram chat_id if Telegram preset is used
-	if c.Notifications.Webhook.Enabled && c.Notifications.Webhook.Preset == "telegram" && c.Notifications.Webhook.ChatID == "" {
+	if dest.Preset == "telegram" && dest.ChatID == "" {
 		return fmt.Errorf("chat_id is required for Telegram webhook")
 	}
 
-	// Validate cooldown
-	if c.Notifications.SuppressQuestionAfterTaskCompleteSeconds < 0 {
-		return fmt.Errorf("suppressQuestionAfterTaskCompleteSeconds must be >= 0")
+	// Validate Slack threading, which posts through chat.postMessage to a channel
+	if dest.SlackThreads {
+		if dest.Preset != "slack" {
+			return fmt.Errorf("slackThreads requires the slack preset")
+		}
+		if dest.ChatID == "" {
+			return fmt.Errorf("chat_id (Slack channel ID) is required for slackThreads")
+		}
+	}
+
+	// Validate ntfy topic if ntfy preset is used
+	if dest.Preset == "ntfy" && dest.Topic == "" {
+		return fmt.Errorf("topic is required for ntfy webhook")
+	}
+
+	// Validate Pushover credentials if Pushover preset is used
+	if dest.Preset == "pushover" && (dest.Token == "" || dest.User == "") {
+		return fmt.Errorf("token and user are required for Pushover webhook")
+	}
+
+	// Validate Zulip stream if Zulip preset is used
+	if dest.Preset == "zulip" && dest.Stream == "" {
+		return fmt.Errorf("stream is required for Zulip webhook")
+	}
+
+	// Validate Gotify app token if Gotify preset is used
+	if dest.Preset == "gotify" && dest.Token == "" {
+		return fmt.Errorf("token is required for Gotify webhook")
+	}
+
+	// Validate OpsGenie API key if OpsGenie preset is used
+	if dest.Preset == "opsgenie" && dest.Token == "" {
+		return fmt.Errorf("token (API key) is required for OpsGenie webhook")
+	}
+
+	// Validate PagerDuty routing key if PagerDuty preset is used
+	if dest.Preset == "pagerduty" && dest.RoutingKey == "" {
+		return fmt.Errorf("routing_key is required for PagerDuty webhook")
+	}
+
+	// Validate Discord button URL, Discord only accepts absolute http(s) links
+	if dest.DiscordButtonURL != "" {
+		u, err := url.Parse(strings.ReplaceAll(dest.DiscordButtonURL, "{sessionId}", "session"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid discordButtonUrl: %s (must be an http or https URL)", dest.DiscordButtonURL)
+		}
+	}
+
+	// Validate response success matcher
+	if dest.SuccessMatch.Regex != "" {
+		if _, err := regexp.Compile(dest.SuccessMatch.Regex); err != nil {
+			return fmt.Errorf("invalid webhook successMatch regex: %w", err)
+		}
+	}
+	if (dest.SuccessMatch.JSONPath == "") != (dest.SuccessMatch.Equals == "") {
+		return fmt.Errorf("webhook successMatch jsonPath and equals must be set together")
 	}
 
 	return nil
 }
 
+// registeredStatuses holds display metadata for statuses registered at runtime
+var (
+	registeredStatusesMu sync.RWMutex
+	registeredStatuses   = map[string]StatusInfo{}
+)
+
+// RegisterStatus registers display metadata for a custom status, e.g. "deploy_finished"
+// Entries in the statuses section of the config take precedence over registrations
+func RegisterStatus(status string, info StatusInfo) error {
+	if status == "" {
+		return fmt.Errorf("status name must not be empty")
+	}
+	if info.Color != "" && !hexColorPattern.MatchString(info.Color) {
+		return fmt.Errorf("invalid color for status %s: %s (must be #rrggbb)", status, info.Color)
+	}
+	if info.Priority != "" && !isPriority(info.Priority) {
+		return fmt.Errorf("invalid priority for status %s: %s (must be one of: min, low, default, high, urgent)", status, info.Priority)
+	}
+
+	registeredStatusesMu.Lock()
+	defer registeredStatusesMu.Unlock()
+	registeredStatuses[status] = info
+	return nil
+}
+
+// UnregisterStatus removes a status registered with RegisterStatus
+func UnregisterStatus(status string) {
+	registeredStatusesMu.Lock()
+	defer registeredStatusesMu.Unlock()
+	delete(registeredStatuses, status)
+}
+
 // GetStatusInfo returns status information for a given status
+// Statuses missing from the config fall back to registered custom statuses
 func (c *Config) GetStatusInfo(status string) (StatusInfo, bool) {
-	info, exists := c.Statuses[status]
+	if info, exists := c.Statuses[status]; exists {
+		return info, true
+	}
+
+	registeredStatusesMu.RLock()
+	defer registeredStatusesMu.RUnlock()
+	info, exists := registeredStatuses[status]
 	return info, exists
 }
 
+// GetDestinations returns the webhook destinations to deliver to
+// If no destinations are listed, the top-level preset/url settings form a single "default" destination
+func (w *WebhookConfig) GetDestinations() []WebhookDestination {
+	if len(w.Destinations) > 0 {
+		return w.Destinations
+	}
+
+	return []WebhookDestination{
+		{
+			Name:             "default",
+			Preset:           w.Preset,
+			URL:              w.URL,
+			ChatID:           w.ChatID,
+			Topic:            w.Topic,
+			Stream:           w.Stream,
+			Token:            w.Token,
+			User:             w.User,
+			RoutingKey:       w.RoutingKey,
+			SlackBlocks:      w.SlackBlocks,
+			SlackThreads:     w.SlackThreads,
+			DiscordButtonURL: w.DiscordButtonURL,
+			Mention:          w.Mention,
+			MentionStatuses:  w.MentionStatuses,
+			Template:         w.Template,
+			FooterTemplate:   w.FooterTemplate,
+			FieldMap:         w.FieldMap,
+			Format:           w.Format,
+			Headers:          w.Headers,
+			SuccessMatch:     w.SuccessMatch,
+			MessageRefPath:   w.MessageRefPath,
+		},
+	}
+}
+
+// NotificationsDisabled reports whether DisabledEnv is set
+// Any value other than an empty or false one (e.g. "0", "false") counts, so a typo can't re-enable notifications
+// Checked on every call rather than at load, so it also covers configs built in code
+func NotificationsDisabled() bool {
+	value := os.Getenv(DisabledEnv)
+	if value == "" {
+		return false
+	}
+	disabled, err := strconv.ParseBool(value)
+	return err != nil || disabled
+}
+
 // IsDesktopEnabled returns true if desktop notifications are enabled
 func (c *Config) IsDesktopEnabled() bool {
-	return c.Notifications.Desktop.Enabled
+	return c.Notifications.Desktop.Enabled && !NotificationsDisabled()
 }
 
 // IsWebhookEnabled returns true if webhook notifications are enabled
 func (c *Config) IsWebhookEnabled() bool {
-	return c.Notifications.Webhook.Enabled
+	return c.Notifications.Webhook.Enabled && !NotificationsDisabled()
+}
+
+// IsStatusMuted returns true if notifications for the status are turned off
+func (c *Config) IsStatusMuted(status string) bool {
+	for _, muted := range c.Notifications.MutedStatuses {
+		if muted == status {
+			return true
+		}
+	}
+	return false
 }
 
 // IsAnyNotificationEnabled returns true if at least one notification method is enabled
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/777genius/claude-notifications/internal/platform"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -73,6 +74,46 @@ func TestLoadConfigNotExists(t *testing.T) {
 	assert.True(t, cfg.Notifications.Desktop.Enabled)
 }
 
+func TestLoadConfigStateDirEnvOverride(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"state": {"dir": "/from/config"}}`), 0644))
+
+	cfg, err := Load(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "/from/config", cfg.State.Dir)
+
+	t.Setenv(StateDirEnv, "/from/env")
+
+	cfg, err = Load(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "/from/env", cfg.State.Dir)
+
+	cfg, err = Load(filepath.Join(tmpDir, "missing.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "/from/env", cfg.State.Dir)
+}
+
+func TestNotificationsDisabledEnv(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	assert.True(t, cfg.IsAnyNotificationEnabled())
+
+	for _, value := range []string{"1", "true", "yes"} {
+		t.Setenv(DisabledEnv, value)
+		assert.True(t, NotificationsDisabled(), value)
+		assert.False(t, cfg.IsDesktopEnabled(), value)
+		assert.False(t, cfg.IsWebhookEnabled(), value)
+		assert.False(t, cfg.IsAnyNotificationEnabled(), value)
+	}
+
+	for _, value := range []string{"", "0", "false"} {
+		t.Setenv(DisabledEnv, value)
+		assert.False(t, NotificationsDisabled(), value)
+		assert.True(t, cfg.IsWebhookEnabled(), value)
+	}
+}
+
 func TestValidateConfig(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -305,6 +346,25 @@ func TestLoadFromPluginRoot_WithEnvironmentVariables(t *testing.T) {
 	assert.Equal(t, "https://example.com/hook", cfg.Notifications.Webhook.URL)
 }
 
+func TestLoad_WebhookURLKeepsUnsetVariables(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	configJSON := `{
+		"notifications": {
+			"webhook": {
+				"enabled": true,
+				"url": "https://api.telegram.org/bot${TEST_UNSET_TELEGRAM_TOKEN}/sendMessage"
+			}
+		}
+	}`
+	require.NoError(t, os.WriteFile(configPath, []byte(configJSON), 0644))
+
+	cfg, err := Load(configPath)
+	require.NoError(t, err)
+	// Left for the webhook sender to resolve or report
+	assert.Equal(t, "https://api.telegram.org/bot${TEST_UNSET_TELEGRAM_TOKEN}/sendMessage", cfg.Notifications.Webhook.URL)
+}
+
 // === Tests for ApplyDefaults ===
 
 func TestApplyDefaults(t *testing.T) {
@@ -456,6 +516,19 @@ func TestValidateConfig_MoreCases(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "console preset without URL",
+			cfg: &Config{
+				Notifications: NotificationsConfig{
+					Webhook: WebhookConfig{
+						Enabled: true,
+						Preset:  "console",
+						Format:  "json",
+					},
+				},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -506,3 +579,806 @@ func TestValidate_NegativeCooldown(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "suppressQuestionAfterTaskCompleteSeconds must be >= 0")
 }
+
+func TestGetDestinations_LegacySingleURL(t *testing.T) {
+	webhook := WebhookConfig{
+		Preset:  "telegram",
+		URL:     "https://api.telegram.org/bot123:ABC/sendMessage",
+		ChatID:  "123",
+		Format:  "json",
+		Headers: map[string]string{"X-Test": "1"},
+	}
+
+	dests := webhook.GetDestinations()
+	assert.Len(t, dests, 1)
+	assert.Equal(t, "default", dests[0].Name)
+	assert.Equal(t, "telegram", dests[0].Preset)
+	assert.Equal(t, webhook.URL, dests[0].URL)
+	assert.Equal(t, "123", dests[0].ChatID)
+	assert.Equal(t, "1", dests[0].Headers["X-Test"])
+}
+
+func TestGetDestinations_List(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.URL = "https://ignored.example.com"
+	cfg.Notifications.Webhook.Destinations = []WebhookDestination{
+		{Preset: "slack", URL: "https://hooks.slack.com/services/X"},
+		{Name: "me", Preset: "telegram", URL: "https://api.telegram.org/bot1/sendMessage", ChatID: "1"},
+	}
+	cfg.ApplyDefaults()
+
+	dests := cfg.Notifications.Webhook.GetDestinations()
+	assert.Len(t, dests, 2)
+	assert.Equal(t, "slack", dests[0].Name, "unnamed destination should default to its preset")
+	assert.Equal(t, "json", dests[0].Format)
+	assert.NotNil(t, dests[0].Headers)
+	assert.Equal(t, "me", dests[1].Name)
+}
+
+func TestValidate_Destinations(t *testing.T) {
+	tests := []struct {
+		name         string
+		destinations []WebhookDestination
+		errMsg       string
+	}{
+		{
+			name: "valid destinations",
+			destinations: []WebhookDestination{
+				{Name: "team", Preset: "slack", URL: "https://hooks.slack.com/services/X"},
+				{Name: "me", Preset: "telegram", URL: "https://api.telegram.org/bot1/sendMessage", ChatID: "1"},
+			},
+		},
+		{
+			name: "duplicate names",
+			destinations: []WebhookDestination{
+				{Name: "team", Preset: "slack", URL: "https://hooks.slack.com/services/X"},
+				{Name: "team", Preset: "discord", URL: "https://discord.com/api/webhooks/1/a"},
+			},
+			errMsg: "duplicate webhook destination name: team",
+		},
+		{
+			name: "missing URL",
+			destinations: []WebhookDestination{
+				{Name: "team", Preset: "slack"},
+			},
+			errMsg: `webhook destination "team": webhook URL is required`,
+		},
+		{
+			name: "telegram without chat_id",
+			destinations: []WebhookDestination{
+				{Name: "me", Preset: "telegram", URL: "https://api.telegram.org/bot1/sendMessage"},
+			},
+			errMsg: `webhook destination "me": chat_id is required`,
+		},
+		{
+			name: "template format without template",
+			destinations: []WebhookDestination{
+				{Name: "ci", URL: "https://ci.example.com/hook", Format: "template"},
+			},
+			errMsg: `webhook destination "ci": template is required when webhook format is template`,
+		},
+		{
+			name: "slack threads",
+			destinations: []WebhookDestination{
+				{Name: "team", Preset: "slack", URL: "https://slack.com/api/chat.postMessage", ChatID: "C123", SlackThreads: true},
+			},
+		},
+		{
+			name: "slack threads without channel",
+			destinations: []WebhookDestination{
+				{Name: "team", Preset: "slack", URL: "https://slack.com/api/chat.postMessage", SlackThreads: true},
+			},
+			errMsg: `webhook destination "team": chat_id (Slack channel ID) is required for slackThreads`,
+		},
+		{
+			name: "slack threads on another preset",
+			destinations: []WebhookDestination{
+				{Name: "chat", Preset: "discord", URL: "https://discord.com/api/webhooks/1/a", SlackThreads: true},
+			},
+			errMsg: `webhook destination "chat": slackThreads requires the slack preset`,
+		},
+		{
+			name: "ntfy without topic",
+			destinations: []WebhookDestination{
+				{Name: "phone", Preset: "ntfy", URL: "https://ntfy.sh"},
+			},
+			errMsg: `webhook destination "phone": topic is required for ntfy webhook`,
+		},
+		{
+			name: "pushover without user",
+			destinations: []WebhookDestination{
+				{Name: "phone", Preset: "pushover", URL: "https://api.pushover.net/1/messages.json", Token: "app-token"},
+			},
+			errMsg: `webhook destination "phone": token and user are required for Pushover webhook`,
+		},
+		{
+			name: "zulip without stream",
+			destinations: []WebhookDestination{
+				{Name: "chat", Preset: "zulip", URL: "https://example.zulipchat.com/api/v1/messages"},
+			},
+			errMsg: `webhook destination "chat": stream is required for Zulip webhook`,
+		},
+		{
+			name: "gotify without token",
+			destinations: []WebhookDestination{
+				{Name: "phone", Preset: "gotify", URL: "https://gotify.example.com/message"},
+			},
+			errMsg: `webhook destination "phone": token is required for Gotify webhook`,
+		},
+		{
+			name: "opsgenie without api key",
+			destinations: []WebhookDestination{
+				{Name: "incidents", Preset: "opsgenie", URL: "https://api.opsgenie.com/v2/alerts"},
+			},
+			errMsg: `webhook destination "incidents": token (API key) is required for OpsGenie webhook`,
+		},
+		{
+			name: "pagerduty without routing key",
+			destinations: []WebhookDestination{
+				{Name: "oncall", Preset: "pagerduty", URL: "https://events.pagerduty.com/v2/enqueue"},
+			},
+			errMsg: `webhook destination "oncall": routing_key is required for PagerDuty webhook`,
+		},
+		{
+			name: "discord button with relative URL",
+			destinations: []WebhookDestination{
+				{Name: "chat", Preset: "discord", URL: "https://discord.com/api/webhooks/1/x", DiscordButtonURL: "/sessions/{sessionId}"},
+			},
+			errMsg: `webhook destination "chat": invalid discordButtonUrl: /sessions/{sessionId}`,
+		},
+		{
+			name: "discord button with session placeholder",
+			destinations: []WebhookDestination{
+				{Name: "chat", Preset: "discord", URL: "https://discord.com/api/webhooks/1/x", DiscordButtonURL: "https://dash.example.com/sessions/{sessionId}"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Notifications.Webhook.Enabled = true
+			cfg.Notifications.Webhook.Destinations = tt.destinations
+			cfg.ApplyDefaults()
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_SigningAlgorithm(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.Signing = SigningConfig{Secret: "s", Algorithm: "md5"}
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook signing algorithm: md5")
+
+	cfg.Notifications.Webhook.Signing.Algorithm = "sha1"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_SpoolMaxAge(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.Spool = SpoolConfig{Enabled: true, MaxAge: "soon"}
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook spool maxAge: soon")
+
+	cfg.Notifications.Webhook.Spool.MaxAge = "30m"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_MentionStatuses(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.MentionStatuses = []string{"question", "plan_ready"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Notifications.Webhook.MentionStatuses = []string{"urgent"}
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid mention status: urgent")
+}
+
+func TestValidate_WebhookTimeout(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.Timeout = "fast"
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook timeout: fast")
+
+	cfg.Notifications.Webhook.Timeout = "30s"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_WebhookTLS(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.TLS = TLSConfig{CertFile: "/etc/ssl/client.pem"}
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook tls certFile and keyFile must be set together")
+
+	cfg.Notifications.Webhook.TLS.KeyFile = "/etc/ssl/client-key.pem"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_PayloadLimit(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.PayloadLimit = PayloadLimitConfig{MaxBytes: -1}
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "maxBytes must be >= 0")
+
+	cfg.Notifications.Webhook.PayloadLimit = PayloadLimitConfig{MaxBytes: 4000, Overflow: "drop"}
+	err = cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook payloadLimit overflow: drop")
+
+	cfg.Notifications.Webhook.PayloadLimit.Overflow = "fail"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_FieldMap(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+
+	cfg.Notifications.Webhook.FieldMap = map[string]string{"status": "event", "message": "text"}
+	assert.NoError(t, cfg.Validate())
+
+	// Swapping two standard fields is fine
+	cfg.Notifications.Webhook.FieldMap = map[string]string{"status": "message", "message": "status"}
+	assert.NoError(t, cfg.Validate())
+
+	tests := []struct {
+		name     string
+		fieldMap map[string]string
+		want     string
+	}{
+		{"unknown field", map[string]string{"severity": "level"}, "invalid webhook fieldMap field: severity"},
+		{"empty name", map[string]string{"status": ""}, "must not be empty"},
+		{"duplicate name", map[string]string{"status": "x", "title": "x"}, "to x"},
+		{"collides with unmapped field", map[string]string{"status": "message"}, "collides with an existing field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Notifications.Webhook.FieldMap = tt.fieldMap
+			err := cfg.Validate()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestValidate_QuickRetry(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.Retry.QuickRetry = QuickRetryConfig{Enabled: true, Delay: "soon"}
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook retry quickRetry delay: soon")
+
+	cfg.Notifications.Webhook.Retry.QuickRetry = QuickRetryConfig{Enabled: true, Attempts: -1}
+	err = cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "quickRetry attempts must be >= 0")
+
+	cfg.Notifications.Webhook.Retry.QuickRetry = QuickRetryConfig{Enabled: true, Attempts: 3, Delay: "100ms"}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_BatchWindow(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.Batch = BatchConfig{Enabled: true, Window: "0s"}
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook batch window: 0s")
+
+	cfg.Notifications.Webhook.Batch.Window = "10s"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_Routes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.Destinations = []WebhookDestination{
+		{Name: "team", Preset: "slack", URL: "https://hooks.slack.com/services/X"},
+	}
+	cfg.Notifications.Webhook.Routes = map[string]string{"question": "nowhere"}
+	cfg.ApplyDefaults()
+
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook route for question references unknown destination: nowhere")
+
+	cfg.Notifications.Webhook.Routes["question"] = "team"
+	assert.NoError(t, cfg.Validate())
+
+	// Legacy single webhook is addressable as "default"
+	cfg.Notifications.Webhook.Destinations = nil
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.Routes["question"] = "default"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_Proxy(t *testing.T) {
+	tests := []struct {
+		proxy  string
+		errMsg string
+	}{
+		{proxy: "http://proxy.corp.example:3128"},
+		{proxy: "socks5://127.0.0.1:1080"},
+		{proxy: "ftp://proxy.corp.example", errMsg: "invalid webhook proxy scheme: ftp"},
+		{proxy: "not a url", errMsg: "invalid webhook proxy URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.proxy, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Notifications.Webhook.Enabled = true
+			cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+			cfg.Notifications.Webhook.Proxy = tt.proxy
+
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestValidate_DisabledWebhookIgnored(t *testing.T) {
+	// Leftover settings of disabled webhooks must not break desktop notifications
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = false
+	cfg.Notifications.Webhook.Retry.Enabled = true
+	cfg.Notifications.Webhook.Retry.MaxAttempts = 0
+	cfg.Notifications.Webhook.Retry.InitialBackoff = "fast"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	assert.Error(t, cfg.Validate())
+}
+
+func TestValidate_RetryJitter(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	assert.Equal(t, "equal", cfg.Notifications.Webhook.Retry.Jitter)
+
+	cfg.Notifications.Webhook.Retry.Jitter = "random"
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook retry jitter: random")
+
+	cfg.Notifications.Webhook.Retry.Jitter = "full"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_RetryBackoff(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.Retry.InitialBackoff = "fast"
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook retry initialBackoff: fast")
+
+	cfg.Notifications.Webhook.Retry.InitialBackoff = "500ms"
+	cfg.Notifications.Webhook.Retry.MaxBackoff = "0s"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook retry maxBackoff: 0s")
+
+	cfg.Notifications.Webhook.Retry.MaxBackoff = "5s"
+	cfg.Notifications.Webhook.Retry.MaxAttempts = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook retry maxAttempts must be >= 1")
+
+	// Attempts don't matter while retry is off
+	cfg.Notifications.Webhook.Retry.Enabled = false
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_CircuitBreakerTimeout(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.CircuitBreaker.Timeout = "30"
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook circuitBreaker timeout: 30")
+
+	cfg.Notifications.Webhook.CircuitBreaker.Timeout = "1m"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_RetryMaxElapsedTime(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.Retry.MaxElapsedTime = "forever"
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook retry maxElapsedTime: forever")
+
+	cfg.Notifications.Webhook.Retry.MaxElapsedTime = "30s"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_MessageNormalization(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.MessageNormalization = []string{"strip-emoji", "strip-markdown"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Notifications.MessageNormalization = []string{"strip-everything"}
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid message normalization rule: strip-everything")
+}
+
+func TestValidate_MutedStatuses(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.MutedStatuses = []string{"review_complete"}
+	assert.NoError(t, cfg.Validate())
+	assert.True(t, cfg.IsStatusMuted("review_complete"))
+	assert.False(t, cfg.IsStatusMuted("task_complete"))
+
+	cfg.Notifications.MutedStatuses = []string{"reviewed"}
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid muted status: reviewed")
+}
+
+func TestValidate_QuietHours(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.QuietHours = QuietHoursConfig{Enabled: true, Start: "22:00", End: "07:00", Days: []string{"mon", "Fri"}}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Notifications.Webhook.QuietHours.End = "7am"
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook quietHours end: 7am")
+
+	cfg.Notifications.Webhook.QuietHours.End = "07:00"
+	cfg.Notifications.Webhook.QuietHours.Days = []string{"weekend"}
+	err = cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook quietHours day: weekend")
+
+	// Disabled windows are not validated
+	cfg.Notifications.Webhook.QuietHours.Enabled = false
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_LogLevel(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.NoError(t, cfg.Validate())
+
+	cfg.LogLevel = "debug"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.LogLevel = "verbose"
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid log level: verbose")
+}
+
+func TestValidate_StateBackend(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.State.Backend = "sqlite"
+	if platform.CgoEnabled {
+		assert.NoError(t, cfg.Validate())
+	} else {
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "built without cgo")
+	}
+
+	cfg.State.Backend = "redis"
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid state backend: redis")
+}
+
+func TestValidate_StateEncryptionKey(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.State.EncryptionKey = "secret"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.State.Backend = "sqlite"
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "only supported by the file backend")
+}
+
+func TestValidate_SuccessMatch(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+
+	cfg.Notifications.Webhook.SuccessMatch = SuccessMatchConfig{Regex: "(unclosed"}
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook successMatch regex")
+
+	cfg.Notifications.Webhook.SuccessMatch = SuccessMatchConfig{JSONPath: "ok"}
+	err = cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "jsonPath and equals must be set together")
+
+	cfg.Notifications.Webhook.SuccessMatch = SuccessMatchConfig{JSONPath: "ok", Equals: "true", Regex: `"ok"`}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestValidate_DedupLockTTL(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, 2, cfg.Notifications.DedupLockTTLSeconds)
+
+	cfg.Notifications.DedupLockTTLSeconds = -1
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "dedupLockTTLSeconds must be >= 0")
+}
+
+func TestValidate_DedupContentLockTTL(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, 5, cfg.Notifications.DedupContentLockTTLSeconds)
+
+	cfg.Notifications.DedupContentLockTTLSeconds = -1
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "dedupContentLockTTLSeconds must be >= 0")
+}
+
+func TestValidate_CleanupMaxAge(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, 60, cfg.Notifications.CleanupMaxAgeSeconds)
+
+	cfg.Notifications.CleanupMaxAgeSeconds = -1
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cleanupMaxAgeSeconds must be >= 0")
+}
+
+func TestDuplicateMessageWindow(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, 12, cfg.Notifications.DuplicateMessageWindowSeconds)
+
+	// Unset, it follows the question cooldown as before the key existed
+	cfg = &Config{Notifications: NotificationsConfig{SuppressQuestionAfterAnyNotificationSeconds: 30}}
+	cfg.ApplyDefaults()
+	assert.Equal(t, 30, cfg.Notifications.DuplicateMessageWindowSeconds)
+
+	// Set, the two windows are independent
+	cfg = &Config{Notifications: NotificationsConfig{
+		SuppressQuestionAfterAnyNotificationSeconds: 30,
+		DuplicateMessageWindowSeconds:               300,
+	}}
+	cfg.ApplyDefaults()
+	assert.Equal(t, 30, cfg.Notifications.SuppressQuestionAfterAnyNotificationSeconds)
+	assert.Equal(t, 300, cfg.Notifications.DuplicateMessageWindowSeconds)
+
+	cfg = DefaultConfig()
+	cfg.Notifications.DuplicateMessageWindowSeconds = -1
+	err := cfg.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicateMessageWindowSeconds must be >= 0")
+}
+
+func TestValidate_StatusColor(t *testing.T) {
+	cfg := DefaultConfig()
+
+	info := cfg.Statuses["task_complete"]
+	info.Color = "#1A2b3c"
+	cfg.Statuses["task_complete"] = info
+	assert.NoError(t, cfg.Validate())
+
+	for _, color := range []string{"green", "1a2b3c", "#abc", "#12345g"} {
+		info.Color = color
+		cfg.Statuses["task_complete"] = info
+		err := cfg.Validate()
+		assert.Error(t, err, color)
+		assert.Contains(t, err.Error(), "invalid color for status task_complete")
+	}
+}
+
+func TestValidate_StatusPriority(t *testing.T) {
+	cfg := DefaultConfig()
+
+	info := cfg.Statuses["task_complete"]
+	for _, priority := range Priorities {
+		info.Priority = priority
+		cfg.Statuses["task_complete"] = info
+		assert.NoError(t, cfg.Validate(), priority)
+	}
+
+	for _, priority := range []string{"HIGH", "5", "critical"} {
+		info.Priority = priority
+		cfg.Statuses["task_complete"] = info
+		err := cfg.Validate()
+		assert.Error(t, err, priority)
+		assert.Contains(t, err.Error(), "invalid priority for status task_complete")
+	}
+}
+
+func TestGetStatusInfo_RegisteredStatus(t *testing.T) {
+	t.Cleanup(func() { UnregisterStatus("deploy_finished") })
+	cfg := DefaultConfig()
+
+	_, ok := cfg.GetStatusInfo("deploy_finished")
+	assert.False(t, ok)
+
+	require.NoError(t, RegisterStatus("deploy_finished", StatusInfo{Title: "Deploy Finished", Color: "#8e44ad"}))
+	info, ok := cfg.GetStatusInfo("deploy_finished")
+	assert.True(t, ok)
+	assert.Equal(t, "#8e44ad", info.Color)
+
+	// Registered statuses can be muted
+	cfg.Notifications.MutedStatuses = []string{"deploy_finished"}
+	assert.NoError(t, cfg.Validate())
+
+	// The config takes precedence over the registration
+	cfg.Statuses["deploy_finished"] = StatusInfo{Title: "Shipped"}
+	info, _ = cfg.GetStatusInfo("deploy_finished")
+	assert.Equal(t, "Shipped", info.Title)
+
+	assert.Error(t, RegisterStatus("", StatusInfo{}))
+}
+
+func TestValidate_Compression(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.Compression = CompressionConfig{Enabled: true, MinBytes: 2048}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Notifications.Webhook.Compression.MinBytes = -1
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "compression minBytes must be >= 0")
+}
+
+func TestValidate_Fallbacks(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.Destinations = []WebhookDestination{
+		{Name: "team", Preset: "slack", URL: "https://hooks.slack.com/services/X"},
+	}
+	cfg.Notifications.Webhook.Fallbacks = []WebhookDestination{
+		{Name: "me", Preset: "telegram", URL: "https://api.telegram.org/bot1/sendMessage", ChatID: "1"},
+	}
+	cfg.ApplyDefaults()
+	assert.NoError(t, cfg.Validate())
+
+	tests := []struct {
+		name      string
+		fallbacks []WebhookDestination
+		routes    map[string]string
+		errMsg    string
+	}{
+		{
+			name:      "name clashes with destination",
+			fallbacks: []WebhookDestination{{Name: "team", Preset: "slack", URL: "https://hooks.slack.com/services/Y"}},
+			errMsg:    "duplicate webhook destination name: team",
+		},
+		{
+			name: "duplicate fallback names",
+			fallbacks: []WebhookDestination{
+				{Name: "backup", Preset: "slack", URL: "https://hooks.slack.com/services/Y"},
+				{Name: "backup", Preset: "slack", URL: "https://hooks.slack.com/services/Z"},
+			},
+			errMsg: "duplicate webhook destination name: backup",
+		},
+		{
+			name:      "invalid fallback",
+			fallbacks: []WebhookDestination{{Name: "me", Preset: "telegram", URL: "https://api.telegram.org/bot1/sendMessage"}},
+			errMsg:    `webhook fallback "me": chat_id is required`,
+		},
+		{
+			name:      "route to fallback",
+			fallbacks: []WebhookDestination{{Name: "backup", Preset: "slack", URL: "https://hooks.slack.com/services/Y"}},
+			routes:    map[string]string{"question": "backup"},
+			errMsg:    "webhook route for question references unknown destination: backup",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Notifications.Webhook.Fallbacks = tt.fallbacks
+			cfg.Notifications.Webhook.Routes = tt.routes
+			cfg.ApplyDefaults()
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestValidate_MaxHalfOpenProbes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.CircuitBreaker.MaxHalfOpenProbes = 1
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Notifications.Webhook.CircuitBreaker.MaxHalfOpenProbes = -1
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "maxHalfOpenProbes must be >= 0")
+}
+
+func TestValidate_CircuitBreakerBackoff(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.CircuitBreaker.MaxTimeout = "5m"
+	cfg.Notifications.Webhook.CircuitBreaker.ResetAfter = "2m"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Notifications.Webhook.CircuitBreaker.MaxTimeout = "soon"
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook circuitBreaker maxTimeout")
+
+	cfg.Notifications.Webhook.CircuitBreaker.MaxTimeout = ""
+	cfg.Notifications.Webhook.CircuitBreaker.ResetAfter = "-1s"
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook circuitBreaker resetAfter")
+}
+
+func TestValidate_MaxEventAge(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.MaxEventAge = "10m"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Notifications.Webhook.MaxEventAge = "ten minutes"
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook maxEventAge")
+}
+
+func TestValidate_MaxConcurrency(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Notifications.Webhook.Enabled = true
+	cfg.Notifications.Webhook.URL = "https://example.com/webhook"
+	cfg.Notifications.Webhook.MaxConcurrency = 2
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Notifications.Webhook.MaxConcurrency = -1
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "maxConcurrency must be >= 0")
+}
@@ -1,25 +1,85 @@
 package dedup
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
+	"github.com/777genius/claude-notifications/internal/logging"
 	"github.com/777genius/claude-notifications/internal/platform"
+	"github.com/777genius/claude-notifications/internal/state"
 )
 
+// DefaultLockTTL is how long a hook lock is considered fresh
+const DefaultLockTTL = 2 * time.Second
+
+// DefaultContentLockTTL is how long a content lock is considered fresh
+const DefaultContentLockTTL = 5 * time.Second
+
+// lockPollInterval is how often AcquireLockWait retries a held lock
+const lockPollInterval = 50 * time.Millisecond
+
 // Manager handles deduplication using two-phase locking
 type Manager struct {
-	tempDir string
+	tempDir            string
+	lockTTL            time.Duration
+	contentLockTTL     time.Duration
+	normalizationRules []state.NormalizationRule
 }
 
 // NewManager creates a new deduplication manager
 func NewManager() *Manager {
+	return NewManagerWithLockTTL(DefaultLockTTL)
+}
+
+// NewManagerWithLockTTL creates a deduplication manager whose locks stay fresh for ttl
+// Lock ages are compared with millisecond resolution
+func NewManagerWithLockTTL(ttl time.Duration) *Manager {
+	dir := platform.StateDir()
+	// Older versions kept locks directly in the temp dir
+	_ = platform.MigrateFiles(platform.TempDir(), dir, "claude-notification-*.lock")
+
 	return &Manager{
-		tempDir: platform.TempDir(),
+		tempDir:        dir,
+		lockTTL:        ttl,
+		contentLockTTL: DefaultContentLockTTL,
 	}
 }
 
+// NewManagerInDir creates a deduplication manager that keeps its locks in dir
+// The directory is created if it does not exist
+func NewManagerInDir(dir string, ttl time.Duration) (*Manager, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create lock dir: %w", err)
+	}
+	return &Manager{
+		tempDir:        dir,
+		lockTTL:        ttl,
+		contentLockTTL: DefaultContentLockTTL,
+	}, nil
+}
+
+// SetNormalizationRules sets extra rules applied before hashing content locks
+func (m *Manager) SetNormalizationRules(rules []state.NormalizationRule) {
+	m.normalizationRules = rules
+}
+
+// SetContentLockTTL sets how long content locks stay fresh, DefaultContentLockTTL by default
+// Lock ages are compared with millisecond resolution
+func (m *Manager) SetContentLockTTL(ttl time.Duration) {
+	m.contentLockTTL = ttl
+}
+
+// isFresh reports whether a lock of the given age (in milliseconds) is still within ttl
+func isFresh(ageMillis int64, ttl time.Duration) bool {
+	return ageMillis >= 0 && ageMillis < ttl.Milliseconds()
+}
+
 // getLockPath returns the path to the lock file for a session and hook event
 // If hookEvent is empty, uses a global lock for the session (backward compatibility)
 func (m *Manager) getLockPath(sessionID string, hookEvent ...string) string {
@@ -29,6 +89,13 @@ func (m *Manager) getLockPath(sessionID string, hookEvent ...string) string {
 	return filepath.Join(m.tempDir, fmt.Sprintf("claude-notification-%s.lock", sessionID))
 }
 
+// getContentLockPath returns the path to the lock file for a message in a session
+// The name keeps the session prefix so per-session and TTL cleanup still match it
+func (m *Manager) getContentLockPath(sessionID, message string) string {
+	sum := sha256.Sum256([]byte(state.NormalizeMessage(message, m.normalizationRules...)))
+	return filepath.Join(m.tempDir, fmt.Sprintf("claude-notification-%s-content-%s.lock", sessionID, hex.EncodeToString(sum[:8])))
+}
+
 // CheckEarlyDuplicate performs Phase 1 check for duplicates
 // Returns true if this is a duplicate and should be skipped
 // hookEvent parameter is optional - if provided, checks hook-specific lock file
@@ -39,23 +106,98 @@ func (m *Manager) CheckEarlyDuplicate(sessionID string, hookEvent ...string) boo
 		return false
 	}
 
-	// Check lock age
-	age := platform.FileAge(lockPath)
-
-	// If mtime is unavailable (Windows issue) or lock is fresh (<2s), treat as duplicate
-	if age == -1 || (age >= 0 && age < 2) {
-		return true
-	}
-
-	return false
+	// Check lock age. FileAge falls back to the creation time when the mtime is unusable;
+	// if no timestamp is available at all, the lock is treated as stale rather than
+	// suppressing notifications indefinitely
+	return isFresh(platform.FileAgeMillis(lockPath), m.lockTTL)
 }
 
 // AcquireLock performs Phase 2 lock acquisition
 // Returns true if lock was successfully acquired
 // hookEvent parameter is optional - if provided, uses hook-specific lock file
 func (m *Manager) AcquireLock(sessionID string, hookEvent ...string) (bool, error) {
-	lockPath := m.getLockPath(sessionID, hookEvent...)
+	return m.acquire(m.getLockPath(sessionID, hookEvent...), isStaleMarker(m.lockTTL))
+}
+
+// AcquireContentLock acquires a lock keyed on the normalized message content
+// Returns false if the same content was locked for this session within the content lock TTL;
+// different messages in the same session don't collide
+func (m *Manager) AcquireContentLock(sessionID, message string) (bool, error) {
+	return m.acquire(m.getContentLockPath(sessionID, message), isStaleMarker(m.contentLockTTL))
+}
+
+// ShouldDeliver gates delivery of content across concurrent hook events of a session
+// Only the first caller for the same normalized content gets true; the others back off.
+// The winner must call release once the delivery is recorded (e.g. in session state),
+// so the next event is judged against that record rather than the lock.
+// The gate stays held while its owner process is alive (see platform.IsLockStale),
+// and is taken over at once if the owner exited without releasing it.
+// If the lock cannot be created, delivery is allowed rather than silently dropped.
+func (m *Manager) ShouldDeliver(sessionID, content string) (bool, func()) {
+	lockPath := m.getContentLockPath(sessionID, content)
+
+	acquired, err := m.acquire(lockPath, isStaleOwned(m.contentLockTTL))
+	if err != nil {
+		logging.Warn("Failed to acquire content lock, delivering anyway: %v", err)
+		return true, func() {}
+	}
+	if !acquired {
+		return false, func() {}
+	}
 
+	var once sync.Once
+	return true, func() {
+		once.Do(func() {
+			// Don't remove a gate another process took over
+			if owner, ok := platform.ReadLockOwner(lockPath); ok && owner.PID != os.Getpid() {
+				return
+			}
+			_ = os.Remove(lockPath)
+		})
+	}
+}
+
+// ShouldDeliverContent is ShouldDeliver that also holds back content the session delivered
+// less than windowSeconds ago, as recorded in states (see state.Manager.IsDuplicateMessage)
+// The gate blocks identical content from hook events racing right now (e.g. Stop and Notification),
+// the window blocks an instant repeat that arrives after the first was recorded, and
+// the same text after the window has passed is delivered again. Zero windowSeconds only applies the gate.
+// If the state cannot be read, delivery is allowed rather than silently dropped.
+func (m *Manager) ShouldDeliverContent(states *state.Manager, sessionID, content string, windowSeconds int) (bool, func()) {
+	deliver, release := m.ShouldDeliver(sessionID, content)
+	if !deliver {
+		logging.Debug("Duplicate content suppressed by concurrent hook event: %s", content)
+		return false, release
+	}
+
+	duplicate, err := states.IsDuplicateMessage(sessionID, content, windowSeconds)
+	if err != nil {
+		logging.Warn("Failed to check duplicate message: %v", err)
+	} else if duplicate {
+		logging.Debug("Duplicate message suppressed within %ds: %s", windowSeconds, content)
+		release()
+		return false, func() {}
+	}
+	return true, release
+}
+
+// isStaleMarker returns a check whether a lock that ages out rather than being released is older than ttl
+// Hook locks outlive their process on purpose, so only the age counts
+func isStaleMarker(ttl time.Duration) func(lockPath string) bool {
+	return func(lockPath string) bool {
+		return !isFresh(platform.FileAgeMillis(lockPath), ttl)
+	}
+}
+
+// isStaleOwned returns a check whether a lock released by its owner can be taken over (see platform.IsLockStale)
+func isStaleOwned(ttl time.Duration) func(lockPath string) bool {
+	return func(lockPath string) bool {
+		return platform.IsLockStale(lockPath, ttl)
+	}
+}
+
+// acquire atomically creates lockPath, replacing it if isStale reports it stale
+func (m *Manager) acquire(lockPath string, isStale func(string) bool) (bool, error) {
 	// Try to create lock atomically
 	created, err := platform.AtomicCreateFile(lockPath)
 	if err != nil {
@@ -67,11 +209,8 @@ func (m *Manager) AcquireLock(sessionID string, hookEvent ...string) (bool, erro
 		return true, nil
 	}
 
-	// Lock exists - check if it's stale
-	age := platform.FileAge(lockPath)
-
-	// If lock is fresh (<2s), we're a duplicate
-	if age >= 0 && age < 2 {
+	// Lock exists - if it's still held, we're a duplicate
+	if !isStale(lockPath) {
 		return false, nil
 	}
 
@@ -87,6 +226,25 @@ func (m *Manager) AcquireLock(sessionID string, hookEvent ...string) (bool, erro
 	return created, nil
 }
 
+// AcquireLockWait is AcquireLock that waits up to timeout for a held lock to be released
+// or to go stale, polling every lockPollInterval
+// Returns false without error if the lock is still held when the timeout expires
+func (m *Manager) AcquireLockWait(sessionID string, timeout time.Duration, hookEvent ...string) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		acquired, err := m.AcquireLock(sessionID, hookEvent...)
+		if acquired || err != nil {
+			return acquired, err
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false, nil
+		}
+		time.Sleep(min(lockPollInterval, remaining))
+	}
+}
+
 // ReleaseLock releases a lock (optional, locks are cleaned up automatically)
 // hookEvent parameter is optional - if provided, releases hook-specific lock file
 func (m *Manager) ReleaseLock(sessionID string, hookEvent ...string) error {
@@ -102,11 +260,40 @@ func (m *Manager) Cleanup(maxAge int64) error {
 	return platform.CleanupOldFiles(m.tempDir, "claude-notification-*.lock", maxAge)
 }
 
-// CleanupForSession cleans up lock file for a specific session
+// ClearContentLocks removes every content lock held for a session
+func (m *Manager) ClearContentLocks(sessionID string) error {
+	contentLocks, err := filepath.Glob(filepath.Join(m.tempDir, fmt.Sprintf("claude-notification-%s-content-*.lock", sessionID)))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, lock := range contentLocks {
+		if err := os.Remove(lock); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CleanupForSession cleans up lock file for a specific session, including its content locks
 func (m *Manager) CleanupForSession(sessionID string) error {
+	_ = m.ClearContentLocks(sessionID)
+
 	lockPath := m.getLockPath(sessionID)
 	if platform.FileExists(lockPath) {
 		return os.Remove(lockPath)
 	}
 	return nil
 }
+
+// ResetCooldowns clears a session's cooldown state and content locks,
+// so a notification that would be suppressed as a repeat is delivered again
+// locks may be nil to reset only the session state
+func ResetCooldowns(states *state.Manager, locks *Manager, sessionID string) error {
+	err := states.ResetCooldowns(sessionID)
+	if locks != nil {
+		err = errors.Join(err, locks.ClearContentLocks(sessionID))
+	}
+	return err
+}
@@ -1,15 +1,22 @@
 package dedup
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/state"
 )
 
 func TestCheckEarlyDuplicate(t *testing.T) {
@@ -66,6 +73,174 @@ func TestAcquireLock(t *testing.T) {
 	assert.True(t, acquired)
 }
 
+func TestLockTTLConfigurable(t *testing.T) {
+	ttl := 5 * time.Second
+	mgr := NewManagerWithLockTTL(ttl)
+	sessionID := "test-session-ttl"
+
+	lockPath := mgr.getLockPath(sessionID)
+	defer os.Remove(lockPath)
+
+	setAge := func(age time.Duration) {
+		require.NoError(t, os.WriteFile(lockPath, []byte(""), 0644))
+		mtime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(lockPath, mtime, mtime))
+	}
+
+	// Fresh at TTL-1
+	setAge(ttl - time.Second)
+	assert.True(t, mgr.CheckEarlyDuplicate(sessionID), "lock at TTL-1 should be fresh")
+	acquired, err := mgr.AcquireLock(sessionID)
+	require.NoError(t, err)
+	assert.False(t, acquired, "fresh lock at TTL-1 should block acquisition")
+
+	// Stale at TTL+1
+	setAge(ttl + time.Second)
+	assert.False(t, mgr.CheckEarlyDuplicate(sessionID), "lock at TTL+1 should be stale")
+	acquired, err = mgr.AcquireLock(sessionID)
+	require.NoError(t, err)
+	assert.True(t, acquired, "stale lock at TTL+1 should be replaced")
+}
+
+func TestContentLockTTLConfigurable(t *testing.T) {
+	mgr, err := NewManagerInDir(t.TempDir(), DefaultLockTTL)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultContentLockTTL, mgr.contentLockTTL)
+
+	ttl := 8 * time.Second
+	mgr.SetContentLockTTL(ttl)
+	sessionID := "test-session-content-ttl"
+	lockPath := mgr.getContentLockPath(sessionID, "Task completed")
+
+	setAge := func(age time.Duration) {
+		require.NoError(t, os.WriteFile(lockPath, []byte(""), 0644))
+		mtime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(lockPath, mtime, mtime))
+	}
+
+	// Fresh at TTL-1, even though the hook lock TTL has long passed
+	setAge(ttl - time.Second)
+	acquired, err := mgr.AcquireContentLock(sessionID, "Task completed")
+	require.NoError(t, err)
+	assert.False(t, acquired, "content lock at TTL-1 should block acquisition")
+
+	// Stale at TTL+1
+	setAge(ttl + time.Second)
+	acquired, err = mgr.AcquireContentLock(sessionID, "Task completed")
+	require.NoError(t, err)
+	assert.True(t, acquired, "stale content lock at TTL+1 should be replaced")
+}
+
+func TestLockTTLMillisecondPrecision(t *testing.T) {
+	mgr, err := NewManagerInDir(t.TempDir(), DefaultLockTTL)
+	require.NoError(t, err)
+	sessionID := "test-session-millis"
+	lockPath := mgr.getLockPath(sessionID)
+
+	setAge := func(age time.Duration) {
+		require.NoError(t, os.WriteFile(lockPath, []byte(""), 0644))
+		mtime := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(lockPath, mtime, mtime))
+	}
+
+	// Two events 1500ms apart fall inside the 2s window regardless of where the second boundary lies
+	setAge(1500 * time.Millisecond)
+	assert.True(t, mgr.CheckEarlyDuplicate(sessionID), "lock 1500ms old should be fresh")
+
+	// Just past the window is stale even if it is still within the same whole second
+	setAge(2100 * time.Millisecond)
+	assert.False(t, mgr.CheckEarlyDuplicate(sessionID), "lock 2100ms old should be stale")
+
+	// Fractional-second TTLs are honored instead of being rounded down
+	short, err := NewManagerInDir(t.TempDir(), 1500*time.Millisecond)
+	require.NoError(t, err)
+	acquired, err := short.AcquireLock(sessionID)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	acquired, err = short.AcquireLock(sessionID)
+	require.NoError(t, err)
+	assert.False(t, acquired, "lock within a 1500ms TTL should block")
+}
+
+func TestCheckEarlyDuplicate_NoTimestampNotDuplicate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows falls back to the creation time")
+	}
+
+	mgr, err := NewManagerInDir(t.TempDir(), DefaultLockTTL)
+	require.NoError(t, err)
+	lockPath := mgr.getLockPath("no-timestamp")
+
+	// An empty lock whose mtime reads as unset has no usable age
+	require.NoError(t, os.WriteFile(lockPath, nil, 0644))
+	require.NoError(t, os.Chtimes(lockPath, time.Unix(0, 0), time.Unix(0, 0)))
+
+	assert.False(t, mgr.CheckEarlyDuplicate("no-timestamp"), "lock without a timestamp must not suppress notifications")
+	acquired, err := mgr.AcquireLock("no-timestamp")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestNewManagerDefaultLockTTL(t *testing.T) {
+	assert.Equal(t, DefaultLockTTL, NewManager().lockTTL)
+}
+
+func TestNewManagerInDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "custom", "locks")
+
+	mgr, err := NewManagerInDir(dir, DefaultLockTTL)
+	require.NoError(t, err)
+	assert.DirExists(t, dir)
+
+	acquired, err := mgr.AcquireLock("custom-dir-session")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.FileExists(t, filepath.Join(dir, "claude-notification-custom-dir-session.lock"))
+}
+
+func TestAcquireLockWait_ReleasedMidWait(t *testing.T) {
+	mgr := NewManager()
+	sessionID := "test-session-wait"
+	defer func() { _ = mgr.ReleaseLock(sessionID) }()
+
+	acquired, err := mgr.AcquireLock(sessionID)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// Another process releases the lock shortly
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		_ = mgr.ReleaseLock(sessionID)
+	}()
+
+	start := time.Now()
+	acquired, err = mgr.AcquireLockWait(sessionID, 2*time.Second)
+	elapsed := time.Since(start)
+
+	require.NoError(t, err)
+	assert.True(t, acquired, "should acquire once the lock is released")
+	assert.GreaterOrEqual(t, elapsed, 100*time.Millisecond, "should have waited for the release")
+	assert.Less(t, elapsed, time.Second, "should not wait for the full timeout")
+}
+
+func TestAcquireLockWait_Timeout(t *testing.T) {
+	mgr := NewManager()
+	sessionID := "test-session-wait-timeout"
+	defer func() { _ = mgr.ReleaseLock(sessionID) }()
+
+	acquired, err := mgr.AcquireLock(sessionID)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	start := time.Now()
+	acquired, err = mgr.AcquireLockWait(sessionID, 200*time.Millisecond)
+	elapsed := time.Since(start)
+
+	require.NoError(t, err)
+	assert.False(t, acquired, "held lock should not be acquired")
+	assert.GreaterOrEqual(t, elapsed, 200*time.Millisecond)
+}
+
 func TestAcquireLockConcurrent(t *testing.T) {
 	mgr := NewManager()
 	sessionID := "concurrent-test"
@@ -99,6 +274,219 @@ func TestAcquireLockConcurrent(t *testing.T) {
 	assert.Equal(t, 1, successCount)
 }
 
+func TestAcquireContentLock_IdenticalContentDeduped(t *testing.T) {
+	mgr, err := NewManagerInDir(t.TempDir(), DefaultLockTTL)
+	require.NoError(t, err)
+
+	acquired, err := mgr.AcquireContentLock("content-session", "Task completed.")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// Same content after normalization is a duplicate
+	acquired, err = mgr.AcquireContentLock("content-session", "  task completed ")
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// Same content in another session is not
+	acquired, err = mgr.AcquireContentLock("other-session", "Task completed.")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestAcquireContentLock_DifferentContentConcurrent(t *testing.T) {
+	mgr, err := NewManagerInDir(t.TempDir(), DefaultLockTTL)
+	require.NoError(t, err)
+
+	messages := []string{"Task completed", "Plan is ready", "Question for you", "Task completed"}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	acquiredCount := 0
+	for _, msg := range messages {
+		wg.Add(1)
+		go func(msg string) {
+			defer wg.Done()
+			acquired, err := mgr.AcquireContentLock("concurrent-content", msg)
+			assert.NoError(t, err)
+			if acquired {
+				mu.Lock()
+				acquiredCount++
+				mu.Unlock()
+			}
+		}(msg)
+	}
+	wg.Wait()
+
+	// Three distinct messages get through, the repeated one is deduped
+	assert.Equal(t, 3, acquiredCount)
+}
+
+func TestShouldDeliver_ConcurrentHookEvents(t *testing.T) {
+	mgr, err := NewManagerInDir(t.TempDir(), DefaultLockTTL)
+	require.NoError(t, err)
+
+	// Stop and Notification hooks firing for the same completion
+	var wg sync.WaitGroup
+	var deliveries atomic.Int32
+	start := make(chan struct{})
+	for _, content := range []string{"Task completed.", "task completed"} {
+		wg.Add(1)
+		go func(content string) {
+			defer wg.Done()
+			<-start
+			deliver, release := mgr.ShouldDeliver("gate-session", content)
+			defer release()
+			if deliver {
+				deliveries.Add(1)
+				time.Sleep(50 * time.Millisecond) // simulated delivery keeps the gate held
+			}
+		}(content)
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), deliveries.Load())
+}
+
+func TestShouldDeliver_Release(t *testing.T) {
+	mgr, err := NewManagerInDir(t.TempDir(), DefaultLockTTL)
+	require.NoError(t, err)
+
+	deliver, release := mgr.ShouldDeliver("release-session", "hello")
+	require.True(t, deliver)
+
+	blocked, _ := mgr.ShouldDeliver("release-session", "hello")
+	assert.False(t, blocked, "held gate should block identical content")
+
+	other, releaseOther := mgr.ShouldDeliver("release-session", "different")
+	assert.True(t, other, "different content should not be blocked")
+	releaseOther()
+
+	release()
+	release() // idempotent
+	assert.NoFileExists(t, mgr.getContentLockPath("release-session", "hello"))
+
+	deliver, release = mgr.ShouldDeliver("release-session", "hello")
+	assert.True(t, deliver, "released gate should let the next event through")
+	release()
+}
+
+func TestShouldDeliverContent_ElapsedTime(t *testing.T) {
+	mgr, err := NewManagerInDir(t.TempDir(), DefaultLockTTL)
+	require.NoError(t, err)
+	states := state.NewManagerWithStore(state.NewMemoryStore())
+	sessionID := "elapsed-session"
+	content := "Task completed."
+
+	deliver, release := mgr.ShouldDeliverContent(states, sessionID, content, 10)
+	require.True(t, deliver)
+	require.NoError(t, states.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete, content))
+	release()
+
+	// sentAgo backdates the recorded delivery
+	sentAgo := func(seconds int64) {
+		s, err := states.Load(sessionID)
+		require.NoError(t, err)
+		s.LastNotificationTime = time.Now().Unix() - seconds
+		require.NoError(t, states.Save(s))
+	}
+
+	// The same content 1s later is a duplicate, even though the gate was released
+	sentAgo(1)
+	deliver, _ = mgr.ShouldDeliverContent(states, sessionID, content, 10)
+	assert.False(t, deliver)
+
+	// Blocking the repeat must not leave the gate held
+	other, releaseOther := mgr.ShouldDeliverContent(states, sessionID, "Different text", 10)
+	assert.True(t, other)
+	releaseOther()
+
+	// 30s later the same content is a legitimate repeat
+	sentAgo(30)
+	deliver, release = mgr.ShouldDeliverContent(states, sessionID, content, 10)
+	assert.True(t, deliver)
+
+	// While that delivery is in flight, a concurrent event with the same content is blocked
+	concurrent, _ := mgr.ShouldDeliverContent(states, sessionID, content, 10)
+	assert.False(t, concurrent)
+	release()
+}
+
+func TestShouldDeliver_TakesOverFromExitedOwner(t *testing.T) {
+	mgr, err := NewManagerInDir(t.TempDir(), DefaultLockTTL)
+	require.NoError(t, err)
+	lockPath := mgr.getContentLockPath("crash-session", "hello")
+
+	// A hook process that crashed while holding the gate
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, cmd.Run())
+	record := fmt.Sprintf("%d %d", time.Now().UnixMilli(), cmd.Process.Pid)
+	require.NoError(t, os.WriteFile(lockPath, []byte(record), 0644))
+
+	deliver, release := mgr.ShouldDeliver("crash-session", "hello")
+	assert.True(t, deliver, "gate held by an exited process should be taken over")
+	release()
+	assert.NoFileExists(t, lockPath)
+}
+
+func TestShouldDeliver_LiveOwnerKeepsGatePastTTL(t *testing.T) {
+	mgr, err := NewManagerInDir(t.TempDir(), time.Second)
+	require.NoError(t, err)
+
+	deliver, release := mgr.ShouldDeliver("slow-session", "hello")
+	require.True(t, deliver)
+	defer release()
+
+	// A slow delivery by a live owner outlasts the TTL
+	old := time.Now().Add(-5 * time.Second)
+	lockPath := mgr.getContentLockPath("slow-session", "hello")
+	require.NoError(t, os.WriteFile(lockPath, []byte(fmt.Sprintf("%d %d", old.UnixMilli(), os.Getpid())), 0644))
+	require.NoError(t, os.Chtimes(lockPath, old, old))
+
+	blocked, _ := mgr.ShouldDeliver("slow-session", "hello")
+	assert.False(t, blocked, "live owner's gate must not be stolen")
+}
+
+func TestContentLock_SessionPrefixCleanup(t *testing.T) {
+	dir := t.TempDir()
+	mgr, err := NewManagerInDir(dir, DefaultLockTTL)
+	require.NoError(t, err)
+
+	lockPath := mgr.getContentLockPath("prefix-session", "hello")
+	assert.True(t, strings.HasPrefix(filepath.Base(lockPath), "claude-notification-prefix-session-"))
+
+	_, err = mgr.AcquireContentLock("prefix-session", "hello")
+	require.NoError(t, err)
+	require.NoError(t, mgr.CleanupForSession("prefix-session"))
+	assert.NoFileExists(t, lockPath)
+}
+
+func TestResetCooldowns(t *testing.T) {
+	mgr, err := NewManagerInDir(t.TempDir(), DefaultLockTTL)
+	require.NoError(t, err)
+	states := state.NewManagerWithStore(state.NewMemoryStore())
+	sessionID := "reset-session"
+
+	require.NoError(t, states.UpdateLastNotification(sessionID, analyzer.StatusQuestion, "Which database?"))
+	acquired, err := mgr.AcquireContentLock(sessionID, "Which database?")
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	require.NoError(t, ResetCooldowns(states, mgr, sessionID))
+
+	suppress, err := states.ShouldSuppressQuestionAfterAnyNotification(sessionID, 60)
+	require.NoError(t, err)
+	assert.False(t, suppress)
+
+	// The same content can be locked again at once
+	acquired, err = mgr.AcquireContentLock(sessionID, "Which database?")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// Without a lock manager only the state is reset
+	require.NoError(t, ResetCooldowns(states, nil, sessionID))
+}
+
 func TestReleaseLock(t *testing.T) {
 	mgr := NewManager()
 
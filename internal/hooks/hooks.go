@@ -11,6 +11,7 @@ import (
 	"github.com/777genius/claude-notifications/internal/config"
 	"github.com/777genius/claude-notifications/internal/dedup"
 	"github.com/777genius/claude-notifications/internal/errorhandler"
+	"github.com/777genius/claude-notifications/internal/lifecycle"
 	"github.com/777genius/claude-notifications/internal/logging"
 	"github.com/777genius/claude-notifications/internal/notifier"
 	"github.com/777genius/claude-notifications/internal/platform"
@@ -18,6 +19,7 @@ import (
 	"github.com/777genius/claude-notifications/internal/state"
 	"github.com/777genius/claude-notifications/internal/summary"
 	"github.com/777genius/claude-notifications/internal/webhook"
+	"github.com/777genius/claude-notifications/pkg/jsonl"
 )
 
 // HookData represents the data received from Claude Code hooks
@@ -37,7 +39,8 @@ type notifierInterface interface {
 
 // webhookInterface defines the interface for sending webhook notifications
 type webhookInterface interface {
-	SendAsync(status analyzer.Status, message, sessionID string)
+	SendAsyncWithDetails(status analyzer.Status, message, sessionID string, details webhook.Details)
+	DeleteLast(sessionID string) error
 	Shutdown(timeout time.Duration) error
 }
 
@@ -64,12 +67,54 @@ func NewHandler(pluginRoot string) (*Handler, error) {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
+	if cfg.LogLevel != "" {
+		level, err := logging.ParseLevel(cfg.LogLevel)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		logging.SetLevel(level)
+	}
+
+	rules, err := state.ParseNormalizationRules(cfg.Notifications.MessageNormalization)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	store, err := state.NewStore(cfg.State)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state store: %w", err)
+	}
+	stateMgr := state.NewManagerWithStore(store)
+	stateMgr.SetNormalizationRules(rules)
+	stateMgr.SetRecentMessageDedup(cfg.Notifications.DedupRecentMessages)
+
+	lockTTL := time.Duration(cfg.Notifications.DedupLockTTLSeconds) * time.Second
+	// Only the default lock dir migrates locks left in the temp dir, a custom state.dir is left alone
+	var dedupMgr *dedup.Manager
+	if cfg.State.Dir != "" {
+		dedupMgr, err = dedup.NewManagerInDir(cfg.State.Dir, lockTTL)
+		if err != nil {
+			_ = stateMgr.Close()
+			return nil, err
+		}
+	} else {
+		dedupMgr = dedup.NewManagerWithLockTTL(lockTTL)
+	}
+	dedupMgr.SetNormalizationRules(rules)
+	dedupMgr.SetContentLockTTL(time.Duration(cfg.Notifications.DedupContentLockTTLSeconds) * time.Second)
+
+	// Session state remembers Slack threads and message IDs across hook processes
+	webhookSvc, err := webhook.NewSender(cfg, webhook.WithThreadStore(stateMgr), webhook.WithMessageRefStore(stateMgr))
+	if err != nil {
+		_ = stateMgr.Close()
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	return &Handler{
 		cfg:         cfg,
-		dedupMgr:    dedup.NewManager(),
-		stateMgr:    state.NewManager(),
+		dedupMgr:    dedupMgr,
+		stateMgr:    stateMgr,
 		notifierSvc: notifier.New(cfg),
-		webhookSvc:  webhook.New(cfg),
+		webhookSvc:  webhookSvc,
 		pluginRoot:  pluginRoot,
 	}, nil
 }
@@ -86,6 +131,13 @@ func (h *Handler) HandleHook(hookEvent string, input io.Reader) error {
 		}
 	}()
 
+	// Release state store resources (e.g. SQLite connection)
+	defer func() {
+		if err := h.stateMgr.Close(); err != nil {
+			logging.Warn("Failed to close state store: %v", err)
+		}
+	}()
+
 	// Ensure webhook sender waits for in-flight requests before exit
 	defer func() {
 		if err := h.webhookSvc.Shutdown(5 * time.Second); err != nil {
@@ -158,6 +210,10 @@ func (h *Handler) HandleHook(hookEvent string, input io.Reader) error {
 			return err
 		}
 		defer h.cleanupOldLocks()
+	case "SessionEnd":
+		// Nothing to notify about, the session's last message may be removed
+		h.handleSessionEnd(&hookData)
+		return nil
 	default:
 		return fmt.Errorf("unknown hook event: %s", hookEvent)
 	}
@@ -168,6 +224,12 @@ func (h *Handler) HandleHook(hookEvent string, input io.Reader) error {
 		return nil
 	}
 
+	// Skip muted statuses before taking the lock so they don't block real notifications
+	if h.cfg.IsStatusMuted(string(status)) {
+		logging.Debug("Status %s is muted, skipping notification", status)
+		return nil
+	}
+
 	// Phase 2: Acquire lock before sending (per hook event type)
 	acquired, err := h.dedupMgr.AcquireLock(hookData.SessionID, hookEvent)
 	if err != nil {
@@ -225,6 +287,31 @@ func (h *Handler) HandleHook(hookEvent string, input io.Reader) error {
 		}
 	}
 
+	// Generate message
+	message := h.generateMessage(&hookData, status)
+
+	// Only one of the hook events racing with the same content (e.g. Stop and Notification) gets through,
+	// and the same text is held back until the duplicate window has passed since it was last sent
+	deliver, release := h.dedupMgr.ShouldDeliverContent(
+		h.stateMgr,
+		hookData.SessionID,
+		message,
+		h.cfg.Notifications.DuplicateMessageWindowSeconds,
+	)
+	if !deliver {
+		return nil
+	}
+	defer release()
+
+	// Blanket per-session throttle, independent of status
+	throttled, err := h.stateMgr.ShouldSuppressAny(hookData.SessionID, h.cfg.Notifications.MinNotificationIntervalSeconds)
+	if err != nil {
+		logging.Warn("Failed to check minimum notification interval: %v", err)
+	} else if throttled {
+		logging.Debug("Notification suppressed by minimum interval: %s", message)
+		return nil
+	}
+
 	// Update state (only for task_complete, PreToolUse already updated state)
 	if status == analyzer.StatusTaskComplete {
 		if err := h.stateMgr.UpdateTaskComplete(hookData.SessionID); err != nil {
@@ -233,15 +320,12 @@ func (h *Handler) HandleHook(hookEvent string, input io.Reader) error {
 	}
 
 	// Update last notification time AFTER cooldown checks (inside lock region)
-	if err := h.stateMgr.UpdateLastNotification(hookData.SessionID, status); err != nil {
+	if err := h.stateMgr.UpdateLastNotification(hookData.SessionID, status, message); err != nil {
 		logging.Warn("Failed to update last notification time: %v", err)
 	}
 
-	// Generate message
-	message := h.generateMessage(&hookData, status)
-
 	// Send notifications
-	h.sendNotifications(status, message, hookData.SessionID)
+	h.sendNotifications(status, message, hookData.SessionID, hookData.CWD, h.eventTime(&hookData))
 
 	logging.Debug("=== Hook completed: %s ===", hookEvent)
 	return nil
@@ -296,6 +380,41 @@ func (h *Handler) handleStopEvent(hookData *HookData) (analyzer.Status, error) {
 	return status, nil
 }
 
+// handleSessionEnd deletes the session's last webhook message if deleteOnSessionEnd is set,
+// so e.g. a pending question doesn't linger after the session is gone
+func (h *Handler) handleSessionEnd(hookData *HookData) {
+	if !h.cfg.IsWebhookEnabled() || !h.cfg.Notifications.Webhook.DeleteOnEnd {
+		logging.Debug("SessionEnd: message deletion disabled, skipping")
+		return
+	}
+	if err := h.webhookSvc.DeleteLast(hookData.SessionID); err != nil {
+		logging.Warn("Failed to delete last webhook message for session %s: %v", hookData.SessionID, err)
+	}
+}
+
+// eventTime returns when the transcript's last assistant message was written, which ages
+// webhook notifications (see maxEventAge), so a hook that fires late is dropped as stale
+// Returns zero when maxEventAge is off or the time is unknown, and the notification is never aged
+func (h *Handler) eventTime(hookData *HookData) time.Time {
+	if h.cfg.Notifications.Webhook.MaxEventAge == "" || !h.cfg.IsWebhookEnabled() {
+		return time.Time{}
+	}
+	if hookData.TranscriptPath == "" || !platform.FileExists(hookData.TranscriptPath) {
+		return time.Time{}
+	}
+
+	messages, err := jsonl.ParseFile(hookData.TranscriptPath)
+	if err != nil {
+		logging.Debug("Failed to read transcript for event time: %v", err)
+		return time.Time{}
+	}
+	eventTime, err := time.Parse(time.RFC3339, jsonl.GetLastAssistantTimestamp(messages))
+	if err != nil {
+		return time.Time{}
+	}
+	return eventTime
+}
+
 // generateMessage generates a notification message
 func (h *Handler) generateMessage(hookData *HookData, status analyzer.Status) string {
 	if hookData.TranscriptPath != "" && platform.FileExists(hookData.TranscriptPath) {
@@ -309,7 +428,7 @@ func (h *Handler) generateMessage(hookData *HookData, status analyzer.Status) st
 }
 
 // sendNotifications sends desktop and webhook notifications
-func (h *Handler) sendNotifications(status analyzer.Status, message, sessionID string) {
+func (h *Handler) sendNotifications(status analyzer.Status, message, sessionID, cwd string, eventTime time.Time) {
 	// Add panic recovery to prevent notification failures from crashing the plugin
 	defer errorhandler.HandlePanic()
 
@@ -328,19 +447,35 @@ func (h *Handler) sendNotifications(status analyzer.Status, message, sessionID s
 
 	// Send webhook notification (async)
 	if h.cfg.IsWebhookEnabled() {
-		h.webhookSvc.SendAsync(status, enhancedMessage, sessionID)
+		details := webhook.Details{CWD: cwd, Project: h.projectName(sessionID, cwd), EventTime: eventTime}
+		h.webhookSvc.SendAsyncWithDetails(status, enhancedMessage, sessionID, details)
 	}
 }
 
+// projectName returns the project name stored in session state for cwd
+// Returns empty string when unknown, leaving the webhook sender to derive it from cwd
+func (h *Handler) projectName(sessionID, cwd string) string {
+	sessionState, err := h.stateMgr.Load(sessionID)
+	if err != nil || sessionState == nil || sessionState.CWD != cwd {
+		return ""
+	}
+	return sessionState.ProjectName
+}
+
 // cleanupOldLocks cleans up old lock and state files but preserves session state for cooldown
 func (h *Handler) cleanupOldLocks() {
-	// Cleanup old locks (older than 60 seconds)
-	if err := h.dedupMgr.Cleanup(60); err != nil {
+	maxAge := int64(h.cfg.Notifications.CleanupMaxAgeSeconds)
+	if maxAge <= 0 {
+		maxAge = lifecycle.DefaultCleanupMaxAge
+	}
+
+	// Cleanup old locks
+	if err := h.dedupMgr.Cleanup(maxAge); err != nil {
 		logging.Warn("Failed to cleanup old locks: %v", err)
 	}
 
-	// Cleanup old state files (older than 60 seconds)
-	if err := h.stateMgr.Cleanup(60); err != nil {
+	// Cleanup old state files
+	if err := h.stateMgr.Cleanup(maxAge); err != nil {
 		logging.Warn("Failed to cleanup old state files: %v", err)
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -15,6 +16,7 @@ import (
 	"github.com/777genius/claude-notifications/internal/config"
 	"github.com/777genius/claude-notifications/internal/dedup"
 	"github.com/777genius/claude-notifications/internal/state"
+	"github.com/777genius/claude-notifications/internal/webhook"
 	"github.com/777genius/claude-notifications/pkg/jsonl"
 )
 
@@ -76,6 +78,7 @@ func (m *mockNotifier) lastCall() *notificationCall {
 type mockWebhook struct {
 	mu              sync.Mutex
 	calls           []webhookCall
+	deleted         []string // session IDs passed to DeleteLast
 	shutdownCalled  bool
 	shutdownTimeout time.Duration
 }
@@ -84,9 +87,15 @@ type webhookCall struct {
 	status    analyzer.Status
 	message   string
 	sessionID string
+	cwd       string
+	eventTime time.Time
 }
 
 func (m *mockWebhook) SendAsync(status analyzer.Status, message, sessionID string) {
+	m.SendAsyncWithDetails(status, message, sessionID, webhook.Details{})
+}
+
+func (m *mockWebhook) SendAsyncWithDetails(status analyzer.Status, message, sessionID string, details webhook.Details) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -94,9 +103,24 @@ func (m *mockWebhook) SendAsync(status analyzer.Status, message, sessionID strin
 		status:    status,
 		message:   message,
 		sessionID: sessionID,
+		cwd:       details.CWD,
+		eventTime: details.EventTime,
 	})
 }
 
+func (m *mockWebhook) DeleteLast(sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted = append(m.deleted, sessionID)
+	return nil
+}
+
+func (m *mockWebhook) deletedSessions() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.deleted...)
+}
+
 func (m *mockWebhook) Shutdown(timeout time.Duration) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -750,6 +774,45 @@ func TestHandler_UnknownHookEvent(t *testing.T) {
 	}
 }
 
+func TestHandler_SessionEnd_DeletesLastMessage(t *testing.T) {
+	cfg := &config.Config{
+		Notifications: config.NotificationsConfig{
+			Webhook: config.WebhookConfig{Enabled: true, DeleteOnEnd: true},
+		},
+	}
+	handler, _, mockWH := newTestHandler(t, cfg)
+
+	hookData := buildHookDataJSON(HookData{SessionID: "test-session-end"})
+	if err := handler.HandleHook("SessionEnd", hookData); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if deleted := mockWH.deletedSessions(); len(deleted) != 1 || deleted[0] != "test-session-end" {
+		t.Errorf("expected DeleteLast for test-session-end, got %v", deleted)
+	}
+	if mockWH.wasCalled() {
+		t.Error("expected no notification on SessionEnd")
+	}
+}
+
+func TestHandler_SessionEnd_DeletionDisabled(t *testing.T) {
+	cfg := &config.Config{
+		Notifications: config.NotificationsConfig{
+			Webhook: config.WebhookConfig{Enabled: true},
+		},
+	}
+	handler, _, mockWH := newTestHandler(t, cfg)
+
+	hookData := buildHookDataJSON(HookData{SessionID: "test-session-end"})
+	if err := handler.HandleHook("SessionEnd", hookData); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if deleted := mockWH.deletedSessions(); len(deleted) != 0 {
+		t.Errorf("expected no DeleteLast without deleteOnSessionEnd, got %v", deleted)
+	}
+}
+
 // === Webhook Integration ===
 
 func TestHandler_SendsWebhookWhenEnabled(t *testing.T) {
@@ -783,7 +846,85 @@ func TestHandler_SendsWebhookWhenEnabled(t *testing.T) {
 	time.Sleep(50 * time.Millisecond) // Webhook is async
 
 	if !mockWH.wasCalled() {
-		t.Error("expected webhook to be called when enabled")
+		t.Fatal("expected webhook to be called when enabled")
+	}
+	mockWH.mu.Lock()
+	defer mockWH.mu.Unlock()
+	if cwd := mockWH.calls[0].cwd; cwd != "/test" {
+		t.Errorf("expected webhook details to carry cwd /test, got %q", cwd)
+	}
+}
+
+func TestHandler_WebhookEventTimeFromTranscript(t *testing.T) {
+	cfg := &config.Config{
+		Notifications: config.NotificationsConfig{
+			Webhook: config.WebhookConfig{Enabled: true, MaxEventAge: "10m"},
+		},
+		Statuses: map[string]config.StatusInfo{
+			"task_complete": {Title: "Task Complete"},
+		},
+	}
+
+	handler, _, mockWH := newTestHandler(t, cfg)
+
+	transcriptPath := createTempTranscript(t,
+		buildTranscriptWithTools([]string{"Write"}, 300))
+
+	hookData := buildHookDataJSON(HookData{
+		SessionID:      "test-session-event-time",
+		TranscriptPath: transcriptPath,
+	})
+
+	if err := handler.HandleHook("Stop", hookData); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !mockWH.wasCalled() {
+		t.Fatal("expected webhook to be called")
+	}
+	mockWH.mu.Lock()
+	defer mockWH.mu.Unlock()
+	// The event is aged from the last assistant message, not from when the hook ran
+	want := time.Date(2025, 1, 1, 12, 0, 1, 0, time.UTC)
+	if got := mockWH.calls[0].eventTime; !got.Equal(want) {
+		t.Errorf("expected event time %v from the transcript, got %v", want, got)
+	}
+}
+
+func TestHandler_MutedStatusSkipsNotifications(t *testing.T) {
+	cfg := &config.Config{
+		Notifications: config.NotificationsConfig{
+			Desktop:       config.DesktopConfig{Enabled: true},
+			Webhook:       config.WebhookConfig{Enabled: true},
+			MutedStatuses: []string{"review_complete"},
+		},
+		Statuses: map[string]config.StatusInfo{
+			"review_complete": {Title: "Review Complete"},
+		},
+	}
+
+	handler, mockNotif, mockWH := newTestHandler(t, cfg)
+
+	transcriptPath := createTempTranscript(t,
+		buildTranscriptWithTools([]string{"Read", "Read", "Grep"}, 300))
+
+	hookData := buildHookDataJSON(HookData{
+		SessionID:      "test-session-muted",
+		TranscriptPath: transcriptPath,
+		CWD:            "/test",
+	})
+
+	if err := handler.HandleHook("Stop", hookData); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond) // Webhook is async
+
+	if mockNotif.wasCalled() {
+		t.Error("expected no desktop notification for muted status")
+	}
+	if mockWH.wasCalled() {
+		t.Error("expected no webhook for muted status")
 	}
 }
 
@@ -912,6 +1053,40 @@ func TestNewHandler_InvalidConfig(t *testing.T) {
 	}
 }
 
+func TestNewHandler_CustomStateDirLeavesTempLocks(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	// A lock left in the temp dir by an older version
+	legacyLock := filepath.Join(tmpDir, "claude-notification-session-1-task_complete.lock")
+	if err := os.WriteFile(legacyLock, []byte("1"), 0644); err != nil {
+		t.Fatalf("failed to write lock: %v", err)
+	}
+
+	pluginRoot := t.TempDir()
+	configDir := filepath.Join(pluginRoot, "config")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatalf("failed to create config dir: %v", err)
+	}
+	stateDir := filepath.Join(t.TempDir(), "state")
+	configJSON := `{"notifications": {"webhook": {"enabled": false}}, "state": {"dir": ` + strconv.Quote(stateDir) + `}}`
+	if err := os.WriteFile(filepath.Join(configDir, "config.json"), []byte(configJSON), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if _, err := NewHandler(pluginRoot); err != nil {
+		t.Fatalf("NewHandler failed: %v", err)
+	}
+
+	// With state.dir set, locks are not migrated into the default state directory
+	if _, err := os.Stat(legacyLock); err != nil {
+		t.Errorf("expected temp dir lock to be left alone, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "claude-notifications", filepath.Base(legacyLock))); !os.IsNotExist(err) {
+		t.Errorf("expected lock not to be migrated into the default state directory, got %v", err)
+	}
+}
+
 func TestNewHandler_MalformedJSON(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -0,0 +1,54 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/logging"
+)
+
+// DefaultCleanupMaxAge is the default age in seconds after which lock and state files are removed
+const DefaultCleanupMaxAge int64 = 60
+
+// Drainer waits for in-flight work to finish (e.g. webhook.Sender)
+type Drainer interface {
+	Shutdown(timeout time.Duration) error
+}
+
+// Cleaner removes files older than maxAge seconds (e.g. dedup.Manager, state.Manager)
+type Cleaner interface {
+	Cleanup(maxAge int64) error
+}
+
+// Shutdown drains the sender, then cleans up stale lock and state files
+// Cleanup runs even if draining times out; all errors are returned joined
+func Shutdown(drainer Drainer, timeout time.Duration, maxAge int64, cleaners ...Cleaner) error {
+	var errs []error
+
+	if drainer != nil {
+		if err := drainer.Shutdown(timeout); err != nil {
+			errs = append(errs, fmt.Errorf("drain: %w", err))
+		}
+	}
+
+	if maxAge <= 0 {
+		maxAge = DefaultCleanupMaxAge
+	}
+	for _, cleaner := range cleaners {
+		if cleaner == nil {
+			continue
+		}
+		if err := cleaner.Cleanup(maxAge); err != nil {
+			errs = append(errs, fmt.Errorf("cleanup: %w", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		logging.Warn("Shutdown completed with errors: %v", errors.Join(errs...))
+		return errors.Join(errs...)
+	}
+
+	logging.Debug("Shutdown completed")
+	return nil
+}
@@ -0,0 +1,102 @@
+package lifecycle
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/dedup"
+	"github.com/777genius/claude-notifications/internal/platform"
+	"github.com/777genius/claude-notifications/internal/state"
+	"github.com/777genius/claude-notifications/internal/webhook"
+)
+
+// touchAged creates a file with a modification time age in the past
+func touchAged(t *testing.T, path string, age time.Duration) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+	mtime := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestShutdownDrainsThenCleansUp(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	var delivered atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		delivered.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Notifications: config.NotificationsConfig{
+			Webhook: config.WebhookConfig{Enabled: true, URL: server.URL, Format: "json"},
+		},
+		Statuses: map[string]config.StatusInfo{
+			"task_complete": {Title: "Task Complete"},
+		},
+	}
+	sender := webhook.New(cfg)
+	dedupMgr := dedup.NewManager()
+	stateMgr := state.NewManagerWithStore(state.NewFileStore(tmpDir))
+
+	lockDir := filepath.Join(tmpDir, platform.StateSubdir)
+	oldLock := filepath.Join(lockDir, "claude-notification-Stop-old.lock")
+	freshLock := filepath.Join(lockDir, "claude-notification-Stop-fresh.lock")
+	oldState := filepath.Join(tmpDir, "claude-session-state-old.json")
+	freshState := filepath.Join(tmpDir, "claude-session-state-fresh.json")
+	touchAged(t, oldLock, 10*time.Minute)
+	touchAged(t, freshLock, 0)
+	touchAged(t, oldState, 10*time.Minute)
+	touchAged(t, freshState, 0)
+
+	sender.SendAsync(analyzer.StatusTaskComplete, "In flight", "session-1")
+
+	err := Shutdown(sender, 5*time.Second, 300, dedupMgr, stateMgr)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), delivered.Load(), "in-flight send should complete before shutdown returns")
+	assert.NoFileExists(t, oldLock)
+	assert.NoFileExists(t, oldState)
+	assert.FileExists(t, freshLock)
+	assert.FileExists(t, freshState)
+}
+
+type fakeDrainer struct{ err error }
+
+func (f *fakeDrainer) Shutdown(timeout time.Duration) error { return f.err }
+
+type fakeCleaner struct {
+	maxAge int64
+	called bool
+}
+
+func (f *fakeCleaner) Cleanup(maxAge int64) error {
+	f.called = true
+	f.maxAge = maxAge
+	return nil
+}
+
+func TestShutdownCleansUpAfterDrainTimeout(t *testing.T) {
+	drainErr := errors.New("shutdown timeout")
+	cleaner := &fakeCleaner{}
+
+	err := Shutdown(&fakeDrainer{err: drainErr}, time.Millisecond, 0, cleaner)
+
+	assert.ErrorIs(t, err, drainErr)
+	assert.True(t, cleaner.called, "cleanup should run even if draining fails")
+	assert.Equal(t, DefaultCleanupMaxAge, cleaner.maxAge)
+}
@@ -0,0 +1,178 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/platform"
+	"github.com/777genius/claude-notifications/internal/webhook"
+)
+
+// FileKind identifies what a managed file is used for
+type FileKind string
+
+const (
+	FileKindState       FileKind = "state"        // session state JSON, plus its lock and temp files
+	FileKindSessionLock FileKind = "session_lock" // per-session/hook-event dedup locks
+	FileKindContentLock FileKind = "content_lock" // per-message dedup locks
+	FileKindSpool       FileKind = "spool"        // spooled webhook notifications, including claimed and temp entries
+)
+
+// managedPattern maps a glob to the kind of file it matches
+// Patterns are checked in order and the first match wins, so content locks precede session locks
+type managedPattern struct {
+	glob string
+	kind FileKind
+}
+
+// statePatterns match files written by state.FileStore and dedup.Manager
+var statePatterns = []managedPattern{
+	{"claude-session-state-*.json", FileKindState},
+	{"claude-session-state-*.lock", FileKindState},
+	{"claude-session-state-*.tmp", FileKindState},
+	{"claude-notification-*-content-*.lock", FileKindContentLock},
+	{"claude-notification-*.lock", FileKindSessionLock},
+}
+
+// spoolPatterns match files written by webhook.Spool
+var spoolPatterns = []managedPattern{
+	{"spool-*", FileKindSpool},
+}
+
+// Dirs are the directories searched for managed files
+type Dirs struct {
+	State  string // state and lock files
+	Spool  string // webhook spool entries
+	Legacy string // state and lock files left in the temp dir by older versions; empty to skip
+}
+
+// DefaultDirs returns the directories used with cfg, falling back to the defaults when cfg is nil
+// The directories are not created
+func DefaultDirs(cfg *config.Config) Dirs {
+	dirs := Dirs{
+		State:  filepath.Join(platform.TempDir(), platform.StateSubdir),
+		Legacy: platform.TempDir(),
+	}
+	if cfg != nil && cfg.State.Dir != "" {
+		dirs.State = cfg.State.Dir
+	}
+	dirs.Spool = webhook.DefaultSpoolDir(dirs.State)
+	if cfg != nil && cfg.Notifications.Webhook.Spool.Dir != "" {
+		dirs.Spool = cfg.Notifications.Webhook.Spool.Dir
+	}
+	return dirs
+}
+
+// ManagedFile is a file owned by the plugin
+type ManagedFile struct {
+	Path string
+	Kind FileKind
+}
+
+// FileCounts is the number of managed files of each kind
+type FileCounts struct {
+	State        int
+	SessionLocks int
+	ContentLocks int
+	Spool        int
+}
+
+// add counts one file of kind
+func (c *FileCounts) add(kind FileKind) {
+	switch kind {
+	case FileKindState:
+		c.State++
+	case FileKindSessionLock:
+		c.SessionLocks++
+	case FileKindContentLock:
+		c.ContentLocks++
+	case FileKindSpool:
+		c.Spool++
+	}
+}
+
+// Total returns the number of files of all kinds
+func (c FileCounts) Total() int {
+	return c.State + c.SessionLocks + c.ContentLocks + c.Spool
+}
+
+// CountFiles counts files by kind
+func CountFiles(files []ManagedFile) FileCounts {
+	var counts FileCounts
+	for _, f := range files {
+		counts.add(f.Kind)
+	}
+	return counts
+}
+
+// ListManagedFiles returns every state, lock and spool file in dirs, sorted by path
+// Missing directories are skipped
+func ListManagedFiles(dirs Dirs) ([]ManagedFile, error) {
+	seen := make(map[string]bool)
+	var files []ManagedFile
+
+	scan := func(dir string, patterns []managedPattern) error {
+		if dir == "" {
+			return nil
+		}
+		for _, p := range patterns {
+			matches, err := filepath.Glob(filepath.Join(dir, p.glob))
+			if err != nil {
+				return fmt.Errorf("failed to list %s files: %w", p.kind, err)
+			}
+			for _, path := range matches {
+				if seen[path] {
+					continue
+				}
+				seen[path] = true
+				files = append(files, ManagedFile{Path: path, Kind: p.kind})
+			}
+		}
+		return nil
+	}
+
+	if err := scan(dirs.State, statePatterns); err != nil {
+		return nil, err
+	}
+	if err := scan(dirs.Legacy, statePatterns); err != nil {
+		return nil, err
+	}
+	if err := scan(dirs.Spool, spoolPatterns); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// PruneManagedFiles deletes every file returned by ListManagedFiles and returns how many were removed
+// Files already gone are not counted; other removal errors are returned joined after trying every file
+func PruneManagedFiles(dirs Dirs) (FileCounts, error) {
+	var removed FileCounts
+
+	files, err := ListManagedFiles(dirs)
+	if err != nil {
+		return removed, err
+	}
+
+	var errs []error
+	for _, f := range files {
+		if err := os.Remove(f.Path); err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", f.Path, err))
+			}
+			continue
+		}
+		removed.add(f.Kind)
+	}
+
+	logging.Info("Pruned %d managed files (state: %d, session locks: %d, content locks: %d, spool: %d)",
+		removed.Total(), removed.State, removed.SessionLocks, removed.ContentLocks, removed.Spool)
+
+	return removed, errors.Join(errs...)
+}
@@ -0,0 +1,105 @@
+package lifecycle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/platform"
+	"github.com/777genius/claude-notifications/internal/webhook"
+)
+
+func TestListAndPruneManagedFiles(t *testing.T) {
+	dirs := Dirs{
+		State:  t.TempDir(),
+		Spool:  t.TempDir(),
+		Legacy: t.TempDir(),
+	}
+
+	want := map[string]FileKind{
+		filepath.Join(dirs.State, "claude-session-state-abc.json"):                 FileKindState,
+		filepath.Join(dirs.State, "claude-session-state-abc.lock"):                 FileKindState,
+		filepath.Join(dirs.State, "claude-session-state-abc.json.tmp"):             FileKindState,
+		filepath.Join(dirs.State, "claude-notification-abc.lock"):                  FileKindSessionLock,
+		filepath.Join(dirs.State, "claude-notification-abc-S.lock"):                FileKindSessionLock,
+		filepath.Join(dirs.State, "claude-notification-abc-content-0123abcd.lock"): FileKindContentLock,
+		filepath.Join(dirs.Legacy, "claude-session-state-old.json"):                FileKindState,
+		filepath.Join(dirs.Legacy, "claude-notification-old.lock"):                 FileKindSessionLock,
+		filepath.Join(dirs.Spool, "spool-1.json"):                                  FileKindSpool,
+		filepath.Join(dirs.Spool, "spool-2.json.claimed"):                          FileKindSpool,
+		filepath.Join(dirs.Spool, "spool-3.json.tmp"):                              FileKindSpool,
+	}
+	for path := range want {
+		require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+	}
+
+	// Files the plugin does not own are left alone
+	unrelated := []string{
+		filepath.Join(dirs.State, "notes.txt"),
+		filepath.Join(dirs.Legacy, "spool-other.json"),
+		filepath.Join(dirs.Legacy, "other-app.lock"),
+	}
+	for _, path := range unrelated {
+		require.NoError(t, os.WriteFile(path, []byte("keep"), 0644))
+	}
+
+	files, err := ListManagedFiles(dirs)
+	require.NoError(t, err)
+
+	got := make(map[string]FileKind, len(files))
+	for _, f := range files {
+		got[f.Path] = f.Kind
+	}
+	assert.Equal(t, want, got)
+	assert.Equal(t, FileCounts{State: 4, SessionLocks: 3, ContentLocks: 1, Spool: 3}, CountFiles(files))
+
+	removed, err := PruneManagedFiles(dirs)
+	require.NoError(t, err)
+	assert.Equal(t, FileCounts{State: 4, SessionLocks: 3, ContentLocks: 1, Spool: 3}, removed)
+	assert.Equal(t, 11, removed.Total())
+
+	for path := range want {
+		assert.NoFileExists(t, path)
+	}
+	for _, path := range unrelated {
+		assert.FileExists(t, path)
+	}
+
+	files, err = ListManagedFiles(dirs)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestListManagedFilesMissingDirs(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	files, err := ListManagedFiles(Dirs{State: missing, Spool: missing})
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	removed, err := PruneManagedFiles(Dirs{State: missing, Spool: missing})
+	require.NoError(t, err)
+	assert.Zero(t, removed.Total())
+}
+
+func TestDefaultDirs(t *testing.T) {
+	dirs := DefaultDirs(nil)
+	assert.Equal(t, filepath.Join(platform.TempDir(), platform.StateSubdir), dirs.State)
+	assert.Equal(t, filepath.Join(platform.TempDir(), platform.StateSubdir, "spool"), dirs.Spool)
+	assert.Equal(t, webhook.DefaultSpoolDir(""), dirs.Spool)
+	assert.Equal(t, platform.TempDir(), dirs.Legacy)
+
+	// The spool follows state.dir unless it has its own directory
+	cfg := &config.Config{State: config.StateConfig{Dir: "/custom/state"}}
+	dirs = DefaultDirs(cfg)
+	assert.Equal(t, "/custom/state", dirs.State)
+	assert.Equal(t, filepath.Join("/custom/state", "spool"), dirs.Spool)
+
+	cfg.Notifications.Webhook.Spool.Dir = "/custom/spool"
+	dirs = DefaultDirs(cfg)
+	assert.Equal(t, "/custom/spool", dirs.Spool)
+}
@@ -5,16 +5,67 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
 
+// Level is the severity of a log message
+type Level int
+
+const (
+	// LevelDebug is verbose tracing, e.g. every hook step
+	LevelDebug Level = iota
+	// LevelInfo is normal operation, e.g. a webhook was delivered
+	LevelInfo
+	// LevelWarn is a recoverable problem
+	LevelWarn
+	// LevelError is a failure
+	LevelError
+)
+
+// DefaultLevel is the minimum level logged unless SetLevel is called
+const DefaultLevel = LevelInfo
+
+// String returns the label written to the log for the level
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+}
+
+// ParseLevel parses a level name: debug, info, warn or error
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return DefaultLevel, fmt.Errorf("unknown log level: %s", name)
+	}
+}
+
 // Logger provides structured logging to a file
 type Logger struct {
 	file          *os.File
 	mu            sync.Mutex
 	prefix        string
-	consoleOutput bool // Enable output to console (stderr/stdout)
+	level         Level // messages below this level are dropped
+	consoleOutput bool  // Enable output to console (stderr/stdout)
 }
 
 var (
@@ -44,10 +95,18 @@ func NewLogger(path string) (*Logger, error) {
 	}
 
 	return &Logger{
-		file: f,
+		file:  f,
+		level: DefaultLevel,
 	}, nil
 }
 
+// SetLevel sets the minimum level that is written
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
 // SetPrefix sets a prefix for all log messages
 func (l *Logger) SetPrefix(prefix string) {
 	l.mu.Lock()
@@ -70,10 +129,14 @@ func (l *Logger) DisableConsoleOutput() {
 }
 
 // log writes a formatted log message with timestamp
-func (l *Logger) log(level, format string, args ...interface{}) {
+func (l *Logger) log(level Level, format string, args ...interface{}) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
+	if level < l.level {
+		return
+	}
+
 	timestamp := time.Now().Format("2006-01-02 15:04:05")
 	message := fmt.Sprintf(format, args...)
 
@@ -91,7 +154,7 @@ func (l *Logger) log(level, format string, args ...interface{}) {
 	if l.consoleOutput {
 		// Use stderr for errors and warnings, stdout for info and debug
 		var consoleOutput io.Writer
-		if level == "ERROR" || level == "WARN" {
+		if level >= LevelWarn {
 			consoleOutput = os.Stderr
 		} else {
 			consoleOutput = os.Stdout
@@ -110,22 +173,22 @@ func (l *Logger) log(level, format string, args ...interface{}) {
 
 // Debug logs a debug message
 func (l *Logger) Debug(format string, args ...interface{}) {
-	l.log("DEBUG", format, args...)
+	l.log(LevelDebug, format, args...)
 }
 
 // Info logs an info message
 func (l *Logger) Info(format string, args ...interface{}) {
-	l.log("INFO", format, args...)
+	l.log(LevelInfo, format, args...)
 }
 
 // Warn logs a warning message
 func (l *Logger) Warn(format string, args ...interface{}) {
-	l.log("WARN", format, args...)
+	l.log(LevelWarn, format, args...)
 }
 
 // Error logs an error message
 func (l *Logger) Error(format string, args ...interface{}) {
-	l.log("ERROR", format, args...)
+	l.log(LevelError, format, args...)
 }
 
 // Close closes the log file
@@ -146,6 +209,13 @@ func (l *Logger) GetWriter() io.Writer {
 
 // Global logger functions (use default logger)
 
+// SetDefault replaces the default logger and returns the previous one, e.g. to capture output in tests
+func SetDefault(l *Logger) *Logger {
+	previous := defaultLogger
+	defaultLogger = l
+	return previous
+}
+
 // Debug logs a debug message using the default logger
 func Debug(format string, args ...interface{}) {
 	if defaultLogger != nil {
@@ -181,6 +251,13 @@ func SetPrefix(prefix string) {
 	}
 }
 
+// SetLevel sets the minimum level written by the default logger
+func SetLevel(level Level) {
+	if defaultLogger != nil {
+		defaultLogger.SetLevel(level)
+	}
+}
+
 // EnableConsoleOutput enables console output for the default logger
 func EnableConsoleOutput() {
 	if defaultLogger != nil {
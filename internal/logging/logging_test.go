@@ -47,6 +47,7 @@ func TestLogger_Debug(t *testing.T) {
 	}
 	defer logger.Close()
 
+	logger.SetLevel(LevelDebug)
 	logger.Debug("test debug message: %s", "value")
 
 	// Read log file
@@ -288,6 +289,7 @@ func TestGlobalFunctions(t *testing.T) {
 	defer logger.Close()
 
 	// Test global functions
+	SetLevel(LevelDebug)
 	Debug("debug %s", "test")
 	Info("info %d", 123)
 	Warn("warn")
@@ -424,6 +426,7 @@ func TestLogger_AllLevelsFormat(t *testing.T) {
 	defer logger.Close()
 
 	logger.SetPrefix("APP")
+	logger.SetLevel(LevelDebug)
 
 	// Log all levels
 	logger.Debug("debug message")
@@ -499,6 +502,7 @@ func TestLogger_ConsoleOutput_InfoToStdout(t *testing.T) {
 	logger.EnableConsoleOutput()
 
 	// Log INFO and DEBUG (should go to stdout)
+	logger.SetLevel(LevelDebug)
 	logger.Info("info message")
 	logger.Debug("debug message")
 
@@ -516,3 +520,113 @@ func TestLogger_ConsoleOutput_InfoToStdout(t *testing.T) {
 		t.Error("Log should contain [DEBUG]")
 	}
 }
+
+func TestLogger_DefaultLevelDropsDebug(t *testing.T) {
+	tmpDir := t.TempDir()
+	logPath := filepath.Join(tmpDir, "default-level.log")
+
+	logger, err := NewLogger(logPath)
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer logger.Close()
+
+	logger.Debug("debug message")
+	logger.Info("info message")
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+
+	logContent := string(content)
+	if strings.Contains(logContent, "[DEBUG]") {
+		t.Errorf("Debug should be dropped at the default Info level, got: %s", logContent)
+	}
+	if !strings.Contains(logContent, "[INFO]") {
+		t.Errorf("Info should be written at the default level, got: %s", logContent)
+	}
+}
+
+func TestLogger_SetLevelWarn(t *testing.T) {
+	tmpDir := t.TempDir()
+	logPath := filepath.Join(tmpDir, "warn-level.log")
+
+	logger, err := NewLogger(logPath)
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer logger.Close()
+
+	logger.SetLevel(LevelWarn)
+	logger.Debug("debug message")
+	logger.Info("info message")
+	logger.Warn("warn message")
+	logger.Error("error message")
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+
+	logContent := string(content)
+	if strings.Contains(logContent, "[DEBUG]") || strings.Contains(logContent, "[INFO]") {
+		t.Errorf("Debug and Info should be dropped at Warn level, got: %s", logContent)
+	}
+	if !strings.Contains(logContent, "[WARN] warn message") {
+		t.Errorf("Warn should be written at Warn level, got: %s", logContent)
+	}
+	if !strings.Contains(logContent, "[ERROR] error message") {
+		t.Errorf("Error should be written at Warn level, got: %s", logContent)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Level
+		wantErr  bool
+	}{
+		{"debug", LevelDebug, false},
+		{"INFO", LevelInfo, false},
+		{"warn", LevelWarn, false},
+		{"warning", LevelWarn, false},
+		{"error", LevelError, false},
+		{"verbose", DefaultLevel, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, err := ParseLevel(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if level != tt.expected {
+				t.Errorf("ParseLevel(%q) = %v, want %v", tt.name, level, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "test.log")
+	logger, err := NewLogger(logPath)
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer logger.Close()
+
+	previous := SetDefault(logger)
+	Info("routed message")
+	if got := SetDefault(previous); got != logger {
+		t.Error("SetDefault() should return the logger it replaces")
+	}
+
+	content, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "routed message") {
+		t.Errorf("Expected global functions to use the new default logger, got: %s", content)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build cgo
+
+package platform
+
+// CgoEnabled reports whether the binary was built with cgo, which the SQLite state backend needs
+const CgoEnabled = true
@@ -0,0 +1,64 @@
+package platform
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile_Exclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+
+	unlock, err := LockFile(path, true)
+	require.NoError(t, err)
+
+	acquired := make(chan struct{})
+	go func() {
+		unlock2, err := LockFile(path, true)
+		if err == nil {
+			unlock2()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second exclusive lock acquired while first is held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	unlock()
+
+	select {
+	case <-acquired:
+	case <-time.After(2 * time.Second):
+		t.Fatal("second lock not acquired after release")
+	}
+}
+
+func TestLockFile_Shared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+
+	unlock1, err := LockFile(path, false)
+	require.NoError(t, err)
+	defer unlock1()
+
+	done := make(chan struct{})
+	go func() {
+		unlock2, err := LockFile(path, false)
+		assert.NoError(t, err)
+		if err == nil {
+			unlock2()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("shared locks should not block each other")
+	}
+}
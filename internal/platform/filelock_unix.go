@@ -0,0 +1,33 @@
+//go:build !windows
+
+package platform
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// LockFile acquires an advisory lock on path, creating the file if needed
+// Blocks until the lock is available. exclusive=false takes a shared (read) lock.
+// The returned function releases the lock.
+func LockFile(path string, exclusive bool) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if err := syscall.Flock(int(f.Fd()), how); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock file: %w", err)
+	}
+
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		_ = f.Close()
+	}, nil
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package platform
+
+import (
+	"os"
+	"time"
+)
+
+// birthTime returns the file creation time
+// Unix mtimes are reliable, so the portable stat data carries no creation time to fall back to
+func birthTime(info os.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
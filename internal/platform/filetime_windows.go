@@ -0,0 +1,19 @@
+//go:build windows
+
+package platform
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// birthTime returns the file creation time from the Win32 file attributes
+// Some filesystems and network shares on Windows report no usable mtime, but keep the creation time
+func birthTime(info os.FileInfo) (time.Time, bool) {
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok || attrs == nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, attrs.CreationTime.Nanoseconds()), true
+}
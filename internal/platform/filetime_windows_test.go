@@ -0,0 +1,51 @@
+//go:build windows
+
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBirthTime_Windows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	birth, ok := birthTime(info)
+	require.True(t, ok, "Windows should expose the creation time")
+	assert.WithinDuration(t, time.Now(), birth, 5*time.Second)
+}
+
+func TestFileAge_WindowsCreationTimeFallback(t *testing.T) {
+	// Hide the mtime but keep the Win32 attributes, so the creation time is used
+	statFile = func(path string) (os.FileInfo, error) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		return windowsZeroMTimeInfo{info}, nil
+	}
+	t.Cleanup(func() { statFile = os.Stat })
+
+	path := filepath.Join(t.TempDir(), "test.lock")
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+
+	age := FileAgeMillis(path)
+	assert.GreaterOrEqual(t, age, int64(0))
+	assert.Less(t, age, int64(5000))
+}
+
+// windowsZeroMTimeInfo hides only the mtime
+type windowsZeroMTimeInfo struct {
+	os.FileInfo
+}
+
+func (windowsZeroMTimeInfo) ModTime() time.Time { return time.Time{} }
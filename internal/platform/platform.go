@@ -1,9 +1,12 @@
 package platform
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -29,6 +32,39 @@ func TempDir() string {
 	return strings.TrimSuffix(tempDir, string(os.PathSeparator))
 }
 
+// StateSubdir is the directory inside the temp dir that holds state and lock files
+const StateSubdir = "claude-notifications"
+
+// StateDir returns the directory for state and lock files, creating it if needed
+// If the directory can't be created the path is still returned, so callers see the error on write
+func StateDir() string {
+	dir := filepath.Join(TempDir(), StateSubdir)
+	_ = os.MkdirAll(dir, 0755)
+	return dir
+}
+
+// MigrateFiles moves files matching pattern from fromDir into toDir
+// Files that already exist in toDir are newer, so the old copies are removed instead
+func MigrateFiles(fromDir, toDir, pattern string) error {
+	matches, err := filepath.Glob(filepath.Join(fromDir, pattern))
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, path := range matches {
+		dest := filepath.Join(toDir, filepath.Base(path))
+		if FileExists(dest) {
+			_ = os.Remove(path)
+			continue
+		}
+		if err := os.Rename(path, dest); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to migrate %s: %w", path, err)
+		}
+	}
+	return firstErr
+}
+
 // FileMTime returns the modification time of a file as Unix timestamp
 // Returns 0 if the file doesn't exist or on error
 func FileMTime(path string) int64 {
@@ -39,19 +75,117 @@ func FileMTime(path string) int64 {
 	return info.ModTime().Unix()
 }
 
+// statFile is os.Stat, replaceable in tests to simulate filesystems without usable mtimes
+var statFile = os.Stat
+
+// maxLockRecordSize bounds the file size read when looking for a lock record
+const maxLockRecordSize = 64
+
+// MaxLockOwnerHold caps how long a live owner keeps a lock, guarding against PID reuse
+const MaxLockOwnerHold = 2 * time.Minute
+
+// LockOwner is the record AtomicCreateFile writes into a lock file
+type LockOwner struct {
+	PID     int       // creating process, 0 if not recorded
+	Created time.Time // creation time
+}
+
+// fileTime returns the best available timestamp for a file's age
+// It prefers the mtime, then the creation (birth) time where the OS exposes it,
+// then the creation time AtomicCreateFile records in the file itself.
+// Returns false if the file doesn't exist or none of these is usable.
+func fileTime(path string) (time.Time, bool) {
+	info, err := statFile(path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if mtime := info.ModTime(); usableFileTime(mtime) {
+		return mtime, true
+	}
+	if birth, ok := birthTime(info); ok && usableFileTime(birth) {
+		return birth, true
+	}
+	if info.Size() == 0 || info.Size() > maxLockRecordSize {
+		return time.Time{}, false
+	}
+	owner, ok := ReadLockOwner(path)
+	return owner.Created, ok
+}
+
+// usableFileTime reports whether t looks like a real timestamp rather than a zero or unset value
+func usableFileTime(t time.Time) bool {
+	return !t.IsZero() && t.Unix() > 0
+}
+
+// ReadLockOwner parses the "<unix millis> <pid>" record AtomicCreateFile writes into lock files
+// Older locks may hold only the timestamp, in which case PID is 0
+// Returns false if the file is missing, too large or holds no valid record
+func ReadLockOwner(path string) (LockOwner, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 || len(data) > maxLockRecordSize {
+		return LockOwner{}, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return LockOwner{}, false
+	}
+	millis, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil || millis <= 0 {
+		return LockOwner{}, false
+	}
+	owner := LockOwner{Created: time.UnixMilli(millis)}
+	if len(fields) > 1 {
+		if pid, err := strconv.Atoi(fields[1]); err == nil && pid > 0 {
+			owner.PID = pid
+		}
+	}
+	return owner, true
+}
+
+// IsLockStale reports whether a lock that its owner releases when done can be taken over
+// A lock whose recorded owner process has exited is stale at once (the owner crashed),
+// while a live owner keeps it past ttl, up to MaxLockOwnerHold.
+// Locks without an owner record fall back to their age; an unknown age counts as stale.
+func IsLockStale(path string, ttl time.Duration) bool {
+	if owner, ok := ReadLockOwner(path); ok && owner.PID > 0 {
+		if !ProcessAlive(owner.PID) {
+			return true
+		}
+		return time.Since(owner.Created) >= MaxLockOwnerHold
+	}
+	age := FileAgeMillis(path)
+	return age < 0 || age >= ttl.Milliseconds()
+}
+
 // CurrentTimestamp returns the current Unix timestamp
 func CurrentTimestamp() int64 {
 	return time.Now().Unix()
 }
 
+// CurrentTimestampMillis returns the current Unix timestamp in milliseconds
+func CurrentTimestampMillis() int64 {
+	return time.Now().UnixMilli()
+}
+
 // FileAge returns the age of a file in seconds
-// Returns -1 if the file doesn't exist
+// Falls back to the creation time when the mtime is unusable (see fileTime)
+// Returns -1 if the file doesn't exist or no timestamp is available
 func FileAge(path string) int64 {
-	mtime := FileMTime(path)
-	if mtime == 0 {
+	t, ok := fileTime(path)
+	if !ok {
+		return -1
+	}
+	return CurrentTimestamp() - t.Unix()
+}
+
+// FileAgeMillis returns the age of a file in milliseconds
+// Returns -1 if the file doesn't exist or no timestamp is available
+func FileAgeMillis(path string) int64 {
+	t, ok := fileTime(path)
+	if !ok {
 		return -1
 	}
-	return CurrentTimestamp() - mtime
+	return CurrentTimestampMillis() - t.UnixMilli()
 }
 
 // FileExists checks if a file exists
@@ -77,6 +211,8 @@ func CleanupOldFiles(dir, pattern string, maxAge int64) error {
 }
 
 // AtomicCreateFile creates a file atomically using O_EXCL flag
+// The creation time and PID are written into the file (see ReadLockOwner),
+// so FileAge works where mtimes are unreliable and owners can be checked for liveness
 // Returns true if file was created, false if it already exists
 func AtomicCreateFile(path string) (bool, error) {
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
@@ -86,6 +222,7 @@ func AtomicCreateFile(path string) (bool, error) {
 		}
 		return false, err
 	}
+	_, _ = fmt.Fprintf(f, "%d %d", CurrentTimestampMillis(), os.Getpid())
 	f.Close()
 	return true, nil
 }
@@ -100,6 +237,34 @@ func ExpandEnv(s string) string {
 	return os.ExpandEnv(s)
 }
 
+// ExpandEnvStrict is ExpandEnv that fails if a referenced variable is not set
+// Variables set to an empty value are allowed
+func ExpandEnvStrict(s string) (string, error) {
+	var missing []string
+	expanded := os.Expand(s, func(name string) string {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// ExpandEnvKeepMissing expands set variables and leaves references to unset ones as ${VAR},
+// so a later ExpandEnvStrict can still report them
+func ExpandEnvKeepMissing(s string) string {
+	return os.Expand(s, func(name string) string {
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		return "${" + name + "}"
+	})
+}
+
 // IsWindows returns true if running on Windows
 func IsWindows() bool {
 	return runtime.GOOS == "windows"
@@ -114,3 +279,61 @@ func IsMacOS() bool {
 func IsLinux() bool {
 	return runtime.GOOS == "linux"
 }
+
+// GetGitBranch returns the current git branch for cwd
+// Returns empty string if cwd is empty or not inside a git repository
+func GetGitBranch(cwd string) string {
+	if cwd == "" {
+		return ""
+	}
+
+	out, err := exec.Command("git", "-C", cwd, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// GetGitCommit returns the short hash of the current git commit for cwd
+// Returns empty string if cwd is empty or not inside a git repository
+func GetGitCommit(cwd string) string {
+	if cwd == "" {
+		return ""
+	}
+
+	out, err := exec.Command("git", "-C", cwd, "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// GetGitDirty reports whether the git working tree at cwd has uncommitted changes
+// Returns false if cwd is empty or not inside a git repository
+func GetGitDirty(cwd string) bool {
+	if cwd == "" {
+		return false
+	}
+
+	out, err := exec.Command("git", "-C", cwd, "status", "--porcelain").Output()
+	if err != nil {
+		return false
+	}
+	return len(strings.TrimSpace(string(out))) > 0
+}
+
+// GetProjectName returns a short, human-readable name for the project at cwd
+// Uses the git repository's top-level directory name when cwd is inside a repository, otherwise the basename of cwd
+// Returns empty string if cwd is empty
+func GetProjectName(cwd string) string {
+	if cwd == "" {
+		return ""
+	}
+
+	if out, err := exec.Command("git", "-C", cwd, "rev-parse", "--show-toplevel").Output(); err == nil {
+		if top := strings.TrimSpace(string(out)); top != "" {
+			return filepath.Base(top)
+		}
+	}
+	return filepath.Base(filepath.Clean(cwd))
+}
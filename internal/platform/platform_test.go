@@ -1,9 +1,12 @@
 package platform
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"testing"
 	"time"
 
@@ -23,6 +26,39 @@ func TestTempDir(t *testing.T) {
 	assert.NotEqual(t, "/", tempDir[len(tempDir)-1:])
 }
 
+func TestStateDir(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	dir := StateDir()
+	assert.Equal(t, filepath.Join(TempDir(), StateSubdir), dir)
+	assert.DirExists(t, dir)
+}
+
+func TestMigrateFiles(t *testing.T) {
+	fromDir := t.TempDir()
+	toDir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(fromDir, "a.lock"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(fromDir, "b.lock"), []byte("old"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(fromDir, "c.txt"), []byte("c"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(toDir, "b.lock"), []byte("new"), 0644))
+
+	require.NoError(t, MigrateFiles(fromDir, toDir, "*.lock"))
+
+	// Moved files
+	assert.NoFileExists(t, filepath.Join(fromDir, "a.lock"))
+	assert.FileExists(t, filepath.Join(toDir, "a.lock"))
+
+	// Existing destination wins, stale source is removed
+	assert.NoFileExists(t, filepath.Join(fromDir, "b.lock"))
+	data, err := os.ReadFile(filepath.Join(toDir, "b.lock"))
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+
+	// Non-matching files are left alone
+	assert.FileExists(t, filepath.Join(fromDir, "c.txt"))
+}
+
 func TestFileExists(t *testing.T) {
 	// Create temp file
 	tmpFile := filepath.Join(t.TempDir(), "test.txt")
@@ -62,12 +98,90 @@ func TestFileAge(t *testing.T) {
 	assert.Equal(t, int64(-1), age)
 }
 
+func TestFileAgeMillis(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "test.txt")
+	require.NoError(t, os.WriteFile(tmpFile, []byte("test"), 0644))
+
+	mtime := time.Now().Add(-1500 * time.Millisecond)
+	require.NoError(t, os.Chtimes(tmpFile, mtime, mtime))
+
+	age := FileAgeMillis(tmpFile)
+	assert.GreaterOrEqual(t, age, int64(1500))
+	assert.Less(t, age, int64(2500))
+
+	// Non-existent file
+	assert.Equal(t, int64(-1), FileAgeMillis("/nonexistent/file"))
+}
+
+// zeroMTimeInfo hides a file's mtime, as seen on filesystems that don't report one
+type zeroMTimeInfo struct {
+	os.FileInfo
+}
+
+func (zeroMTimeInfo) ModTime() time.Time { return time.Time{} }
+
+// Sys drops the OS data so no creation time is available either
+func (zeroMTimeInfo) Sys() interface{} { return nil }
+
+func withoutMTime(t *testing.T) {
+	t.Helper()
+	statFile = func(path string) (os.FileInfo, error) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		return zeroMTimeInfo{info}, nil
+	}
+	t.Cleanup(func() { statFile = os.Stat })
+}
+
+func TestFileAge_RecordedTimeFallback(t *testing.T) {
+	withoutMTime(t)
+	lockPath := filepath.Join(t.TempDir(), "test.lock")
+
+	created, err := AtomicCreateFile(lockPath)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	// The creation time written by AtomicCreateFile stands in for the missing mtime
+	age := FileAgeMillis(lockPath)
+	assert.GreaterOrEqual(t, age, int64(0))
+	assert.Less(t, age, int64(5000))
+	assert.GreaterOrEqual(t, FileAge(lockPath), int64(0))
+
+	// Older recorded times age accordingly
+	old := time.Now().Add(-10 * time.Second).UnixMilli()
+	require.NoError(t, os.WriteFile(lockPath, []byte(strconv.FormatInt(old, 10)), 0644))
+	assert.InDelta(t, 10, FileAge(lockPath), 1)
+}
+
+func TestFileAge_NoTimestampAvailable(t *testing.T) {
+	withoutMTime(t)
+	dir := t.TempDir()
+
+	empty := filepath.Join(dir, "empty.lock")
+	require.NoError(t, os.WriteFile(empty, nil, 0644))
+	assert.Equal(t, int64(-1), FileAge(empty))
+	assert.Equal(t, int64(-1), FileAgeMillis(empty))
+
+	garbage := filepath.Join(dir, "garbage.lock")
+	require.NoError(t, os.WriteFile(garbage, []byte("not a timestamp"), 0644))
+	assert.Equal(t, int64(-1), FileAgeMillis(garbage))
+}
+
 func TestCurrentTimestamp(t *testing.T) {
 	ts := CurrentTimestamp()
 	now := time.Now().Unix()
 	assert.InDelta(t, now, ts, 1) // Within 1 second
 }
 
+func TestCurrentTimestampMillis(t *testing.T) {
+	ts := CurrentTimestampMillis()
+	now := time.Now().UnixMilli()
+	assert.InDelta(t, now, ts, 100)
+	assert.InDelta(t, CurrentTimestamp(), ts/1000, 1)
+}
+
 func TestAtomicCreateFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	filePath := filepath.Join(tmpDir, "test.lock")
@@ -83,6 +197,87 @@ func TestAtomicCreateFile(t *testing.T) {
 	assert.False(t, created)
 }
 
+// exitedPID returns the PID of a process that has already exited
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, cmd.Run())
+	return cmd.Process.Pid
+}
+
+func TestAtomicCreateFile_RecordsOwner(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "test.lock")
+
+	created, err := AtomicCreateFile(filePath)
+	require.NoError(t, err)
+	require.True(t, created)
+
+	owner, ok := ReadLockOwner(filePath)
+	require.True(t, ok)
+	assert.Equal(t, os.Getpid(), owner.PID)
+	assert.WithinDuration(t, time.Now(), owner.Created, 5*time.Second)
+}
+
+func TestReadLockOwner(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	// Timestamp-only records from older versions
+	owner, ok := ReadLockOwner(write("legacy.lock", "1700000000000"))
+	require.True(t, ok)
+	assert.Equal(t, 0, owner.PID)
+	assert.Equal(t, int64(1700000000000), owner.Created.UnixMilli())
+
+	_, ok = ReadLockOwner(write("empty.lock", ""))
+	assert.False(t, ok)
+	_, ok = ReadLockOwner(write("garbage.lock", "hello world"))
+	assert.False(t, ok)
+	_, ok = ReadLockOwner(filepath.Join(dir, "missing.lock"))
+	assert.False(t, ok)
+}
+
+func TestProcessAlive(t *testing.T) {
+	assert.True(t, ProcessAlive(os.Getpid()))
+	assert.False(t, ProcessAlive(exitedPID(t)))
+	assert.False(t, ProcessAlive(0))
+	assert.False(t, ProcessAlive(-1))
+}
+
+func TestIsLockStale(t *testing.T) {
+	dir := t.TempDir()
+	ttl := 2 * time.Second
+	writeOwner := func(name string, created time.Time, pid int) string {
+		path := filepath.Join(dir, name)
+		record := fmt.Sprintf("%d %d", created.UnixMilli(), pid)
+		require.NoError(t, os.WriteFile(path, []byte(record), 0644))
+		return path
+	}
+
+	// Recorded owner no longer running: stale even though the lock is brand new
+	assert.True(t, IsLockStale(writeOwner("dead.lock", time.Now(), exitedPID(t)), ttl))
+
+	// Live owner keeps the lock past the TTL...
+	live := writeOwner("live.lock", time.Now().Add(-10*time.Second), os.Getpid())
+	assert.False(t, IsLockStale(live, ttl))
+
+	// ...but not forever, in case the PID was reused
+	reused := writeOwner("reused.lock", time.Now().Add(-MaxLockOwnerHold-time.Second), os.Getpid())
+	assert.True(t, IsLockStale(reused, ttl))
+
+	// Without an owner record, age decides
+	fresh := filepath.Join(dir, "fresh.lock")
+	require.NoError(t, os.WriteFile(fresh, nil, 0644))
+	assert.False(t, IsLockStale(fresh, ttl))
+
+	old := time.Now().Add(-3 * time.Second)
+	require.NoError(t, os.Chtimes(fresh, old, old))
+	assert.True(t, IsLockStale(fresh, ttl))
+}
+
 func TestCleanupOldFiles(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -142,6 +337,29 @@ func TestExpandEnv(t *testing.T) {
 	assert.Equal(t, "test_value/path", result)
 }
 
+func TestExpandEnvStrict(t *testing.T) {
+	t.Setenv("TEST_TOKEN", "secret")
+	t.Setenv("TEST_EMPTY", "")
+
+	result, err := ExpandEnvStrict("Bearer ${TEST_TOKEN}")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer secret", result)
+
+	result, err = ExpandEnvStrict("x${TEST_EMPTY}y")
+	require.NoError(t, err)
+	assert.Equal(t, "xy", result, "set but empty variables are allowed")
+
+	_, err = ExpandEnvStrict("${TEST_TOKEN}/${TEST_MISSING_A}/$TEST_MISSING_B")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TEST_MISSING_A, TEST_MISSING_B")
+}
+
+func TestExpandEnvKeepMissing(t *testing.T) {
+	t.Setenv("TEST_HOST", "example.com")
+
+	assert.Equal(t, "https://example.com/${TEST_MISSING}", ExpandEnvKeepMissing("https://$TEST_HOST/$TEST_MISSING"))
+}
+
 func TestPlatformChecks(t *testing.T) {
 	// At least one should be true
 	assert.True(t, IsMacOS() || IsLinux() || IsWindows())
@@ -184,3 +402,116 @@ func TestAtomicCreateFile_PermissionDenied(t *testing.T) {
 	assert.False(t, created)
 	assert.Error(t, err, "Creating file in read-only directory should fail")
 }
+
+func TestGetGitBranch(t *testing.T) {
+	t.Run("empty cwd", func(t *testing.T) {
+		assert.Equal(t, "", GetGitBranch(""))
+	})
+
+	t.Run("non-existent path", func(t *testing.T) {
+		assert.Equal(t, "", GetGitBranch(filepath.Join(t.TempDir(), "missing")))
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		assert.Equal(t, "", GetGitBranch(t.TempDir()))
+	})
+
+	t.Run("repository", func(t *testing.T) {
+		dir, git := newGitRepo(t)
+		git("commit", "--allow-empty", "-m", "init")
+		git("checkout", "-b", "feature/login")
+
+		assert.Equal(t, "feature/login", GetGitBranch(dir))
+	})
+}
+
+func TestGetGitCommit(t *testing.T) {
+	t.Run("empty cwd", func(t *testing.T) {
+		assert.Equal(t, "", GetGitCommit(""))
+	})
+
+	t.Run("non-existent path", func(t *testing.T) {
+		assert.Equal(t, "", GetGitCommit(filepath.Join(t.TempDir(), "missing")))
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		assert.Equal(t, "", GetGitCommit(t.TempDir()))
+	})
+
+	t.Run("repository", func(t *testing.T) {
+		dir, git := newGitRepo(t)
+		git("commit", "--allow-empty", "-m", "init")
+
+		commit := GetGitCommit(dir)
+		assert.NotEmpty(t, commit)
+		assert.Less(t, len(commit), 40, "expected a short hash")
+	})
+
+	t.Run("repository without commits", func(t *testing.T) {
+		dir, _ := newGitRepo(t)
+		assert.Equal(t, "", GetGitCommit(dir))
+	})
+}
+
+func TestGetGitDirty(t *testing.T) {
+	t.Run("empty cwd", func(t *testing.T) {
+		assert.False(t, GetGitDirty(""))
+	})
+
+	t.Run("non-existent path", func(t *testing.T) {
+		assert.False(t, GetGitDirty(filepath.Join(t.TempDir(), "missing")))
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		assert.False(t, GetGitDirty(t.TempDir()))
+	})
+
+	t.Run("repository", func(t *testing.T) {
+		dir, git := newGitRepo(t)
+		git("commit", "--allow-empty", "-m", "init")
+		assert.False(t, GetGitDirty(dir))
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("change"), 0644))
+		assert.True(t, GetGitDirty(dir))
+	})
+}
+
+func TestGetProjectName(t *testing.T) {
+	t.Run("empty cwd", func(t *testing.T) {
+		assert.Equal(t, "", GetProjectName(""))
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "my-project")
+		require.NoError(t, os.Mkdir(dir, 0755))
+
+		assert.Equal(t, "my-project", GetProjectName(dir))
+		assert.Equal(t, "my-project", GetProjectName(dir+string(filepath.Separator)))
+	})
+
+	t.Run("repository subdirectory", func(t *testing.T) {
+		dir, _ := newGitRepo(t)
+		sub := filepath.Join(dir, "internal", "pkg")
+		require.NoError(t, os.MkdirAll(sub, 0755))
+
+		assert.Equal(t, filepath.Base(dir), GetProjectName(sub))
+		assert.Equal(t, filepath.Base(dir), GetProjectName(dir))
+	})
+}
+
+// newGitRepo initializes an empty git repository and returns it with a git command helper
+func newGitRepo(t *testing.T) (string, func(args ...string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init")
+	return dir, git
+}
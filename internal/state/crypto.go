@@ -0,0 +1,73 @@
+package state
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// encryptedMagic prefixes encrypted state files so they can be told apart from plaintext JSON
+var encryptedMagic = []byte("CNSTATE1")
+
+// errStateEncrypted is returned when an encrypted state file is read without a key
+var errStateEncrypted = errors.New("state file is encrypted but no encryption key is configured")
+
+// stateCipher encrypts state files with AES-256-GCM
+type stateCipher struct {
+	aead cipher.AEAD
+}
+
+// newStateCipher creates a cipher keyed from passphrase
+func newStateCipher(passphrase string) (*stateCipher, error) {
+	if passphrase == "" {
+		return nil, errors.New("encryption passphrase is empty")
+	}
+
+	key := sha256.Sum256([]byte("claude-notifications state v1\x00" + passphrase))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &stateCipher{aead: aead}, nil
+}
+
+// seal encrypts plaintext as magic || nonce || ciphertext
+func (c *stateCipher) seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(encryptedMagic)+len(nonce)+len(plaintext)+c.aead.Overhead())
+	out = append(out, encryptedMagic...)
+	out = append(out, nonce...)
+	return c.aead.Seal(out, nonce, plaintext, encryptedMagic), nil
+}
+
+// open decrypts data produced by seal
+func (c *stateCipher) open(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(data, encryptedMagic)
+	if len(data) < c.aead.NonceSize() {
+		return nil, errors.New("failed to decrypt state file: data too short")
+	}
+
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, encryptedMagic)
+	if err != nil {
+		return nil, errors.New("failed to decrypt state file: wrong key or corrupted data")
+	}
+	return plaintext, nil
+}
+
+// isEncrypted reports whether data was written by seal
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedMagic)
+}
@@ -0,0 +1,82 @@
+package state
+
+import (
+	"os"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedFileStore_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewEncryptedFileStore(dir, "correct horse battery staple")
+	require.NoError(t, err)
+	mgr := NewManagerWithStore(store)
+
+	secret := "Refactored auth.go to read API_TOKEN=hunter2"
+	require.NoError(t, mgr.UpdateLastNotification("enc-session", analyzer.StatusTaskComplete, secret))
+
+	// Nothing readable on disk
+	data, err := os.ReadFile(store.path("enc-session"))
+	require.NoError(t, err)
+	assert.True(t, isEncrypted(data))
+	assert.NotContains(t, string(data), "hunter2")
+	assert.NotContains(t, string(data), "enc-session")
+
+	loaded, err := mgr.Load("enc-session")
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, secret, loaded.LastNotificationMessage)
+	assert.Equal(t, "enc-session", loaded.SessionID)
+}
+
+func TestEncryptedFileStore_WrongKey(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewEncryptedFileStore(dir, "right key")
+	require.NoError(t, err)
+	require.NoError(t, store.Save(&SessionState{SessionID: "wrong-key", LastNotificationMessage: "secret"}))
+
+	other, err := NewEncryptedFileStore(dir, "wrong key")
+	require.NoError(t, err)
+	state, err := other.Load("wrong-key")
+	assert.Nil(t, state)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wrong key or corrupted data")
+
+	// Without a key the file is reported as encrypted rather than as bad JSON
+	state, err = NewFileStore(dir).Load("wrong-key")
+	assert.Nil(t, state)
+	assert.ErrorIs(t, err, errStateEncrypted)
+}
+
+func TestEncryptedFileStore_ReadsPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, NewFileStore(dir).Save(&SessionState{SessionID: "legacy", LastNotificationMessage: "old"}))
+
+	store, err := NewEncryptedFileStore(dir, "key")
+	require.NoError(t, err)
+
+	loaded, err := store.Load("legacy")
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, "old", loaded.LastNotificationMessage)
+
+	// Saving again encrypts it
+	require.NoError(t, store.Save(loaded))
+	data, err := os.ReadFile(store.path("legacy"))
+	require.NoError(t, err)
+	assert.True(t, isEncrypted(data))
+}
+
+func TestNewStore_EncryptionKey(t *testing.T) {
+	store, err := NewStore(config.StateConfig{Dir: t.TempDir(), EncryptionKey: "key"})
+	require.NoError(t, err)
+	require.IsType(t, &FileStore{}, store)
+	assert.NotNil(t, store.(*FileStore).cipher)
+
+	_, err = NewEncryptedFileStore(t.TempDir(), "")
+	assert.Error(t, err)
+}
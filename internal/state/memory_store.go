@@ -0,0 +1,89 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/777genius/claude-notifications/internal/platform"
+)
+
+// MemoryStore keeps session state in memory, for tests and ephemeral runs
+// State is stored serialized so callers can't mutate it through returned pointers
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	data      []byte
+	updatedAt int64 // unix seconds
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+// Load returns a copy of the state for a session, or nil if none exists
+func (s *MemoryStore) Load(sessionID string) (*SessionState, error) {
+	s.mu.Lock()
+	entry, ok := s.entries[sessionID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	var state SessionState
+	if err := json.Unmarshal(entry.data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state: %w", err)
+	}
+	return &state, nil
+}
+
+// Save creates or replaces the state for a session
+func (s *MemoryStore) Save(state *SessionState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to serialize state: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[state.SessionID] = memoryEntry{data: data, updatedAt: platform.CurrentTimestamp()}
+	return nil
+}
+
+// Delete removes the state for a session
+func (s *MemoryStore) Delete(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, sessionID)
+	return nil
+}
+
+// Cleanup removes state not updated within maxAge seconds
+func (s *MemoryStore) Cleanup(maxAge int64) error {
+	cutoff := platform.CurrentTimestamp() - maxAge
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sessionID, entry := range s.entries {
+		if entry.updatedAt < cutoff {
+			delete(s.entries, sessionID)
+		}
+	}
+	return nil
+}
+
+// Stats summarizes the stored state
+func (s *MemoryStore) Stats() (StateStats, error) {
+	var stats StateStats
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.entries {
+		stats.add(entry.updatedAt, int64(len(entry.data)))
+	}
+	return stats, nil
+}
@@ -0,0 +1,133 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_LoadSaveDelete(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryStore()
+
+	// Missing session returns nil without error
+	state, err := store.Load("missing")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	require.NoError(t, store.Save(&SessionState{SessionID: "s1", LastInteractiveTool: "ExitPlanMode", CWD: "/project"}))
+
+	state, err = store.Load("s1")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, "ExitPlanMode", state.LastInteractiveTool)
+	assert.Equal(t, "/project", state.CWD)
+
+	// Mutating a loaded state doesn't change the stored copy
+	state.LastInteractiveTool = "AskUserQuestion"
+	stored, err := store.Load("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "ExitPlanMode", stored.LastInteractiveTool)
+
+	// Save replaces existing state
+	require.NoError(t, store.Save(state))
+	state, err = store.Load("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "AskUserQuestion", state.LastInteractiveTool)
+
+	require.NoError(t, store.Delete("s1"))
+	state, err = store.Load("s1")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	// Deleting missing state is not an error
+	assert.NoError(t, store.Delete("s1"))
+}
+
+func TestMemoryStore_Stats(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryStore()
+
+	require.NoError(t, store.Save(&SessionState{SessionID: "a"}))
+	require.NoError(t, store.Save(&SessionState{SessionID: "b"}))
+
+	stats, err := store.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Count)
+	assert.LessOrEqual(t, stats.Oldest, stats.Newest)
+	assert.Greater(t, stats.TotalBytes, int64(0))
+}
+
+func TestMemoryStore_Cleanup(t *testing.T) {
+	t.Parallel()
+	store := NewMemoryStore()
+
+	require.NoError(t, store.Save(&SessionState{SessionID: "old"}))
+	require.NoError(t, store.Save(&SessionState{SessionID: "new"}))
+
+	store.mu.Lock()
+	entry := store.entries["old"]
+	entry.updatedAt = platform.CurrentTimestamp() - 120
+	store.entries["old"] = entry
+	store.mu.Unlock()
+
+	require.NoError(t, store.Cleanup(60))
+
+	state, err := store.Load("old")
+	require.NoError(t, err)
+	assert.Nil(t, state, "old state should be cleaned up")
+
+	state, err = store.Load("new")
+	require.NoError(t, err)
+	assert.NotNil(t, state, "recent state should be kept")
+}
+
+func TestManager_WithMemoryStore(t *testing.T) {
+	t.Parallel()
+	mgr := NewManagerWithStore(NewMemoryStore())
+	sessionID := "test-memory-workflow"
+
+	require.NoError(t, mgr.UpdateInteractiveTool(sessionID, "ExitPlanMode", "/project"))
+	require.NoError(t, mgr.UpdateLastNotification(sessionID, analyzer.StatusPlanReady, "Plan ready"))
+
+	suppress, err := mgr.ShouldSuppressQuestionAfterAnyNotification(sessionID, 60)
+	require.NoError(t, err)
+	assert.True(t, suppress)
+
+	history, err := mgr.GetHistory(sessionID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "Plan ready", history[0].Message)
+}
+
+func TestMemoryStore_ConcurrentSessions(t *testing.T) {
+	t.Parallel()
+	mgr := NewManagerWithStore(NewMemoryStore())
+
+	done := make(chan error)
+	for i := 0; i < 10; i++ {
+		go func(i int) {
+			done <- mgr.UpdateInteractiveTool(fmt.Sprintf("session-%d", i), "ExitPlanMode", "/project")
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		require.NoError(t, <-done)
+	}
+
+	for i := 0; i < 10; i++ {
+		state, err := mgr.Load(fmt.Sprintf("session-%d", i))
+		require.NoError(t, err)
+		require.NotNil(t, state)
+	}
+}
+
+func TestNewStore_Memory(t *testing.T) {
+	store, err := NewStore(config.StateConfig{Backend: "memory"})
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryStore{}, store)
+}
@@ -0,0 +1,36 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CurrentSchemaVersion is the SessionState layout written by this version
+const CurrentSchemaVersion = 1
+
+// ErrUnsupportedSchema is returned when state was written by a newer version
+var ErrUnsupportedSchema = errors.New("unsupported session state schema version")
+
+// migrations[i] upgrades state from schema version i to i+1
+var migrations = []func(state *SessionState){
+	// v0 -> v1: schema_version introduced, layout otherwise unchanged
+	func(state *SessionState) {},
+}
+
+// migrate upgrades state to CurrentSchemaVersion in place
+// Reports whether anything changed; state from a newer version is rejected
+// rather than guessed at, since its fields may no longer mean what we expect
+func migrate(state *SessionState) (bool, error) {
+	if state.SchemaVersion > CurrentSchemaVersion || state.SchemaVersion < 0 {
+		return false, fmt.Errorf("%w: %d (current: %d)", ErrUnsupportedSchema, state.SchemaVersion, CurrentSchemaVersion)
+	}
+	if state.SchemaVersion == CurrentSchemaVersion {
+		return false, nil
+	}
+
+	for v := state.SchemaVersion; v < CurrentSchemaVersion; v++ {
+		migrations[v](state)
+	}
+	state.SchemaVersion = CurrentSchemaVersion
+	return true, nil
+}
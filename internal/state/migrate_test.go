@@ -0,0 +1,65 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_LoadMigratesV0(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	mgr := NewManagerWithStore(store)
+
+	// Written before schema_version existed
+	v0 := `{
+  "session_id": "v0-session",
+  "last_interactive_tool": "ExitPlanMode",
+  "last_ts": 1700000000,
+  "last_notification_message": "Done",
+  "cwd": "/project"
+}`
+	require.NoError(t, os.WriteFile(store.path("v0-session"), []byte(v0), 0644))
+
+	state, err := mgr.Load("v0-session")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, CurrentSchemaVersion, state.SchemaVersion)
+	assert.Equal(t, "ExitPlanMode", state.LastInteractiveTool)
+	assert.Equal(t, int64(1700000000), state.LastTimestamp)
+	assert.Equal(t, "Done", state.LastNotificationMessage)
+
+	// Migrated file is written back
+	data, err := os.ReadFile(store.path("v0-session"))
+	require.NoError(t, err)
+	var onDisk map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &onDisk))
+	assert.Equal(t, float64(CurrentSchemaVersion), onDisk["schema_version"])
+}
+
+func TestManager_LoadRejectsNewerSchema(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	mgr := NewManagerWithStore(store)
+
+	require.NoError(t, store.Save(&SessionState{SchemaVersion: CurrentSchemaVersion + 1, SessionID: "future"}))
+	state, err := mgr.Load("future")
+	assert.Nil(t, state)
+	assert.ErrorIs(t, err, ErrUnsupportedSchema)
+
+	// The newer file is left untouched
+	loaded, err := store.Load("future")
+	require.NoError(t, err)
+	assert.Equal(t, CurrentSchemaVersion+1, loaded.SchemaVersion)
+}
+
+func TestManager_SaveStampsSchemaVersion(t *testing.T) {
+	mgr := NewManagerWithStore(NewMemoryStore())
+
+	require.NoError(t, mgr.Save(&SessionState{SessionID: "stamped"}))
+
+	state, err := mgr.Load("stamped")
+	require.NoError(t, err)
+	assert.Equal(t, CurrentSchemaVersion, state.SchemaVersion)
+}
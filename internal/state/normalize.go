@@ -0,0 +1,106 @@
+package state
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// NormalizationRule is an extra step applied when comparing messages for duplicates
+type NormalizationRule string
+
+const (
+	// RuleStripEmoji removes emoji and pictographic symbols
+	RuleStripEmoji NormalizationRule = "strip-emoji"
+	// RuleCollapseWhitespace replaces runs of whitespace with a single space
+	RuleCollapseWhitespace NormalizationRule = "collapse-whitespace"
+	// RuleStripMarkdown removes markdown emphasis, headings, quotes, list markers and link targets
+	RuleStripMarkdown NormalizationRule = "strip-markdown"
+	// RuleStripPunctuation removes all punctuation characters
+	RuleStripPunctuation NormalizationRule = "strip-punctuation"
+)
+
+var (
+	markdownLinkRe    = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownHeadingRe = regexp.MustCompile(`(?m)^[ \t]*#{1,6}[ \t]+`)
+	markdownQuoteRe   = regexp.MustCompile(`(?m)^[ \t]*>[ \t]?`)
+	markdownListRe    = regexp.MustCompile(`(?m)^[ \t]*[-*+][ \t]+`)
+)
+
+// ParseNormalizationRules converts rule names from config into rules
+func ParseNormalizationRules(names []string) ([]NormalizationRule, error) {
+	rules := make([]NormalizationRule, 0, len(names))
+	for _, name := range names {
+		rule := NormalizationRule(name)
+		switch rule {
+		case RuleStripEmoji, RuleCollapseWhitespace, RuleStripMarkdown, RuleStripPunctuation:
+			rules = append(rules, rule)
+		default:
+			return nil, fmt.Errorf("unknown normalization rule: %s", name)
+		}
+	}
+	return rules, nil
+}
+
+// NormalizeMessage normalizes a message the same way duplicate detection does,
+// so other components can key on message content consistently
+func NormalizeMessage(msg string, rules ...NormalizationRule) string {
+	return normalizeMessage(msg, rules...)
+}
+
+// normalizeMessage normalizes a message for duplicate comparison
+// Configured rules run first, then the base normalization:
+// trim whitespace, strip trailing dots, lowercase
+func normalizeMessage(msg string, rules ...NormalizationRule) string {
+	for _, rule := range rules {
+		msg = applyRule(msg, rule)
+	}
+
+	msg = strings.TrimSpace(msg)
+	msg = strings.TrimRight(msg, ".")
+	msg = strings.ToLower(msg)
+	return msg
+}
+
+// applyRule applies a single normalization rule
+func applyRule(msg string, rule NormalizationRule) string {
+	switch rule {
+	case RuleStripEmoji:
+		return strings.Map(func(r rune) rune {
+			if isEmoji(r) {
+				return -1
+			}
+			return r
+		}, msg)
+	case RuleCollapseWhitespace:
+		return strings.Join(strings.Fields(msg), " ")
+	case RuleStripMarkdown:
+		msg = markdownLinkRe.ReplaceAllString(msg, "$1")
+		msg = markdownHeadingRe.ReplaceAllString(msg, "")
+		msg = markdownQuoteRe.ReplaceAllString(msg, "")
+		msg = markdownListRe.ReplaceAllString(msg, "")
+		return strings.NewReplacer("**", "", "__", "", "*", "", "_", "", "`", "", "~~", "").Replace(msg)
+	case RuleStripPunctuation:
+		return strings.Map(func(r rune) rune {
+			if unicode.IsPunct(r) {
+				return -1
+			}
+			return r
+		}, msg)
+	}
+	return msg
+}
+
+// isEmoji reports whether r is an emoji or one of its modifiers/joiners
+func isEmoji(r rune) bool {
+	switch {
+	case r == 0x200D: // zero width joiner
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F: // variation selectors
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // skin tone modifiers
+		return true
+	}
+	return unicode.Is(unicode.So, r)
+}
@@ -0,0 +1,45 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeMessage_Default(t *testing.T) {
+	assert.Equal(t, "task complete", normalizeMessage("  Task Complete...  "))
+	assert.Equal(t, "✅ task  complete", normalizeMessage("✅ Task  Complete."))
+}
+
+func TestNormalizeMessage_Rules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule NormalizationRule
+		a, b string
+	}{
+		{"strip-emoji", RuleStripEmoji, "✅ Task complete", "Task complete"},
+		{"strip-emoji with modifiers", RuleStripEmoji, "👍🏽 Done", "Done"},
+		{"collapse-whitespace", RuleCollapseWhitespace, "Task\n\tcomplete   now", "Task complete now"},
+		{"strip-markdown emphasis", RuleStripMarkdown, "**Task** _complete_ `now`", "Task complete now"},
+		{"strip-markdown heading and link", RuleStripMarkdown, "## See [docs](https://example.com)", "See docs"},
+		{"strip-markdown list and quote", RuleStripMarkdown, "- item\n> quoted", "item\nquoted"},
+		{"strip-punctuation", RuleStripPunctuation, "Done! Ready? Yes, (really).", "Done Ready Yes really"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NotEqual(t, normalizeMessage(tt.a), normalizeMessage(tt.b), "default normalization should differ")
+			assert.Equal(t, normalizeMessage(tt.b, tt.rule), normalizeMessage(tt.a, tt.rule))
+		})
+	}
+}
+
+func TestParseNormalizationRules(t *testing.T) {
+	rules, err := ParseNormalizationRules([]string{"strip-emoji", "strip-punctuation"})
+	require.NoError(t, err)
+	assert.Equal(t, []NormalizationRule{RuleStripEmoji, RuleStripPunctuation}, rules)
+
+	_, err = ParseNormalizationRules([]string{"strip-everything"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,8 @@
+//go:build !cgo
+
+package state
+
+// newSQLiteStore fails without cgo, the SQLite driver needs it
+func newSQLiteStore(path string) (StateStore, error) {
+	return nil, ErrSQLiteUnsupported
+}
@@ -0,0 +1,15 @@
+//go:build !cgo
+
+package state
+
+import (
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStore_SQLiteWithoutCgo(t *testing.T) {
+	_, err := NewStore(config.StateConfig{Backend: "sqlite", Dir: t.TempDir()})
+	assert.ErrorIs(t, err, ErrSQLiteUnsupported)
+}
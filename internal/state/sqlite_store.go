@@ -0,0 +1,125 @@
+//go:build cgo
+
+package state
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/777genius/claude-notifications/internal/platform"
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+)
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS session_state (
+	session_id TEXT PRIMARY KEY,
+	data       TEXT NOT NULL,
+	updated_at INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_session_state_updated_at ON session_state(updated_at);`
+
+// SQLiteStore stores session state in a single SQLite database keyed on session ID
+// Suited to many concurrent sessions, where one file per session gets slow to clean up
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// newSQLiteStore opens the SQLite store for NewStore
+func newSQLiteStore(path string) (StateStore, error) {
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// NewSQLiteStore opens (or creates) the database at path
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	// WAL + busy timeout let concurrent hook processes share the database
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL", path)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize state database: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// Load loads session state from the database
+// Returns nil if no row exists for the session
+func (s *SQLiteStore) Load(sessionID string) (*SessionState, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM session_state WHERE session_id = ?`, sessionID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+
+	var state SessionState
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state: %w", err)
+	}
+
+	return &state, nil
+}
+
+// Save inserts or replaces session state
+func (s *SQLiteStore) Save(state *SessionState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to serialize state: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT INTO session_state (session_id, data, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`,
+		state.SessionID, string(data), platform.CurrentTimestamp(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write state: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes session state
+func (s *SQLiteStore) Delete(sessionID string) error {
+	if _, err := s.db.Exec(`DELETE FROM session_state WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete state: %w", err)
+	}
+	return nil
+}
+
+// Cleanup deletes state not updated within maxAge seconds in a single statement
+func (s *SQLiteStore) Cleanup(maxAge int64) error {
+	cutoff := platform.CurrentTimestamp() - maxAge
+	if _, err := s.db.Exec(`DELETE FROM session_state WHERE updated_at < ?`, cutoff); err != nil {
+		return fmt.Errorf("failed to cleanup state: %w", err)
+	}
+	return nil
+}
+
+// Stats summarizes the stored state in a single query
+func (s *SQLiteStore) Stats() (StateStats, error) {
+	var stats StateStats
+	err := s.db.QueryRow(
+		`SELECT COUNT(*), COALESCE(MIN(updated_at), 0), COALESCE(MAX(updated_at), 0), COALESCE(SUM(LENGTH(data)), 0) FROM session_state`,
+	).Scan(&stats.Count, &stats.Oldest, &stats.Newest, &stats.TotalBytes)
+	if err != nil {
+		return StateStats{}, fmt.Errorf("failed to read state stats: %w", err)
+	}
+	return stats, nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
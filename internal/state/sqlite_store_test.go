@@ -0,0 +1,143 @@
+//go:build cgo
+
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestSQLiteStore_LoadSaveDelete(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	// Missing session returns nil without error
+	state, err := store.Load("missing")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	err = store.Save(&SessionState{SessionID: "s1", LastInteractiveTool: "ExitPlanMode", CWD: "/project"})
+	require.NoError(t, err)
+
+	state, err = store.Load("s1")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, "ExitPlanMode", state.LastInteractiveTool)
+	assert.Equal(t, "/project", state.CWD)
+
+	// Save replaces existing state
+	state.LastInteractiveTool = "AskUserQuestion"
+	require.NoError(t, store.Save(state))
+	state, err = store.Load("s1")
+	require.NoError(t, err)
+	assert.Equal(t, "AskUserQuestion", state.LastInteractiveTool)
+
+	require.NoError(t, store.Delete("s1"))
+	state, err = store.Load("s1")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	// Deleting missing state is not an error
+	assert.NoError(t, store.Delete("s1"))
+}
+
+func TestSQLiteStore_Stats(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	stats, err := store.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, StateStats{}, stats)
+
+	require.NoError(t, store.Save(&SessionState{SessionID: "s1"}))
+	require.NoError(t, store.Save(&SessionState{SessionID: "s2"}))
+	_, err = store.db.Exec(`UPDATE session_state SET updated_at = updated_at - 100 WHERE session_id = 's1'`)
+	require.NoError(t, err)
+
+	stats, err = store.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Count)
+	assert.Equal(t, stats.Newest-100, stats.Oldest)
+	assert.Greater(t, stats.TotalBytes, int64(0))
+}
+
+func TestSQLiteStore_Cleanup(t *testing.T) {
+	store := newTestSQLiteStore(t)
+
+	require.NoError(t, store.Save(&SessionState{SessionID: "old"}))
+	require.NoError(t, store.Save(&SessionState{SessionID: "new"}))
+
+	_, err := store.db.Exec(`UPDATE session_state SET updated_at = ? WHERE session_id = ?`,
+		platform.CurrentTimestamp()-120, "old")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Cleanup(60))
+
+	state, err := store.Load("old")
+	require.NoError(t, err)
+	assert.Nil(t, state, "old state should be cleaned up")
+
+	state, err = store.Load("new")
+	require.NoError(t, err)
+	assert.NotNil(t, state, "recent state should be kept")
+}
+
+func TestManager_WithSQLiteStore(t *testing.T) {
+	mgr := NewManagerWithStore(newTestSQLiteStore(t))
+	sessionID := "test-sqlite-workflow"
+
+	require.NoError(t, mgr.UpdateInteractiveTool(sessionID, "ExitPlanMode", "/project"))
+	require.NoError(t, mgr.UpdateLastNotification(sessionID, analyzer.StatusPlanReady, "Plan ready"))
+
+	suppress, err := mgr.ShouldSuppressQuestionAfterAnyNotification(sessionID, 60)
+	require.NoError(t, err)
+	assert.True(t, suppress)
+
+	state, err := mgr.Load(sessionID)
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, "ExitPlanMode", state.LastInteractiveTool)
+	assert.Equal(t, "Plan ready", state.LastNotificationMessage)
+}
+
+func TestNewStore(t *testing.T) {
+	store, err := NewStore(config.StateConfig{})
+	require.NoError(t, err)
+	assert.IsType(t, &FileStore{}, store)
+
+	store, err = NewStore(config.StateConfig{Backend: "sqlite", Path: filepath.Join(t.TempDir(), "state.db")})
+	require.NoError(t, err)
+	assert.IsType(t, &SQLiteStore{}, store)
+	_ = store.(*SQLiteStore).Close()
+
+	_, err = NewStore(config.StateConfig{Backend: "redis"})
+	assert.Error(t, err)
+}
+
+func TestNewStore_CustomDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "custom", "state")
+
+	store, err := NewStore(config.StateConfig{Dir: dir})
+	require.NoError(t, err)
+	mgr := NewManagerWithStore(store)
+
+	require.NoError(t, mgr.UpdateTaskComplete("custom-dir-session"))
+	assert.FileExists(t, filepath.Join(dir, "claude-session-state-custom-dir-session.json"))
+
+	store, err = NewStore(config.StateConfig{Backend: "sqlite", Dir: dir})
+	require.NoError(t, err)
+	_ = store.(*SQLiteStore).Close()
+	assert.FileExists(t, filepath.Join(dir, sqliteFileName))
+}
@@ -1,10 +1,9 @@
 package state
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"path/filepath"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
 
 	"github.com/777genius/claude-notifications/internal/analyzer"
 	"github.com/777genius/claude-notifications/internal/platform"
@@ -12,80 +11,136 @@ import (
 
 // SessionState represents per-session state
 type SessionState struct {
-	SessionID              string `json:"session_id"`
-	LastInteractiveTool    string `json:"last_interactive_tool"`
-	LastTimestamp          int64  `json:"last_ts"`
-	LastTaskCompleteTime   int64  `json:"last_task_complete_ts,omitempty"`
-	LastNotificationTime   int64  `json:"last_notification_ts,omitempty"`
-	LastNotificationStatus string `json:"last_notification_status,omitempty"`
-	CWD                    string `json:"cwd"`
+	SchemaVersion           int                  `json:"schema_version"` // layout version, see CurrentSchemaVersion; 0 for files written before versioning
+	SessionID               string               `json:"session_id"`
+	LastInteractiveTool     string               `json:"last_interactive_tool"`
+	LastTimestamp           int64                `json:"last_ts"`
+	LastTaskCompleteTime    int64                `json:"last_task_complete_ts,omitempty"`
+	LastNotificationTime    int64                `json:"last_notification_ts,omitempty"`
+	LastNotificationStatus  string               `json:"last_notification_status,omitempty"`
+	LastNotificationMessage string               `json:"last_notification_message,omitempty"`
+	CWD                     string               `json:"cwd"`
+	ProjectName             string               `json:"project_name,omitempty"`             // derived from CWD, see platform.GetProjectName
+	History                 []NotificationRecord `json:"history,omitempty"`                  // oldest first, capped at maxHistoryEntries
+	Threads                 map[string]string    `json:"threads,omitempty"`                  // webhook destination name -> thread the session's notifications reply in (e.g. Slack ts)
+	LastMessageRef          string               `json:"last_message_ref,omitempty"`         // ID the endpoint returned for the last notification, for later edits or deletes
+	LastMessageDestination  string               `json:"last_message_destination,omitempty"` // webhook destination LastMessageRef belongs to
+	RecentHashes            []ContentHash        `json:"recent_hashes,omitempty"`            // normalized message hashes of recent notifications, oldest first, capped at maxRecentHashes
 }
 
+// NotificationRecord is a single entry in a session's notification history
+type NotificationRecord struct {
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	Timestamp int64  `json:"ts"`
+}
+
+// maxHistoryEntries is how many notifications are kept per session
+const maxHistoryEntries = 50
+
+// ContentHash records when a notification with a given normalized message was sent
+type ContentHash struct {
+	Hash      string `json:"hash"`
+	Timestamp int64  `json:"ts"`
+}
+
+// maxRecentHashes is how many message hashes are kept per session for duplicate detection
+const maxRecentHashes = 20
+
 // Manager manages session state
 type Manager struct {
-	tempDir string
+	tempDir            string
+	store              StateStore
+	normalizationRules []NormalizationRule
+	recentDedup        bool // IsDuplicateMessage also checks RecentHashes, see SetRecentMessageDedup
 }
 
-// NewManager creates a new state manager
+// NewManager creates a new state manager backed by JSON files in the temp dir
 func NewManager() *Manager {
 	return &Manager{
-		tempDir: platform.TempDir(),
+		tempDir: prepareDefaultDir(),
+	}
+}
+
+// NewManagerWithStore creates a new state manager backed by the given store
+func NewManagerWithStore(store StateStore) *Manager {
+	return &Manager{
+		tempDir: DefaultDir(),
+		store:   store,
 	}
 }
 
+// SetNormalizationRules sets extra rules used when comparing messages for duplicates
+func (m *Manager) SetNormalizationRules(rules []NormalizationRule) {
+	m.normalizationRules = rules
+}
+
+// SetRecentMessageDedup makes IsDuplicateMessage catch repeats of any recent message,
+// not just the last one
+func (m *Manager) SetRecentMessageDedup(enabled bool) {
+	m.recentDedup = enabled
+}
+
+// contentHash returns the hash of message after normalization, as stored in RecentHashes
+func (m *Manager) contentHash(message string) string {
+	sum := sha256.Sum256([]byte(normalizeMessage(message, m.normalizationRules...)))
+	return hex.EncodeToString(sum[:16])
+}
+
 // getStatePath returns the path to the state file for a session
 func (m *Manager) getStatePath(sessionID string) string {
-	return filepath.Join(m.tempDir, fmt.Sprintf("claude-session-state-%s.json", sessionID))
+	return NewFileStore(m.tempDir).path(sessionID)
+}
+
+// backend returns the configured store, defaulting to JSON files in tempDir
+func (m *Manager) backend() StateStore {
+	if m.store != nil {
+		return m.store
+	}
+	return NewFileStore(m.tempDir)
 }
 
-// Load loads session state from disk
-// Returns nil if state file doesn't exist
+// Load loads session state
+// Returns nil if no state exists for the session
+// State written by an older version is migrated and saved back in the current layout
 func (m *Manager) Load(sessionID string) (*SessionState, error) {
-	path := m.getStatePath(sessionID)
-	if !platform.FileExists(path) {
-		return nil, nil
+	state, err := m.backend().Load(sessionID)
+	if err != nil || state == nil {
+		return state, err
 	}
 
-	data, err := os.ReadFile(path)
+	migrated, err := migrate(state)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read state file: %w", err)
+		return nil, err
 	}
-
-	var state SessionState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	if migrated {
+		// A failed write-back is harmless: the migration simply runs again next time
+		_ = m.backend().Save(state)
 	}
-
-	return &state, nil
+	return state, nil
 }
 
-// Save saves session state to disk
+// Save saves session state in the current schema version
 func (m *Manager) Save(state *SessionState) error {
-	path := m.getStatePath(state.SessionID)
-
-	data, err := json.MarshalIndent(state, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to serialize state: %w", err)
-	}
-
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("failed to write state file: %w", err)
-	}
-
-	return nil
+	state.SchemaVersion = CurrentSchemaVersion
+	return m.backend().Save(state)
 }
 
 // Delete deletes session state
 func (m *Manager) Delete(sessionID string) error {
-	path := m.getStatePath(sessionID)
-	if !platform.FileExists(path) {
-		return nil
-	}
+	return m.backend().Delete(sessionID)
+}
 
-	if err := os.Remove(path); err != nil {
-		return fmt.Errorf("failed to delete state file: %w", err)
-	}
+// Stats summarizes stored session state: count, oldest/newest update times and total size
+func (m *Manager) Stats() (StateStats, error) {
+	return m.backend().Stats()
+}
 
+// Close releases resources held by the store (e.g. the SQLite connection)
+func (m *Manager) Close() error {
+	if closer, ok := m.backend().(io.Closer); ok {
+		return closer.Close()
+	}
 	return nil
 }
 
@@ -104,6 +159,10 @@ func (m *Manager) UpdateInteractiveTool(sessionID, toolName, cwd string) error {
 
 	state.LastInteractiveTool = toolName
 	state.LastTimestamp = platform.CurrentTimestamp()
+	// Only shell out to git when the directory changes
+	if cwd != state.CWD || state.ProjectName == "" {
+		state.ProjectName = platform.GetProjectName(cwd)
+	}
 	state.CWD = cwd
 
 	return m.Save(state)
@@ -150,6 +209,25 @@ func (m *Manager) ShouldSuppressQuestion(sessionID string, cooldownSeconds int)
 	return elapsed < int64(cooldownSeconds), nil
 }
 
+// ResetCooldowns clears the timestamps that suppress notifications for a session,
+// so the next notification is judged as if the session had sent none
+// History and the rest of the state are kept; a missing session is not an error
+func (m *Manager) ResetCooldowns(sessionID string) error {
+	state, err := m.Load(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if state == nil {
+		return nil
+	}
+
+	state.LastTaskCompleteTime = 0
+	state.LastNotificationTime = 0
+
+	return m.Save(state)
+}
+
 // UpdateState updates state based on the detected status
 func (m *Manager) UpdateState(sessionID string, status analyzer.Status, toolName, cwd string) error {
 	switch status {
@@ -163,13 +241,13 @@ func (m *Manager) UpdateState(sessionID string, status analyzer.Status, toolName
 	return nil
 }
 
-// Cleanup cleans up old state files (older than maxAge seconds)
+// Cleanup cleans up old session state (older than maxAge seconds)
 func (m *Manager) Cleanup(maxAge int64) error {
-	return platform.CleanupOldFiles(m.tempDir, "claude-session-state-*.json", maxAge)
+	return m.backend().Cleanup(maxAge)
 }
 
-// UpdateLastNotification updates the last notification timestamp and status
-func (m *Manager) UpdateLastNotification(sessionID string, status analyzer.Status) error {
+// UpdateLastNotification updates the last notification timestamp, status, and message
+func (m *Manager) UpdateLastNotification(sessionID string, status analyzer.Status, message string) error {
 	state, err := m.Load(sessionID)
 	if err != nil {
 		return err
@@ -183,10 +261,171 @@ func (m *Manager) UpdateLastNotification(sessionID string, status analyzer.Statu
 
 	state.LastNotificationTime = platform.CurrentTimestamp()
 	state.LastNotificationStatus = string(status)
+	state.LastNotificationMessage = message
+
+	state.History = append(state.History, NotificationRecord{
+		Status:    string(status),
+		Message:   message,
+		Timestamp: state.LastNotificationTime,
+	})
+	if len(state.History) > maxHistoryEntries {
+		state.History = state.History[len(state.History)-maxHistoryEntries:]
+	}
+
+	// Hashes are recorded even while recent dedup is off, so turning it on takes effect immediately
+	state.RecentHashes = append(state.RecentHashes, ContentHash{
+		Hash:      m.contentHash(message),
+		Timestamp: state.LastNotificationTime,
+	})
+	if len(state.RecentHashes) > maxRecentHashes {
+		state.RecentHashes = state.RecentHashes[len(state.RecentHashes)-maxRecentHashes:]
+	}
+
+	return m.Save(state)
+}
+
+// GetHistory returns the notifications sent for a session, oldest first
+// Returns an empty slice if the session has no history
+func (m *Manager) GetHistory(sessionID string) ([]NotificationRecord, error) {
+	state, err := m.Load(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil {
+		return []NotificationRecord{}, nil
+	}
+
+	history := make([]NotificationRecord, len(state.History))
+	copy(history, state.History)
+	return history, nil
+}
+
+// ThreadID returns the thread a session's notifications to destination reply in
+// Returns empty string if no thread has been started yet
+func (m *Manager) ThreadID(sessionID, destination string) (string, error) {
+	state, err := m.Load(sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	if state == nil {
+		return "", nil
+	}
+
+	return state.Threads[destination], nil
+}
+
+// SetThreadID records the thread later notifications to destination should reply in
+func (m *Manager) SetThreadID(sessionID, destination, threadID string) error {
+	state, err := m.Load(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if state == nil {
+		state = &SessionState{
+			SessionID: sessionID,
+		}
+	}
+
+	if state.Threads == nil {
+		state.Threads = make(map[string]string)
+	}
+	state.Threads[destination] = threadID
 
 	return m.Save(state)
 }
 
+// LastMessageRef returns the endpoint's ID for the session's last notification and the destination it was sent to
+// Returns empty strings if no reference has been recorded
+func (m *Manager) LastMessageRef(sessionID string) (string, string, error) {
+	state, err := m.Load(sessionID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if state == nil {
+		return "", "", nil
+	}
+
+	return state.LastMessageDestination, state.LastMessageRef, nil
+}
+
+// SetLastMessageRef records the endpoint's ID for the session's last notification
+func (m *Manager) SetLastMessageRef(sessionID, destination, ref string) error {
+	state, err := m.Load(sessionID)
+	if err != nil {
+		return err
+	}
+
+	if state == nil {
+		state = &SessionState{
+			SessionID: sessionID,
+		}
+	}
+
+	state.LastMessageDestination = destination
+	state.LastMessageRef = ref
+
+	return m.Save(state)
+}
+
+// IsDuplicateMessage checks if message matches the last notification sent
+// within windowSeconds, after normalization
+// With SetRecentMessageDedup, any of the session's recent messages within the window counts
+func (m *Manager) IsDuplicateMessage(sessionID, message string, windowSeconds int) (bool, error) {
+	if windowSeconds <= 0 {
+		return false, nil
+	}
+
+	state, err := m.Load(sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	if state == nil {
+		return false, nil
+	}
+
+	now := platform.CurrentTimestamp()
+	if state.LastNotificationMessage != "" && now-state.LastNotificationTime < int64(windowSeconds) &&
+		normalizeMessage(message, m.normalizationRules...) == normalizeMessage(state.LastNotificationMessage, m.normalizationRules...) {
+		return true, nil
+	}
+
+	if m.recentDedup {
+		hash := m.contentHash(message)
+		for _, recent := range state.RecentHashes {
+			if recent.Hash == hash && now-recent.Timestamp < int64(windowSeconds) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// ShouldSuppressAny checks if a notification of any status should be suppressed
+// because the session already sent one within the last minIntervalSeconds
+// Zero or negative intervals disable the throttle
+func (m *Manager) ShouldSuppressAny(sessionID string, minIntervalSeconds int) (bool, error) {
+	if minIntervalSeconds <= 0 {
+		return false, nil
+	}
+
+	state, err := m.Load(sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	if state == nil || state.LastNotificationTime == 0 {
+		return false, nil
+	}
+
+	elapsed := platform.CurrentTimestamp() - state.LastNotificationTime
+	return elapsed < int64(minIntervalSeconds), nil
+}
+
 // ShouldSuppressQuestionAfterAnyNotification checks if a question notification should be suppressed
 // due to being within the cooldown window after ANY notification
 func (m *Manager) ShouldSuppressQuestionAfterAnyNotification(sessionID string, cooldownSeconds int) (bool, error) {
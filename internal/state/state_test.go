@@ -1,9 +1,13 @@
 package state
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -103,6 +107,7 @@ func TestManager_UpdateInteractiveTool_NewState(t *testing.T) {
 	assert.Equal(t, sessionID, state.SessionID)
 	assert.Equal(t, "ExitPlanMode", state.LastInteractiveTool)
 	assert.Equal(t, "/test/dir", state.CWD)
+	assert.Equal(t, "dir", state.ProjectName)
 	assert.Greater(t, state.LastTimestamp, int64(0))
 }
 
@@ -130,6 +135,7 @@ func TestManager_UpdateInteractiveTool_ExistingState(t *testing.T) {
 
 	assert.Equal(t, "AskUserQuestion", state.LastInteractiveTool)
 	assert.Equal(t, "/new/dir", state.CWD)
+	assert.Equal(t, "dir", state.ProjectName)
 	assert.Greater(t, state.LastTimestamp, int64(0))
 	// Existing fields should be preserved
 	assert.Equal(t, int64(12345), state.LastTaskCompleteTime)
@@ -188,7 +194,7 @@ func TestManager_UpdateLastNotification_NewState(t *testing.T) {
 	sessionID := "test-notif-new"
 	defer func() { _ = mgr.Delete(sessionID) }()
 
-	err := mgr.UpdateLastNotification(sessionID, analyzer.StatusPlanReady)
+	err := mgr.UpdateLastNotification(sessionID, analyzer.StatusPlanReady, "Plan is ready")
 	require.NoError(t, err)
 
 	// Verify state was created
@@ -199,6 +205,7 @@ func TestManager_UpdateLastNotification_NewState(t *testing.T) {
 	assert.Equal(t, sessionID, state.SessionID)
 	assert.Greater(t, state.LastNotificationTime, int64(0))
 	assert.Equal(t, string(analyzer.StatusPlanReady), state.LastNotificationStatus)
+	assert.Equal(t, "Plan is ready", state.LastNotificationMessage)
 }
 
 func TestManager_UpdateLastNotification_ExistingState(t *testing.T) {
@@ -215,7 +222,7 @@ func TestManager_UpdateLastNotification_ExistingState(t *testing.T) {
 	require.NoError(t, err)
 
 	// Update last notification
-	err = mgr.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete)
+	err = mgr.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete, "")
 	require.NoError(t, err)
 
 	// Verify state was updated
@@ -229,6 +236,57 @@ func TestManager_UpdateLastNotification_ExistingState(t *testing.T) {
 	assert.Equal(t, "ExitPlanMode", state.LastInteractiveTool)
 }
 
+// === GetHistory Tests ===
+
+func TestManager_GetHistory_NoState(t *testing.T) {
+	mgr := NewManager()
+
+	history, err := mgr.GetHistory("test-history-none")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestManager_GetHistory_Accumulates(t *testing.T) {
+	mgr := NewManager()
+	sessionID := "test-history-accumulate"
+	defer func() { _ = mgr.Delete(sessionID) }()
+
+	require.NoError(t, mgr.UpdateLastNotification(sessionID, analyzer.StatusQuestion, "Which database?"))
+	require.NoError(t, mgr.UpdateLastNotification(sessionID, analyzer.StatusPlanReady, "Plan is ready"))
+	require.NoError(t, mgr.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete, "Done"))
+
+	history, err := mgr.GetHistory(sessionID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	assert.Equal(t, string(analyzer.StatusQuestion), history[0].Status)
+	assert.Equal(t, "Which database?", history[0].Message)
+	assert.Equal(t, string(analyzer.StatusTaskComplete), history[2].Status)
+	assert.Equal(t, "Done", history[2].Message)
+	for _, record := range history {
+		assert.Greater(t, record.Timestamp, int64(0))
+	}
+}
+
+func TestManager_GetHistory_Capped(t *testing.T) {
+	mgr := NewManager()
+	sessionID := "test-history-capped"
+	defer func() { _ = mgr.Delete(sessionID) }()
+
+	total := maxHistoryEntries + 5
+	for i := 0; i < total; i++ {
+		require.NoError(t, mgr.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete, fmt.Sprintf("message %d", i)))
+	}
+
+	history, err := mgr.GetHistory(sessionID)
+	require.NoError(t, err)
+	require.Len(t, history, maxHistoryEntries)
+
+	// Oldest entries are dropped
+	assert.Equal(t, "message 5", history[0].Message)
+	assert.Equal(t, fmt.Sprintf("message %d", total-1), history[len(history)-1].Message)
+}
+
 // === ShouldSuppressQuestion Tests ===
 
 func TestManager_ShouldSuppressQuestion_NoState(t *testing.T) {
@@ -377,6 +435,196 @@ func TestManager_ShouldSuppressAfterAny_OutsideCooldown(t *testing.T) {
 	assert.False(t, suppress)
 }
 
+// === Thread Tests ===
+
+func TestManager_ThreadID(t *testing.T) {
+	mgr := NewManagerWithStore(NewMemoryStore())
+	sessionID := "test-threads"
+
+	threadID, err := mgr.ThreadID(sessionID, "team")
+	require.NoError(t, err)
+	assert.Empty(t, threadID)
+
+	require.NoError(t, mgr.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete, "Done"))
+	require.NoError(t, mgr.SetThreadID(sessionID, "team", "1700000000.000100"))
+	require.NoError(t, mgr.SetThreadID(sessionID, "alerts", "1700000000.000200"))
+
+	threadID, err = mgr.ThreadID(sessionID, "team")
+	require.NoError(t, err)
+	assert.Equal(t, "1700000000.000100", threadID)
+
+	threadID, err = mgr.ThreadID(sessionID, "alerts")
+	require.NoError(t, err)
+	assert.Equal(t, "1700000000.000200", threadID)
+
+	// Other state is kept
+	history, err := mgr.GetHistory(sessionID)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+// === Message Ref Tests ===
+
+func TestManager_LastMessageRef(t *testing.T) {
+	mgr := NewManagerWithStore(NewMemoryStore())
+	sessionID := "test-message-ref"
+
+	dest, ref, err := mgr.LastMessageRef(sessionID)
+	require.NoError(t, err)
+	assert.Empty(t, dest)
+	assert.Empty(t, ref)
+
+	require.NoError(t, mgr.SetLastMessageRef(sessionID, "telegram", "4242"))
+	require.NoError(t, mgr.SetLastMessageRef(sessionID, "discord", "1234567890123456789"))
+
+	dest, ref, err = mgr.LastMessageRef(sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "discord", dest)
+	assert.Equal(t, "1234567890123456789", ref)
+}
+
+// === Duplicate Window vs Cooldown Tests ===
+
+func TestManager_DuplicateWindowIndependentOfCooldown(t *testing.T) {
+	mgr := NewManagerWithStore(NewMemoryStore())
+	sessionID := "test-windows-independent"
+
+	require.NoError(t, mgr.Save(&SessionState{
+		SessionID:               sessionID,
+		LastNotificationTime:    platform.CurrentTimestamp() - 10,
+		LastNotificationMessage: "Which database?",
+	}))
+
+	// Short duplicate window, long cooldown: the text may repeat but questions are still held back
+	duplicate, err := mgr.IsDuplicateMessage(sessionID, "Which database?", 5)
+	require.NoError(t, err)
+	assert.False(t, duplicate)
+	suppress, err := mgr.ShouldSuppressQuestionAfterAnyNotification(sessionID, 60)
+	require.NoError(t, err)
+	assert.True(t, suppress)
+
+	// Long duplicate window, short cooldown: questions may follow but the same text is dropped
+	duplicate, err = mgr.IsDuplicateMessage(sessionID, "Which database?", 60)
+	require.NoError(t, err)
+	assert.True(t, duplicate)
+	suppress, err = mgr.ShouldSuppressQuestionAfterAnyNotification(sessionID, 5)
+	require.NoError(t, err)
+	assert.False(t, suppress)
+
+	// A different text is never a duplicate, whatever the window
+	duplicate, err = mgr.IsDuplicateMessage(sessionID, "Which cache?", 60)
+	require.NoError(t, err)
+	assert.False(t, duplicate)
+}
+
+// === ResetCooldowns Tests ===
+
+func TestManager_ResetCooldowns_AllowsSuppressedQuestion(t *testing.T) {
+	mgr := NewManagerWithStore(NewMemoryStore())
+	sessionID := "test-reset-cooldowns"
+
+	require.NoError(t, mgr.UpdateTaskComplete(sessionID))
+	require.NoError(t, mgr.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete, "Done"))
+
+	suppress, err := mgr.ShouldSuppressQuestion(sessionID, 60)
+	require.NoError(t, err)
+	assert.True(t, suppress)
+	suppress, err = mgr.ShouldSuppressQuestionAfterAnyNotification(sessionID, 60)
+	require.NoError(t, err)
+	assert.True(t, suppress)
+	duplicate, err := mgr.IsDuplicateMessage(sessionID, "Done", 60)
+	require.NoError(t, err)
+	assert.True(t, duplicate)
+
+	require.NoError(t, mgr.ResetCooldowns(sessionID))
+
+	suppress, err = mgr.ShouldSuppressQuestion(sessionID, 60)
+	require.NoError(t, err)
+	assert.False(t, suppress)
+	suppress, err = mgr.ShouldSuppressQuestionAfterAnyNotification(sessionID, 60)
+	require.NoError(t, err)
+	assert.False(t, suppress)
+	duplicate, err = mgr.IsDuplicateMessage(sessionID, "Done", 60)
+	require.NoError(t, err)
+	assert.False(t, duplicate)
+
+	// History is kept
+	history, err := mgr.GetHistory(sessionID)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+func TestManager_ResetCooldowns_NoState(t *testing.T) {
+	mgr := NewManagerWithStore(NewMemoryStore())
+
+	require.NoError(t, mgr.ResetCooldowns("non-existent"))
+
+	state, err := mgr.Load("non-existent")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestManager_ShouldSuppressAny_Disabled(t *testing.T) {
+	mgr := NewManager()
+	sessionID := "test-suppress-any-disabled"
+	defer func() { _ = mgr.Delete(sessionID) }()
+
+	state := &SessionState{
+		SessionID:            sessionID,
+		LastNotificationTime: platform.CurrentTimestamp(),
+	}
+	require.NoError(t, mgr.Save(state))
+
+	for _, interval := range []int{0, -5} {
+		suppress, err := mgr.ShouldSuppressAny(sessionID, interval)
+		require.NoError(t, err)
+		assert.False(t, suppress, "interval %d should disable the throttle", interval)
+	}
+}
+
+func TestManager_ShouldSuppressAny_NoState(t *testing.T) {
+	mgr := NewManager()
+
+	suppress, err := mgr.ShouldSuppressAny("non-existent", 5)
+	require.NoError(t, err)
+	assert.False(t, suppress)
+}
+
+func TestManager_ShouldSuppressAny_WithinInterval(t *testing.T) {
+	mgr := NewManager()
+	sessionID := "test-suppress-any-interval-within"
+	defer func() { _ = mgr.Delete(sessionID) }()
+
+	state := &SessionState{
+		SessionID:              sessionID,
+		LastNotificationTime:   platform.CurrentTimestamp(),
+		LastNotificationStatus: string(analyzer.StatusTaskComplete),
+	}
+	err := mgr.Save(state)
+	require.NoError(t, err)
+
+	suppress, err := mgr.ShouldSuppressAny(sessionID, 5)
+	require.NoError(t, err)
+	assert.True(t, suppress)
+}
+
+func TestManager_ShouldSuppressAny_OutsideInterval(t *testing.T) {
+	mgr := NewManager()
+	sessionID := "test-suppress-any-interval-outside"
+	defer func() { _ = mgr.Delete(sessionID) }()
+
+	state := &SessionState{
+		SessionID:            sessionID,
+		LastNotificationTime: platform.CurrentTimestamp() - 6,
+	}
+	err := mgr.Save(state)
+	require.NoError(t, err)
+
+	suppress, err := mgr.ShouldSuppressAny(sessionID, 5)
+	require.NoError(t, err)
+	assert.False(t, suppress)
+}
+
 // === UpdateState Tests ===
 
 func TestManager_UpdateState_TaskComplete(t *testing.T) {
@@ -494,6 +742,34 @@ func TestManager_Cleanup_EmptyDirectory(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// === Stats Tests ===
+
+func TestManager_Stats(t *testing.T) {
+	store := NewFileStore(t.TempDir())
+	mgr := NewManagerWithStore(store)
+
+	stats, err := mgr.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, StateStats{}, stats)
+
+	require.NoError(t, mgr.Save(&SessionState{SessionID: "stats-1"}))
+	require.NoError(t, mgr.Save(&SessionState{SessionID: "stats-2", CWD: "/project"}))
+
+	// Age one session so oldest and newest differ
+	oldTime := time.Now().Add(-120 * time.Second)
+	require.NoError(t, os.Chtimes(store.path("stats-1"), oldTime, oldTime))
+
+	// Lock and temp files are not counted
+	require.NoError(t, os.WriteFile(filepath.Join(store.dir, "claude-session-state-x.tmp"), []byte("{}"), 0644))
+
+	stats, err = mgr.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Count)
+	assert.LessOrEqual(t, stats.Oldest, stats.Newest)
+	assert.Equal(t, oldTime.Unix(), stats.Oldest)
+	assert.Greater(t, stats.TotalBytes, int64(0))
+}
+
 // === Integration Tests ===
 
 func TestManager_FullWorkflow(t *testing.T) {
@@ -506,7 +782,7 @@ func TestManager_FullWorkflow(t *testing.T) {
 	require.NoError(t, err)
 
 	// 2. Update notification
-	err = mgr.UpdateLastNotification(sessionID, analyzer.StatusPlanReady)
+	err = mgr.UpdateLastNotification(sessionID, analyzer.StatusPlanReady, "")
 	require.NoError(t, err)
 
 	// 3. Question should be suppressed within cooldown
@@ -519,7 +795,7 @@ func TestManager_FullWorkflow(t *testing.T) {
 	require.NoError(t, err)
 
 	// 5. Update last notification
-	err = mgr.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete)
+	err = mgr.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete, "")
 	require.NoError(t, err)
 
 	// 6. Verify state contains all expected fields
@@ -548,6 +824,9 @@ func TestManager_StateFilePath(t *testing.T) {
 	// Should be an absolute path
 	assert.True(t, filepath.IsAbs(path), "path should be absolute")
 
+	// Should live in the dedicated subdirectory of the temp dir
+	assert.Equal(t, "claude-notifications", filepath.Base(filepath.Dir(path)))
+
 	// Should have correct filename format
 	expectedFilename := "claude-session-state-test-abc-123.json"
 	assert.Equal(t, expectedFilename, filepath.Base(path))
@@ -557,7 +836,7 @@ func TestLoad_InvalidJSON(t *testing.T) {
 	mgr := NewManager()
 	sessionID := "test-invalid-json"
 
-	// Create a file with invalid JSON
+	// Create a file with invalid JSON (Save writes atomically, so only external corruption can cause this)
 	path := mgr.getStatePath(sessionID)
 	err := os.WriteFile(path, []byte("{invalid json}"), 0644)
 	require.NoError(t, err)
@@ -600,3 +879,172 @@ func TestDelete_PermissionDenied(t *testing.T) {
 	// Restore permissions for cleanup
 	_ = os.Chmod(testTempDir, 0755)
 }
+
+// === IsDuplicateMessage Tests ===
+
+func TestManager_IsDuplicateMessage(t *testing.T) {
+	mgr := NewManager()
+	sessionID := "test-duplicate-message"
+	defer func() { _ = mgr.Delete(sessionID) }()
+
+	// No state yet
+	dup, err := mgr.IsDuplicateMessage(sessionID, "Task complete", 60)
+	require.NoError(t, err)
+	assert.False(t, dup)
+
+	err = mgr.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete, "Task complete.")
+	require.NoError(t, err)
+
+	dup, err = mgr.IsDuplicateMessage(sessionID, "  task complete ", 60)
+	require.NoError(t, err)
+	assert.True(t, dup, "default normalization ignores case, whitespace and trailing dots")
+
+	dup, err = mgr.IsDuplicateMessage(sessionID, "✅ Task complete", 60)
+	require.NoError(t, err)
+	assert.False(t, dup, "emoji prefix differs without strip-emoji rule")
+
+	mgr.SetNormalizationRules([]NormalizationRule{RuleStripEmoji})
+	dup, err = mgr.IsDuplicateMessage(sessionID, "✅ Task complete", 60)
+	require.NoError(t, err)
+	assert.True(t, dup)
+
+	// Disabled window
+	dup, err = mgr.IsDuplicateMessage(sessionID, "Task complete", 0)
+	require.NoError(t, err)
+	assert.False(t, dup)
+}
+
+func TestManager_IsDuplicateMessage_RecentMessages(t *testing.T) {
+	mgr := NewManager()
+	sessionID := "test-duplicate-message-recent"
+	defer func() { _ = mgr.Delete(sessionID) }()
+
+	require.NoError(t, mgr.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete, "Tests pass"))
+	require.NoError(t, mgr.UpdateLastNotification(sessionID, analyzer.StatusQuestion, "Which database?"))
+
+	// Only the last message counts by default
+	dup, err := mgr.IsDuplicateMessage(sessionID, "Tests pass", 60)
+	require.NoError(t, err)
+	assert.False(t, dup)
+
+	mgr.SetRecentMessageDedup(true)
+	dup, err = mgr.IsDuplicateMessage(sessionID, "tests pass.", 60)
+	require.NoError(t, err)
+	assert.True(t, dup, "a repeat after an intervening message is still a duplicate")
+
+	dup, err = mgr.IsDuplicateMessage(sessionID, "Which database?", 60)
+	require.NoError(t, err)
+	assert.True(t, dup)
+
+	dup, err = mgr.IsDuplicateMessage(sessionID, "Tests fail", 60)
+	require.NoError(t, err)
+	assert.False(t, dup)
+
+	// Hashes older than the window don't count
+	state, err := mgr.Load(sessionID)
+	require.NoError(t, err)
+	state.RecentHashes[0].Timestamp -= 120
+	require.NoError(t, mgr.Save(state))
+	dup, err = mgr.IsDuplicateMessage(sessionID, "Tests pass", 60)
+	require.NoError(t, err)
+	assert.False(t, dup)
+}
+
+func TestManager_RecentHashes_Capped(t *testing.T) {
+	mgr := NewManager()
+	mgr.SetRecentMessageDedup(true)
+	sessionID := "test-recent-hashes-capped"
+	defer func() { _ = mgr.Delete(sessionID) }()
+
+	for i := 0; i < maxRecentHashes+5; i++ {
+		require.NoError(t, mgr.UpdateLastNotification(sessionID, analyzer.StatusTaskComplete, fmt.Sprintf("message %d", i)))
+	}
+
+	state, err := mgr.Load(sessionID)
+	require.NoError(t, err)
+	assert.Len(t, state.RecentHashes, maxRecentHashes)
+
+	// The oldest hashes are dropped
+	dup, err := mgr.IsDuplicateMessage(sessionID, "message 0", 60)
+	require.NoError(t, err)
+	assert.False(t, dup)
+	dup, err = mgr.IsDuplicateMessage(sessionID, "message 5", 60)
+	require.NoError(t, err)
+	assert.True(t, dup)
+}
+
+func TestManager_IsDuplicateMessage_OutsideWindow(t *testing.T) {
+	mgr := NewManager()
+	sessionID := "test-duplicate-message-old"
+	defer func() { _ = mgr.Delete(sessionID) }()
+
+	err := mgr.Save(&SessionState{
+		SessionID:               sessionID,
+		LastNotificationTime:    platform.CurrentTimestamp() - 120,
+		LastNotificationMessage: "Task complete",
+	})
+	require.NoError(t, err)
+
+	dup, err := mgr.IsDuplicateMessage(sessionID, "Task complete", 60)
+	require.NoError(t, err)
+	assert.False(t, dup)
+}
+
+func TestSave_ConcurrentWritesAlwaysParse(t *testing.T) {
+	mgr := &Manager{tempDir: t.TempDir()}
+	sessionID := "test-concurrent-save"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := mgr.Save(&SessionState{
+				SessionID:           sessionID,
+				LastInteractiveTool: strings.Repeat("x", i*100), // vary size to expose truncation
+			})
+			assert.NoError(t, err)
+		}(i)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := mgr.Load(sessionID)
+			assert.NoError(t, err, "state file must never be partially written")
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(mgr.getStatePath(sessionID))
+	require.NoError(t, err)
+	var state SessionState
+	require.NoError(t, json.Unmarshal(data, &state))
+	assert.Equal(t, sessionID, state.SessionID)
+
+	// No temp files left behind
+	tmps, _ := filepath.Glob(filepath.Join(mgr.tempDir, "*.tmp"))
+	assert.Empty(t, tmps)
+}
+
+func TestDefaultDir_NoSideEffects(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("TMPDIR does not move the temp dir on Windows")
+	}
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+
+	legacy := filepath.Join(tmpDir, "claude-session-state-old.json")
+	require.NoError(t, os.WriteFile(legacy, []byte("{}"), 0600))
+
+	// A plain path lookup neither creates the directory nor moves files
+	dir := DefaultDir()
+	assert.Equal(t, filepath.Join(tmpDir, platform.StateSubdir), dir)
+	assert.NoDirExists(t, dir)
+	assert.FileExists(t, legacy)
+
+	// Constructing the store migrates once
+	mgr := NewManager()
+	assert.Equal(t, dir, mgr.tempDir)
+	assert.NoFileExists(t, legacy)
+	assert.FileExists(t, filepath.Join(dir, "claude-session-state-old.json"))
+}
@@ -0,0 +1,279 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/platform"
+)
+
+// StateStore persists session state
+type StateStore interface {
+	// Load returns the state for a session, or nil if none exists
+	Load(sessionID string) (*SessionState, error)
+	// Save creates or replaces the state for a session
+	Save(state *SessionState) error
+	// Delete removes the state for a session; missing state is not an error
+	Delete(sessionID string) error
+	// Cleanup removes state not updated within maxAge seconds
+	Cleanup(maxAge int64) error
+	// Stats summarizes the stored state
+	Stats() (StateStats, error)
+}
+
+// StateStats summarizes stored session state
+type StateStats struct {
+	Count      int   // number of sessions with state
+	Oldest     int64 // unix seconds of the least recently updated session, 0 if none
+	Newest     int64 // unix seconds of the most recently updated session, 0 if none
+	TotalBytes int64 // serialized size of all state
+}
+
+// add records one session updated at updatedAt with size bytes of state
+func (s *StateStats) add(updatedAt, size int64) {
+	if s.Count == 0 || updatedAt < s.Oldest {
+		s.Oldest = updatedAt
+	}
+	if s.Count == 0 || updatedAt > s.Newest {
+		s.Newest = updatedAt
+	}
+	s.Count++
+	s.TotalBytes += size
+}
+
+// sqliteFileName is the database file name used when no explicit path is configured
+const sqliteFileName = "claude-notifications-state.db"
+
+// DefaultSQLitePath returns the default location of the SQLite state database
+func DefaultSQLitePath() string {
+	return filepath.Join(platform.TempDir(), sqliteFileName)
+}
+
+// ErrSQLiteUnsupported is returned for the sqlite backend by binaries built without cgo
+var ErrSQLiteUnsupported = errors.New("state backend sqlite requires a build with cgo enabled")
+
+// stateFilePattern matches FileStore state files
+const stateFilePattern = "claude-session-state-*.json"
+
+// NewStore creates the store selected by config
+func NewStore(cfg config.StateConfig) (StateStore, error) {
+	switch cfg.Backend {
+	case "", "file":
+		dir := cfg.Dir
+		if dir == "" {
+			dir = prepareDefaultDir()
+		} else if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create state dir: %w", err)
+		}
+		if cfg.EncryptionKey != "" {
+			return NewEncryptedFileStore(dir, cfg.EncryptionKey)
+		}
+		return NewFileStore(dir), nil
+	case "sqlite":
+		path := cfg.Path
+		if path == "" && cfg.Dir != "" {
+			if err := os.MkdirAll(cfg.Dir, 0700); err != nil {
+				return nil, fmt.Errorf("failed to create state dir: %w", err)
+			}
+			path = filepath.Join(cfg.Dir, sqliteFileName)
+		}
+		if path == "" {
+			path = DefaultSQLitePath()
+		}
+		return newSQLiteStore(path)
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown state backend: %s", cfg.Backend)
+	}
+}
+
+// DefaultDir returns the directory for file-backed state
+func DefaultDir() string {
+	return filepath.Join(platform.TempDir(), platform.StateSubdir)
+}
+
+// prepareDefaultDir creates the default state directory for a new store and moves in
+// the state files that older versions left directly in the temp dir
+func prepareDefaultDir() string {
+	dir := platform.StateDir()
+	_ = platform.MigrateFiles(platform.TempDir(), dir, stateFilePattern)
+	_ = platform.MigrateFiles(platform.TempDir(), dir, "claude-session-state-*.lock")
+	return dir
+}
+
+// FileStore stores each session as a JSON file in a directory
+type FileStore struct {
+	dir    string
+	cipher *stateCipher // nil when state is stored as plaintext
+}
+
+// NewFileStore creates a file store in dir
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// NewEncryptedFileStore creates a file store in dir that encrypts state with AES-GCM
+// The key is derived from passphrase; existing plaintext files are still readable
+// and are encrypted the next time they are saved
+func NewEncryptedFileStore(dir, passphrase string) (*FileStore, error) {
+	c, err := newStateCipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir, cipher: c}, nil
+}
+
+// path returns the path to the state file for a session
+func (s *FileStore) path(sessionID string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("claude-session-state-%s.json", sessionID))
+}
+
+// lockPath returns the path to the advisory lock file guarding a session's state file
+func (s *FileStore) lockPath(sessionID string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("claude-session-state-%s.lock", sessionID))
+}
+
+// Load loads session state from disk
+// Returns nil if state file doesn't exist
+func (s *FileStore) Load(sessionID string) (*SessionState, error) {
+	path := s.path(sessionID)
+	if !platform.FileExists(path) {
+		return nil, nil
+	}
+
+	unlock, err := platform.LockFile(s.lockPath(sessionID), false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	if isEncrypted(data) {
+		if s.cipher == nil {
+			return nil, errStateEncrypted
+		}
+		if data, err = s.cipher.open(data); err != nil {
+			return nil, err
+		}
+	}
+
+	var state SessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file: %w", err)
+	}
+
+	return &state, nil
+}
+
+// Save saves session state to disk
+// The file is written to a temp file and renamed into place, so readers never see partial JSON
+func (s *FileStore) Save(state *SessionState) error {
+	path := s.path(state.SessionID)
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize state: %w", err)
+	}
+	if s.cipher != nil {
+		if data, err = s.cipher.seal(data); err != nil {
+			return fmt.Errorf("failed to encrypt state: %w", err)
+		}
+	}
+
+	unlock, err := platform.LockFile(s.lockPath(state.SessionID), true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tmp, err := os.CreateTemp(s.dir, "claude-session-state-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	_ = os.Chmod(tmpPath, 0644)
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes session state
+func (s *FileStore) Delete(sessionID string) error {
+	path := s.path(sessionID)
+	if !platform.FileExists(path) {
+		return nil
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete state file: %w", err)
+	}
+
+	return nil
+}
+
+// Cleanup cleans up old state files (older than maxAge seconds)
+// Lock files are removed once their state file is gone
+func (s *FileStore) Cleanup(maxAge int64) error {
+	if err := platform.CleanupOldFiles(s.dir, stateFilePattern, maxAge); err != nil {
+		return err
+	}
+	if err := platform.CleanupOldFiles(s.dir, "claude-session-state-*.tmp", maxAge); err != nil {
+		return err
+	}
+
+	locks, err := filepath.Glob(filepath.Join(s.dir, "claude-session-state-*.lock"))
+	if err != nil {
+		return err
+	}
+	for _, lock := range locks {
+		statePath := strings.TrimSuffix(lock, ".lock") + ".json"
+		if !platform.FileExists(statePath) && platform.FileAge(lock) > maxAge {
+			_ = os.Remove(lock)
+		}
+	}
+
+	return nil
+}
+
+// Stats summarizes the state files matched by Cleanup, using file mtimes as update times
+func (s *FileStore) Stats() (StateStats, error) {
+	var stats StateStats
+
+	matches, err := filepath.Glob(filepath.Join(s.dir, stateFilePattern))
+	if err != nil {
+		return stats, err
+	}
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // removed since the glob
+		}
+		stats.add(info.ModTime().Unix(), info.Size())
+	}
+
+	return stats, nil
+}
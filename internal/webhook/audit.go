@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/logging"
+)
+
+// Audit outcomes, one per delivery receipt
+const (
+	AuditDelivered = "delivered" // the destination accepted the notification
+	AuditFailed    = "failed"    // the destination failed after retries
+	AuditRejected  = "rejected"  // dropped by the rate limiter or open circuit breaker before a request was made
+)
+
+// AuditLog appends one JSON line per delivery outcome to a file
+// Safe for concurrent use; each receipt's lines are written with a single append, so hook processes
+// sharing the file don't interleave their records
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// auditEntry is a single line of the audit log
+type auditEntry struct {
+	Timestamp   string `json:"timestamp"` // RFC3339 with nanoseconds, UTC
+	SessionID   string `json:"session_id"`
+	Status      string `json:"status"`
+	Destination string `json:"destination"`
+	Outcome     string `json:"outcome"`
+	RequestID   string `json:"request_id,omitempty"`
+	LatencyMs   int64  `json:"latency_ms"`
+	Error       string `json:"error,omitempty"`
+}
+
+// NewAuditLog creates an audit log appending to path
+// The file is created on first write with owner-only permissions
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Record appends a line per destination outcome of the receipt to the audit log
+// Skipped sends have no outcomes and aren't logged.
+// A failed write is logged rather than returned, auditing never fails a send
+func (a *AuditLog) Record(receipt DeliveryReceipt) {
+	if len(receipt.Destinations) == 0 {
+		return
+	}
+	if err := a.write(receipt); err != nil {
+		logging.Warn("Failed to write webhook audit log %s: %v", a.path, err)
+	}
+}
+
+// write appends the receipt's outcomes as JSON lines
+func (a *AuditLog) write(receipt DeliveryReceipt) error {
+	timestamp := time.Now().UTC().Format(time.RFC3339Nano)
+	var lines []byte
+	for _, outcome := range receipt.Destinations {
+		entry := auditEntry{
+			Timestamp:   timestamp,
+			SessionID:   receipt.SessionID,
+			Status:      string(receipt.Status),
+			Destination: outcome.Destination,
+			Outcome:     auditOutcome(outcome),
+			LatencyMs:   outcome.Latency.Milliseconds(),
+		}
+		if !outcome.Rejected {
+			entry.RequestID = receipt.RequestID
+		}
+		if outcome.Err != nil {
+			entry.Error = outcome.Err.Error()
+		}
+
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to serialize audit entry: %w", err)
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(lines); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// auditOutcome classifies a destination outcome
+func auditOutcome(outcome DestinationOutcome) string {
+	switch {
+	case outcome.Err == nil:
+		return AuditDelivered
+	case outcome.Rejected:
+		return AuditRejected
+	default:
+		return AuditFailed
+	}
+}
@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+)
+
+// readAuditLog returns the decoded lines of an audit log
+func readAuditLog(t *testing.T, path string) []auditEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer f.Close()
+
+	var entries []auditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Audit line is not valid JSON: %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestSenderAuditLog(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) > 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.AuditLog = path
+	sender := New(cfg)
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-1"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := sender.Send(analyzer.StatusQuestion, "Which one?", "session-2"); err == nil {
+		t.Fatal("Expected the second send to fail")
+	}
+
+	entries := readAuditLog(t, path)
+	if len(entries) != 2 {
+		t.Fatalf("Expected one audit line per attempt, got %d", len(entries))
+	}
+
+	first := entries[0]
+	if first.SessionID != "session-1" || first.Status != "task_complete" || first.Destination != "default" || first.Outcome != AuditDelivered {
+		t.Errorf("Unexpected audit entry for the delivered send: %+v", first)
+	}
+	if first.RequestID == "" || first.Timestamp == "" || first.Error != "" {
+		t.Errorf("Expected request ID and timestamp without error, got %+v", first)
+	}
+
+	second := entries[1]
+	if second.SessionID != "session-2" || second.Status != "question" || second.Outcome != AuditFailed || second.Error == "" {
+		t.Errorf("Unexpected audit entry for the failed send: %+v", second)
+	}
+	if second.RequestID == "" || second.RequestID == first.RequestID {
+		t.Errorf("Expected a distinct request ID, got %q", second.RequestID)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Expected audit log permissions 0600, got %o", perm)
+	}
+}
+
+func TestAuditLogConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit := NewAuditLog(path)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			audit.Record(DeliveryReceipt{
+				Status:       analyzer.StatusTaskComplete,
+				SessionID:    "session",
+				Err:          ErrRateLimitExceeded,
+				Destinations: []DestinationOutcome{{Destination: "chat", Rejected: true, Err: ErrRateLimitExceeded}},
+			})
+		}()
+	}
+	wg.Wait()
+
+	entries := readAuditLog(t, path)
+	if len(entries) != 50 {
+		t.Fatalf("Expected 50 audit lines, got %d", len(entries))
+	}
+	if entries[0].Outcome != AuditRejected {
+		t.Errorf("Expected a rejected outcome to be audited as rejected, got %q", entries[0].Outcome)
+	}
+}
+
+func TestSenderAuditLogWriteErrorDoesNotFailSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.AuditLog = filepath.Join(t.TempDir(), "missing", "audit.jsonl")
+
+	if err := New(cfg).Send(analyzer.StatusTaskComplete, "Done", "session-1"); err != nil {
+		t.Errorf("Expected an audit write failure not to fail the send, got %v", err)
+	}
+}
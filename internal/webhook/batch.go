@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+)
+
+// DefaultBatchWindow is how long notifications accumulate when no window is configured
+const DefaultBatchWindow = 5 * time.Second
+
+// DefaultBatchSeparator joins batched messages when no separator is configured
+const DefaultBatchSeparator = "\n\n---\n\n"
+
+// BatchFlushFunc delivers a batch as a single combined notification
+// batched has the status of each notification in the batch, oldest first; spooled has
+// the spool entries of its spooled notifications, to remove once the batch is delivered
+type BatchFlushFunc func(status analyzer.Status, message, sessionID string, details Details, batched []analyzer.Status, spooled []*spoolEntry)
+
+// Batcher coalesces notifications for the same session that arrive within a window
+// The window starts with the first notification; when it closes the messages are
+// joined with the separator and flushed with the status and details of the latest one
+type Batcher struct {
+	window    time.Duration
+	separator string
+	flush     BatchFlushFunc
+
+	mu      sync.Mutex
+	pending map[string]*batch
+}
+
+// batch is the set of notifications waiting for one session
+type batch struct {
+	status   analyzer.Status
+	statuses []analyzer.Status
+	messages []string
+	spooled  []*spoolEntry
+	details  Details
+	timer    *time.Timer
+}
+
+// NewBatcher creates a batcher that calls flush when a session's window closes
+func NewBatcher(window time.Duration, separator string, flush BatchFlushFunc) *Batcher {
+	if window <= 0 {
+		window = DefaultBatchWindow
+	}
+	if separator == "" {
+		separator = DefaultBatchSeparator
+	}
+	return &Batcher{
+		window:    window,
+		separator: separator,
+		flush:     flush,
+		pending:   make(map[string]*batch),
+	}
+}
+
+// Add queues a notification, with its spool entry if it was spooled (nil otherwise)
+// Returns true if it started a new batch, false if it joined an open one
+func (b *Batcher) Add(status analyzer.Status, message, sessionID string, details Details, entry *spoolEntry) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if pending, ok := b.pending[sessionID]; ok {
+		pending.status = status
+		pending.statuses = append(pending.statuses, status)
+		pending.messages = append(pending.messages, message)
+		pending.details = details
+		if entry != nil {
+			pending.spooled = append(pending.spooled, entry)
+		}
+		return false
+	}
+
+	pending := &batch{
+		status:   status,
+		statuses: []analyzer.Status{status},
+		messages: []string{message},
+		details:  details,
+		timer:    time.AfterFunc(b.window, func() { b.flushSession(sessionID) }),
+	}
+	if entry != nil {
+		pending.spooled = []*spoolEntry{entry}
+	}
+	b.pending[sessionID] = pending
+	return true
+}
+
+// FlushAll starts flushing every open batch without waiting for its window, e.g. on shutdown
+// Flushes run in their own goroutines, like window expiry
+func (b *Batcher) FlushAll() {
+	b.mu.Lock()
+	var ready []flushItem
+	for sessionID, pending := range b.pending {
+		// A timer that already fired is flushing this batch itself
+		if pending.timer.Stop() {
+			ready = append(ready, flushItem{sessionID: sessionID, batch: pending})
+			delete(b.pending, sessionID)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, item := range ready {
+		go b.deliver(item.sessionID, item.batch)
+	}
+}
+
+// flushItem is a batch taken out of the pending map for delivery
+type flushItem struct {
+	sessionID string
+	batch     *batch
+}
+
+// flushSession flushes a session's batch when its window closes
+func (b *Batcher) flushSession(sessionID string) {
+	b.mu.Lock()
+	pending, ok := b.pending[sessionID]
+	delete(b.pending, sessionID)
+	b.mu.Unlock()
+
+	if ok {
+		b.deliver(sessionID, pending)
+	}
+}
+
+// deliver joins a batch's messages and hands it to the flush function
+func (b *Batcher) deliver(sessionID string, pending *batch) {
+	b.flush(pending.status, strings.Join(pending.messages, b.separator), sessionID, pending.details, pending.statuses, pending.spooled)
+}
@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestBatcherCoalescesPerSession(t *testing.T) {
+	var mu sync.Mutex
+	flushed := map[string]string{}
+	statuses := map[string]analyzer.Status{}
+	batchedStatuses := map[string][]analyzer.Status{}
+	done := make(chan struct{}, 2)
+
+	b := NewBatcher(50*time.Millisecond, " | ", func(status analyzer.Status, message, sessionID string, details Details, batched []analyzer.Status, spooled []*spoolEntry) {
+		mu.Lock()
+		flushed[sessionID] = message
+		statuses[sessionID] = status
+		batchedStatuses[sessionID] = batched
+		mu.Unlock()
+		done <- struct{}{}
+	})
+
+	if !b.Add(analyzer.StatusTaskComplete, "one", "s1", Details{}, nil) {
+		t.Error("Expected first add to start a batch")
+	}
+	if b.Add(analyzer.StatusReviewComplete, "two", "s1", Details{}, nil) {
+		t.Error("Expected second add to join the open batch")
+	}
+	b.Add(analyzer.StatusQuestion, "other", "s2", Details{}, nil)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Batch was not flushed")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if flushed["s1"] != "one | two" {
+		t.Errorf("Expected joined messages, got %q", flushed["s1"])
+	}
+	if statuses["s1"] != analyzer.StatusReviewComplete {
+		t.Errorf("Expected latest status, got %s", statuses["s1"])
+	}
+	if got := batchedStatuses["s1"]; len(got) != 2 || got[0] != analyzer.StatusTaskComplete || got[1] != analyzer.StatusReviewComplete {
+		t.Errorf("Expected each batched status, oldest first, got %v", got)
+	}
+	if flushed["s2"] != "other" {
+		t.Errorf("Expected separate batch per session, got %q", flushed["s2"])
+	}
+}
+
+func TestBatcherFlushAll(t *testing.T) {
+	done := make(chan string, 1)
+	b := NewBatcher(time.Hour, "", func(status analyzer.Status, message, sessionID string, details Details, batched []analyzer.Status, spooled []*spoolEntry) {
+		done <- message
+	})
+
+	b.Add(analyzer.StatusTaskComplete, "a", "s1", Details{}, nil)
+	b.Add(analyzer.StatusTaskComplete, "b", "s1", Details{}, nil)
+	b.FlushAll()
+
+	select {
+	case msg := <-done:
+		if msg != "a"+DefaultBatchSeparator+"b" {
+			t.Errorf("Expected default separator, got %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("FlushAll did not flush the open batch")
+	}
+}
+
+func TestSenderBatchesWithinWindow(t *testing.T) {
+	requests := atomic.Int32{}
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Batch = config.BatchConfig{Enabled: true, Window: "100ms", Separator: "\n"}
+	callback, receipts := collectReceipts()
+	sender := New(cfg, WithDeliveryCallback(callback))
+
+	for _, msg := range []string{"Subtask 1 done", "Subtask 2 done", "Subtask 3 done"} {
+		if err := sender.Send(analyzer.StatusTaskComplete, msg, "session-123"); err != nil {
+			t.Fatalf("Expected batched send to succeed, got %v", err)
+		}
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no request before the window closes, got %d", requests.Load())
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if err := sender.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if requests.Load() != 1 {
+		t.Fatalf("Expected 1 HTTP request for the batch, got %d", requests.Load())
+	}
+	if msg, _ := body["message"].(string); msg != "Subtask 1 done\nSubtask 2 done\nSubtask 3 done" {
+		t.Errorf("Expected combined message, got %q", msg)
+	}
+
+	// Each batched send gets a receipt for the combined delivery
+	if len(receipts) != 3 {
+		t.Fatalf("Expected a receipt per batched send, got %d", len(receipts))
+	}
+	first := nextReceipt(t, receipts)
+	for i := 0; i < 2; i++ {
+		if r := nextReceipt(t, receipts); r.RequestID != first.RequestID || r.Err != nil || len(r.Destinations) != 1 {
+			t.Errorf("Expected receipts of the same delivery, got %+v and %+v", first, r)
+		}
+	}
+}
+
+func TestSenderBatchFlushRespectsRateLimit(t *testing.T) {
+	requests := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Batch = config.BatchConfig{Enabled: true, Window: "1h"}
+	cfg.Notifications.Webhook.RateLimit = config.RateLimitConfig{Enabled: true, RequestsPerMinute: 1}
+	sender := New(cfg)
+
+	_ = sender.Send(analyzer.StatusTaskComplete, "first", "session-a")
+	_ = sender.Send(analyzer.StatusTaskComplete, "second", "session-b")
+
+	// Shutdown flushes both open batches; only one fits the rate limit
+	if err := sender.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 HTTP request under the rate limit, got %d", requests.Load())
+	}
+	if stats := sender.GetMetrics(); stats.RateLimitedRequests != 1 {
+		t.Errorf("Expected 1 rate limited flush, got %d", stats.RateLimitedRequests)
+	}
+}
+
+func TestSenderBatchSkipsMuted(t *testing.T) {
+	cfg := newTestConfig("https://example.com/webhook")
+	cfg.Notifications.MutedStatuses = []string{"question"}
+	cfg.Notifications.Webhook.Batch = config.BatchConfig{Enabled: true, Window: "1h"}
+	sender := New(cfg)
+
+	_ = sender.Send(analyzer.StatusQuestion, "muted", "session-123")
+
+	sender.batcher.mu.Lock()
+	pending := len(sender.batcher.pending)
+	sender.batcher.mu.Unlock()
+	if pending != 0 {
+		t.Errorf("Expected muted status not to be batched, got %d open batches", pending)
+	}
+}
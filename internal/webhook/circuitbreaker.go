@@ -3,8 +3,11 @@ package webhook
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
+
+	"github.com/777genius/claude-notifications/internal/logging"
 )
 
 // CircuitBreakerState represents the current state of the circuit breaker
@@ -23,17 +26,38 @@ var (
 	ErrCircuitOpen = errors.New("circuit breaker is open")
 )
 
+// transitionHistorySize is how many state transitions RecentTransitions keeps
+const transitionHistorySize = 20
+
+// Transition records a circuit breaker state change
+type Transition struct {
+	At   time.Time
+	From CircuitBreakerState
+	To   CircuitBreakerState
+	Err  error // failure that triggered the change, nil for timeouts and recoveries
+}
+
 // CircuitBreaker implements the circuit breaker pattern
 type CircuitBreaker struct {
-	failureThreshold int
-	successThreshold int
-	timeout          time.Duration
+	failureThreshold  int
+	successThreshold  int
+	timeout           time.Duration
+	maxHalfOpenProbes int           // concurrent calls allowed while half-open, 0 means unlimited
+	maxTimeout        time.Duration // cap for the open timeout as it doubles on repeated trips
+	resetAfter        time.Duration // success streak that ends recovery, 0 ends it on closing
 
 	mu              sync.RWMutex
 	state           CircuitBreakerState
 	failureCount    int
 	successCount    int
+	probesInFlight  int
 	lastStateChange time.Time
+	trips           int       // times opened since failure accounting was last reset
+	streakStart     time.Time // first success since the last failure, zero after a failure
+
+	transitions    [transitionHistorySize]Transition // ring buffer
+	transitionNext int                               // next write position
+	transitionLen  int                               // number of recorded transitions
 }
 
 // NewCircuitBreaker creates a new circuit breaker
@@ -47,6 +71,23 @@ func NewCircuitBreaker(failureThreshold, successThreshold int, timeout time.Dura
 	}
 }
 
+// SetMaxHalfOpenProbes limits how many calls may run concurrently while the circuit is half-open
+// Further calls are rejected with ErrCircuitOpen until a probe finishes. 0 (the default) means unlimited.
+// Must be called before the breaker is used.
+func (cb *CircuitBreaker) SetMaxHalfOpenProbes(n int) {
+	cb.maxHalfOpenProbes = n
+}
+
+// SetBackoff makes the open timeout double on each trip until failure accounting is reset,
+// up to maxTimeout, and keeps failures counted after recovery until calls have succeeded
+// for resetAfter without a failure. A maxTimeout not above the timeout disables the backoff;
+// a zero resetAfter resets everything as soon as the circuit closes.
+// Must be called before the breaker is used.
+func (cb *CircuitBreaker) SetBackoff(maxTimeout, resetAfter time.Duration) {
+	cb.maxTimeout = maxTimeout
+	cb.resetAfter = resetAfter
+}
+
 // Execute runs the function through the circuit breaker
 func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
 	// Check current state
@@ -57,12 +98,20 @@ func (cb *CircuitBreaker) Execute(ctx context.Context, fn func() error) error {
 		return ErrCircuitOpen
 	}
 
+	// While half-open, only a limited number of probes may reach the recovering endpoint
+	if state == StateHalfOpen && cb.maxHalfOpenProbes > 0 {
+		if !cb.acquireProbe() {
+			return ErrCircuitOpen
+		}
+		defer cb.releaseProbe()
+	}
+
 	// Execute the function
 	err := fn()
 
 	// Record result
 	if err != nil {
-		cb.recordFailure()
+		cb.recordFailure(err)
 	} else {
 		cb.recordSuccess()
 	}
@@ -75,17 +124,17 @@ func (cb *CircuitBreaker) getState() CircuitBreakerState {
 	cb.mu.RLock()
 	state := cb.state
 	lastChange := cb.lastStateChange
+	timeout := cb.openTimeout()
 	cb.mu.RUnlock()
 
 	// If we're in Open state and timeout has passed, transition to HalfOpen
-	if state == StateOpen && time.Since(lastChange) >= cb.timeout {
+	if state == StateOpen && time.Since(lastChange) >= timeout {
 		cb.mu.Lock()
 		// Double-check after acquiring write lock
-		if cb.state == StateOpen && time.Since(cb.lastStateChange) >= cb.timeout {
-			cb.state = StateHalfOpen
+		if cb.state == StateOpen && time.Since(cb.lastStateChange) >= cb.openTimeout() {
+			cb.transition(StateHalfOpen, nil)
 			cb.successCount = 0
 			cb.failureCount = 0
-			cb.lastStateChange = time.Now()
 			state = StateHalfOpen
 		}
 		cb.mu.Unlock()
@@ -94,6 +143,55 @@ func (cb *CircuitBreaker) getState() CircuitBreakerState {
 	return state
 }
 
+// openTimeout returns how long the circuit stays open: the timeout doubled for every trip
+// after the first, capped at maxTimeout
+// Must be called with cb.mu held
+func (cb *CircuitBreaker) openTimeout() time.Duration {
+	timeout := cb.timeout
+	if cb.maxTimeout <= timeout {
+		return timeout
+	}
+	for i := 1; i < cb.trips && timeout < cb.maxTimeout; i++ {
+		timeout *= 2
+	}
+	if timeout > cb.maxTimeout {
+		timeout = cb.maxTimeout
+	}
+	return timeout
+}
+
+// recovering reports whether the circuit closed after a trip but hasn't yet seen
+// resetAfter of sustained success
+// Must be called with cb.mu held
+func (cb *CircuitBreaker) recovering() bool {
+	return cb.resetAfter > 0 && cb.trips > 0
+}
+
+// acquireProbe reserves a half-open probe slot, reporting false if all slots are taken
+func (cb *CircuitBreaker) acquireProbe() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == StateOpen {
+		return false
+	}
+	if cb.state == StateHalfOpen && cb.probesInFlight >= cb.maxHalfOpenProbes {
+		return false
+	}
+	cb.probesInFlight++
+	return true
+}
+
+// releaseProbe frees a slot taken by acquireProbe
+func (cb *CircuitBreaker) releaseProbe() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.probesInFlight > 0 {
+		cb.probesInFlight--
+	}
+}
+
 // recordSuccess records a successful call
 func (cb *CircuitBreaker) recordSuccess() {
 	cb.mu.Lock()
@@ -104,41 +202,89 @@ func (cb *CircuitBreaker) recordSuccess() {
 		cb.successCount++
 		if cb.successCount >= cb.successThreshold {
 			// Transition to Closed
-			cb.state = StateClosed
+			cb.transition(StateClosed, nil)
 			cb.failureCount = 0
 			cb.successCount = 0
-			cb.lastStateChange = time.Now()
+			cb.streakStart = time.Now()
+			if cb.resetAfter == 0 {
+				cb.trips = 0
+			}
 		}
 	case StateClosed:
-		// Reset failure count on success
+		if cb.streakStart.IsZero() {
+			cb.streakStart = time.Now()
+		}
+		// While recovering, failures only reset once successes have been sustained
+		if cb.recovering() {
+			if time.Since(cb.streakStart) < cb.resetAfter {
+				return
+			}
+			cb.trips = 0
+			logging.Debug("Circuit breaker recovered, failure accounting reset")
+		}
 		cb.failureCount = 0
 	}
 }
 
 // recordFailure records a failed call
-func (cb *CircuitBreaker) recordFailure() {
+func (cb *CircuitBreaker) recordFailure(err error) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
 	switch cb.state {
 	case StateHalfOpen:
 		// Any failure in HalfOpen immediately goes back to Open
-		cb.state = StateOpen
+		cb.trips++
+		cb.transition(StateOpen, err)
 		cb.failureCount = 0
 		cb.successCount = 0
-		cb.lastStateChange = time.Now()
 
 	case StateClosed:
 		cb.failureCount++
+		cb.streakStart = time.Time{}
 		if cb.failureCount >= cb.failureThreshold {
 			// Transition to Open
-			cb.state = StateOpen
+			cb.trips++
+			cb.transition(StateOpen, err)
 			cb.failureCount = 0
-			cb.lastStateChange = time.Now()
 		}
 	}
 }
 
+// transition changes state, records it in the history and logs it
+// Must be called with cb.mu held for writing
+func (cb *CircuitBreaker) transition(to CircuitBreakerState, err error) {
+	t := Transition{At: time.Now(), From: cb.state, To: to, Err: err}
+
+	cb.state = to
+	cb.lastStateChange = t.At
+
+	cb.transitions[cb.transitionNext] = t
+	cb.transitionNext = (cb.transitionNext + 1) % transitionHistorySize
+	if cb.transitionLen < transitionHistorySize {
+		cb.transitionLen++
+	}
+
+	if err != nil {
+		logging.Warn("Circuit breaker %s -> %s: %v", t.From, t.To, err)
+	} else {
+		logging.Warn("Circuit breaker %s -> %s", t.From, t.To)
+	}
+}
+
+// RecentTransitions returns the most recent state transitions, oldest first
+func (cb *CircuitBreaker) RecentTransitions() []Transition {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+
+	result := make([]Transition, 0, cb.transitionLen)
+	start := (cb.transitionNext - cb.transitionLen + transitionHistorySize) % transitionHistorySize
+	for i := 0; i < cb.transitionLen; i++ {
+		result = append(result, cb.transitions[(start+i)%transitionHistorySize])
+	}
+	return result
+}
+
 // GetState returns the current state (for monitoring/metrics)
 func (cb *CircuitBreaker) GetState() CircuitBreakerState {
 	cb.mu.RLock()
@@ -166,3 +312,19 @@ func (s CircuitBreakerState) String() string {
 		return "unknown"
 	}
 }
+
+// MarshalText encodes the state by name, e.g. "half-open"
+func (s CircuitBreakerState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a state name written by MarshalText
+func (s *CircuitBreakerState) UnmarshalText(text []byte) error {
+	for _, state := range []CircuitBreakerState{StateClosed, StateOpen, StateHalfOpen} {
+		if state.String() == string(text) {
+			*s = state
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown circuit breaker state: %q", text)
+}
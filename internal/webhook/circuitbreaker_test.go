@@ -3,6 +3,8 @@ package webhook
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -292,3 +294,215 @@ func TestCircuitBreakerPartialSuccessInHalfOpen(t *testing.T) {
 		t.Errorf("Expected StateClosed after threshold, got %v", cb.GetState())
 	}
 }
+
+func TestCircuitBreakerRecentTransitions(t *testing.T) {
+	cb := NewCircuitBreaker(2, 1, 20*time.Millisecond)
+
+	if len(cb.RecentTransitions()) != 0 {
+		t.Fatal("Expected no transitions for a new circuit breaker")
+	}
+
+	// Drive failures past the threshold
+	serviceErr := errors.New("service error")
+	for i := 0; i < 2; i++ {
+		_ = cb.Execute(context.Background(), func() error {
+			return serviceErr
+		})
+	}
+
+	// Wait for timeout, then recover
+	time.Sleep(30 * time.Millisecond)
+	if err := cb.Execute(context.Background(), func() error { return nil }); err != nil {
+		t.Fatalf("Unexpected error on recovery: %v", err)
+	}
+
+	transitions := cb.RecentTransitions()
+	expected := []struct{ from, to CircuitBreakerState }{
+		{StateClosed, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateClosed},
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("Expected %d transitions, got %d: %+v", len(expected), len(transitions), transitions)
+	}
+	for i, e := range expected {
+		if transitions[i].From != e.from || transitions[i].To != e.to {
+			t.Errorf("Transition %d: expected %v -> %v, got %v -> %v", i, e.from, e.to, transitions[i].From, transitions[i].To)
+		}
+	}
+
+	if transitions[0].Err != serviceErr {
+		t.Errorf("Expected opening transition to record the triggering error, got %v", transitions[0].Err)
+	}
+	if transitions[2].Err != nil {
+		t.Errorf("Expected no error on recovery, got %v", transitions[2].Err)
+	}
+	if transitions[1].At.Before(transitions[0].At) || transitions[2].At.Before(transitions[1].At) {
+		t.Error("Expected transitions in chronological order")
+	}
+}
+
+func TestCircuitBreakerRecentTransitionsBounded(t *testing.T) {
+	cb := NewCircuitBreaker(1, 1, 0)
+
+	// Each failure opens the circuit, each timeout-expired check half-opens it again
+	for i := 0; i < transitionHistorySize; i++ {
+		_ = cb.Execute(context.Background(), func() error {
+			return errors.New("service error")
+		})
+	}
+
+	transitions := cb.RecentTransitions()
+	if len(transitions) != transitionHistorySize {
+		t.Fatalf("Expected history capped at %d, got %d", transitionHistorySize, len(transitions))
+	}
+
+	// The newest entry is the last one returned
+	if last := transitions[len(transitions)-1]; last.To != StateOpen {
+		t.Errorf("Expected last transition to open the circuit, got %v -> %v", last.From, last.To)
+	}
+}
+
+func TestCircuitBreakerHalfOpenProbeLimit(t *testing.T) {
+	cb := NewCircuitBreaker(1, 2, 20*time.Millisecond)
+	cb.SetMaxHalfOpenProbes(1)
+
+	_ = cb.Execute(context.Background(), func() error { return errors.New("service error") })
+	time.Sleep(30 * time.Millisecond)
+
+	// Hold the single probe open while other calls arrive
+	probeStarted := make(chan struct{})
+	releaseProbe := make(chan struct{})
+	probeDone := make(chan error, 1)
+	go func() {
+		probeDone <- cb.Execute(context.Background(), func() error {
+			close(probeStarted)
+			<-releaseProbe
+			return nil
+		})
+	}()
+	<-probeStarted
+
+	var wg sync.WaitGroup
+	var executed atomic.Int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := cb.Execute(context.Background(), func() error {
+				executed.Add(1)
+				return nil
+			})
+			if !errors.Is(err, ErrCircuitOpen) {
+				t.Errorf("Expected ErrCircuitOpen while the probe is in flight, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if executed.Load() != 0 {
+		t.Errorf("Expected no calls besides the probe, got %d", executed.Load())
+	}
+
+	close(releaseProbe)
+	if err := <-probeDone; err != nil {
+		t.Fatalf("Expected probe to succeed, got %v", err)
+	}
+
+	// Once the probe finishes, the next probe is let through
+	if err := cb.Execute(context.Background(), func() error { return nil }); err != nil {
+		t.Errorf("Expected the next probe to run, got %v", err)
+	}
+	if cb.GetState() != StateClosed {
+		t.Errorf("Expected circuit to close after 2 successful probes, got %v", cb.GetState())
+	}
+}
+
+func TestCircuitBreakerOpenTimeoutBackoff(t *testing.T) {
+	cb := NewCircuitBreaker(1, 1, 40*time.Millisecond)
+	cb.SetBackoff(160*time.Millisecond, 0)
+	fail := func() error { return errors.New("service error") }
+
+	_ = cb.Execute(context.Background(), fail)
+	time.Sleep(50 * time.Millisecond)
+
+	// The failed probe reopens the circuit for twice as long
+	_ = cb.Execute(context.Background(), fail)
+	time.Sleep(50 * time.Millisecond)
+	if err := cb.Execute(context.Background(), func() error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected the doubled timeout to keep the circuit open, got %v", err)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if err := cb.Execute(context.Background(), func() error { return nil }); err != nil {
+		t.Fatalf("Expected the probe after the doubled timeout to pass, got %v", err)
+	}
+	if cb.GetState() != StateClosed {
+		t.Fatalf("Expected StateClosed, got %v", cb.GetState())
+	}
+
+	// Without resetAfter, closing resets the backoff
+	_ = cb.Execute(context.Background(), fail)
+	time.Sleep(50 * time.Millisecond)
+	if cb.getState() != StateHalfOpen {
+		t.Errorf("Expected the base timeout after recovery, got %v", cb.GetState())
+	}
+}
+
+// tripAndRecover opens the circuit with failures and closes it again with a probe
+func tripAndRecover(t *testing.T, cb *CircuitBreaker, failures int, timeout time.Duration) {
+	t.Helper()
+	for i := 0; i < failures; i++ {
+		_ = cb.Execute(context.Background(), func() error { return errors.New("service error") })
+	}
+	time.Sleep(timeout + 10*time.Millisecond)
+	_ = cb.Execute(context.Background(), func() error { return nil })
+	if cb.GetState() != StateClosed {
+		t.Fatalf("Expected StateClosed after recovery, got %v", cb.GetState())
+	}
+}
+
+func TestCircuitBreakerRecoveryKeepsFailures(t *testing.T) {
+	cb := NewCircuitBreaker(2, 1, 20*time.Millisecond)
+	cb.SetBackoff(0, time.Hour)
+	tripAndRecover(t, cb, 2, 20*time.Millisecond)
+
+	// A single success doesn't clear failures while recovering
+	_ = cb.Execute(context.Background(), func() error { return errors.New("service error") })
+	_ = cb.Execute(context.Background(), func() error { return nil })
+	_ = cb.Execute(context.Background(), func() error { return errors.New("service error") })
+
+	if cb.GetState() != StateOpen {
+		t.Errorf("Expected interleaved failures to reopen a recovering circuit, got %v", cb.GetState())
+	}
+}
+
+func TestCircuitBreakerSuccessStreakResetsFailures(t *testing.T) {
+	cb := NewCircuitBreaker(2, 1, 20*time.Millisecond)
+	cb.SetBackoff(0, 50*time.Millisecond)
+	tripAndRecover(t, cb, 2, 20*time.Millisecond)
+
+	_ = cb.Execute(context.Background(), func() error { return errors.New("service error") })
+
+	// Succeed for longer than resetAfter
+	deadline := time.Now().Add(60 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if err := cb.Execute(context.Background(), func() error { return nil }); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, failures, _ := cb.GetStats(); failures != 0 {
+		t.Fatalf("Expected the success streak to clear failures, got %d", failures)
+	}
+
+	_ = cb.Execute(context.Background(), func() error { return errors.New("service error") })
+	if cb.GetState() != StateClosed {
+		t.Errorf("Expected a single failure after the streak to keep the circuit closed, got %v", cb.GetState())
+	}
+	_ = cb.Execute(context.Background(), func() error { return nil })
+	_ = cb.Execute(context.Background(), func() error { return errors.New("service error") })
+	if cb.GetState() != StateClosed {
+		t.Errorf("Expected consecutive failure counting once recovered, got %v", cb.GetState())
+	}
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"bytes"
+	"compress/gzip"
+	"mime"
+	"strings"
+
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// defaultCompressionMinBytes is the smallest body compressed when no threshold is configured
+const defaultCompressionMinBytes = 1024
+
+// shouldCompress reports whether a body of size bytes and contentType is gzipped under cfg
+// Only JSON bodies are compressed; form bodies and other types are sent as-is
+func shouldCompress(cfg config.CompressionConfig, contentType string, size int) bool {
+	if !cfg.Enabled {
+		return false
+	}
+	minBytes := cfg.MinBytes
+	if minBytes == 0 {
+		minBytes = defaultCompressionMinBytes
+	}
+	if size < minBytes {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// gzipPayload compresses payload with gzip
+func gzipPayload(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestSenderGzipCompression(t *testing.T) {
+	var encoding string
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		var reader io.Reader = r.Body
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("Expected a valid gzip body: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			reader = zr
+		}
+		body = nil
+		if err := json.NewDecoder(reader).Decode(&body); err != nil {
+			t.Errorf("Expected JSON payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Compression = config.CompressionConfig{Enabled: true, MinBytes: 512}
+	sender := New(cfg)
+
+	large := strings.Repeat("A long transcript line. ", 100)
+	if err := sender.Send(analyzer.StatusTaskComplete, large, "session-1"); err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if encoding != "gzip" {
+		t.Errorf("Expected Content-Encoding gzip, got %q", encoding)
+	}
+	if body["message"] != large {
+		t.Errorf("Expected decompressed payload to carry the message, got %v", body["message"])
+	}
+
+	// Bodies below the threshold are sent as-is
+	if err := sender.Send(analyzer.StatusTaskComplete, "short", "session-1"); err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if encoding != "" || body["message"] != "short" {
+		t.Errorf("Expected small payload uncompressed, got encoding %q and message %v", encoding, body["message"])
+	}
+}
+
+func TestShouldCompress(t *testing.T) {
+	enabled := config.CompressionConfig{Enabled: true}
+
+	tests := []struct {
+		name        string
+		cfg         config.CompressionConfig
+		contentType string
+		size        int
+		want        bool
+	}{
+		{"disabled by default", config.CompressionConfig{}, "application/json", 1 << 20, false},
+		{"json above default threshold", enabled, "application/json", 2048, true},
+		{"json below default threshold", enabled, "application/json", 512, false},
+		{"json with charset", enabled, "application/json; charset=utf-8", 2048, true},
+		{"structured json suffix", enabled, "application/vnd.api+json", 2048, true},
+		{"form body", enabled, "application/x-www-form-urlencoded", 2048, false},
+		{"plain text", enabled, "text/plain", 2048, false},
+		{"custom threshold", config.CompressionConfig{Enabled: true, MinBytes: 10}, "application/json", 10, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldCompress(tt.cfg, tt.contentType, tt.size); got != tt.want {
+				t.Errorf("shouldCompress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// ConsoleFormatter formats notifications as a single line for the console preset
+// e.g. "[task_complete] ✅ Task Completed: Done (Session: abc123)"
+type ConsoleFormatter struct{}
+
+func (f *ConsoleFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	line := fmt.Sprintf("[%s] %s %s: %s", status, getEmojiForStatus(status, statusInfo), statusInfo.Title, message)
+	if footer := sessionFooter(sessionID, details); footer != "" {
+		line += fmt.Sprintf(" (%s)", footer)
+	}
+	return line, nil
+}
+
+// isConsole reports whether the destination prints to stdout instead of sending a request
+func (d destination) isConsole() bool {
+	return d.Preset == "console"
+}
+
+// writeConsole prints the notification line to stdout, for local development without an endpoint
+func (s *Sender) writeConsole(dest destination, status analyzer.Status, message, sessionID string, details Details) error {
+	line, _, err := s.buildPayload(dest, status, message, sessionID, details)
+	if err != nil {
+		return fmt.Errorf("failed to build payload: %w", err)
+	}
+	if _, err := fmt.Fprintln(os.Stdout, string(line)); err != nil {
+		return fmt.Errorf("failed to write to console: %w", err)
+	}
+	return nil
+}
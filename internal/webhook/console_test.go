@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+)
+
+// captureStdout returns what fn writes to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read captured stdout: %v", err)
+	}
+	return string(out)
+}
+
+func TestSenderConsolePreset(t *testing.T) {
+	cfg := newTestConfig("")
+	cfg.Notifications.Webhook.Preset = "console"
+
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+
+	var sendErr error
+	out := captureStdout(t, func() {
+		sendErr = sender.Send(analyzer.StatusTaskComplete, "Refactored the parser", "session-123")
+	})
+	if sendErr != nil {
+		t.Fatalf("Send failed: %v", sendErr)
+	}
+
+	if !strings.Contains(out, "[task_complete]") || !strings.Contains(out, "Task Complete") {
+		t.Errorf("Expected the status in console output, got %q", out)
+	}
+	if !strings.Contains(out, "Refactored the parser") {
+		t.Errorf("Expected the message in console output, got %q", out)
+	}
+	if !strings.Contains(out, "Session: session-123") {
+		t.Errorf("Expected the session footer in console output, got %q", out)
+	}
+
+	if got := sender.GetMetrics().SuccessfulRequests; got != 1 {
+		t.Errorf("Expected the console write to count as a success, got %d", got)
+	}
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/google/uuid"
+)
+
+// DeleteLast removes the session's last notification using the message ID recorded via
+// messageRefPath (see WithMessageRefStore), e.g. Telegram deleteMessage or Discord DELETE,
+// and forgets the ID so a later Update posts a new message.
+// Without a recorded ID, or for a destination that can't delete, it does nothing.
+func (s *Sender) DeleteLast(sessionID string) error {
+	if !s.cfg.IsWebhookEnabled() {
+		logging.Debug("Webhooks disabled, skipping")
+		return nil
+	}
+
+	if s.initErr != nil {
+		return s.initErr
+	}
+
+	dest, ref, ok := s.lastMessage(sessionID)
+	if !ok {
+		logging.Debug("No recorded message for session %s, nothing to delete", sessionID)
+		return nil
+	}
+	deleter, ok := dest.formatter.(DeleteFormatter)
+	if !ok {
+		logging.Debug("Destination %s can't delete messages, leaving %s", dest.Name, ref)
+		return nil
+	}
+
+	requestID := uuid.New().String()
+	if err := s.deleteMessage(requestID, dest, deleter, ref, sessionID); err != nil {
+		return fmt.Errorf("failed to delete message %s on %s: %w", ref, dest.Name, err)
+	}
+
+	if err := s.messageRefs.SetLastMessageRef(sessionID, "", ""); err != nil {
+		logging.Warn("Failed to clear message ID for session %s: %v", sessionID, err)
+	}
+	return nil
+}
+
+// deleteMessage sends the delete request for message ref with retries
+func (s *Sender) deleteMessage(requestID string, dest destination, deleter DeleteFormatter, ref, sessionID string) error {
+	method, deleteURL, body, err := deleter.DeleteRequest(dest.URL, ref)
+	if err != nil {
+		return fmt.Errorf("failed to build delete request: %w", err)
+	}
+	if err := validateURL(deleteURL); err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+
+	err = s.retry.Do(s.ctx, func(ctx context.Context) error {
+		_, err := s.sendRequest(ctx, requestID, sessionID, dest, method, deleteURL, body, "application/json")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.Info("[%s] Deleted message %s on %s", requestID, ref, dest.Name)
+	return nil
+}
@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/state"
+)
+
+func TestSenderDeleteLastTelegram(t *testing.T) {
+	endpoint := &fakeEditEndpoint{respond: func(r *http.Request) (int, string) {
+		if r.URL.Path == "/bot123/deleteMessage" {
+			return http.StatusOK, `{"ok": true, "result": true}`
+		}
+		return http.StatusOK, `{"ok": true, "result": {"message_id": 42}}`
+	}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	store := state.NewManagerWithStore(state.NewMemoryStore())
+	sender := newTelegramUpdateSender(server.URL, store)
+
+	if err := sender.Send(analyzer.StatusQuestion, "Which one?", "session-1"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := sender.DeleteLast("session-1"); err != nil {
+		t.Fatalf("DeleteLast failed: %v", err)
+	}
+
+	if endpoint.count() != 2 {
+		t.Fatalf("Expected a send and a delete, got %d requests", endpoint.count())
+	}
+	del := endpoint.request(1)
+	if del.method != http.MethodPost || del.path != "/bot123/deleteMessage" {
+		t.Errorf("Expected POST /bot123/deleteMessage, got %s %s", del.method, del.path)
+	}
+	if del.body["message_id"] != float64(42) || del.body["chat_id"] != "1" {
+		t.Errorf("Expected chat_id 1 and message_id 42, got %v", del.body)
+	}
+
+	// The ID is forgotten, so a second call has nothing to delete
+	if _, ref, _ := store.LastMessageRef("session-1"); ref != "" {
+		t.Errorf("Expected the message ID to be cleared, got %q", ref)
+	}
+	if err := sender.DeleteLast("session-1"); err != nil {
+		t.Fatalf("DeleteLast failed: %v", err)
+	}
+	if endpoint.count() != 2 {
+		t.Errorf("Expected no request without a recorded message, got %d requests", endpoint.count())
+	}
+}
+
+func TestSenderDeleteLastDiscord(t *testing.T) {
+	endpoint := &fakeEditEndpoint{respond: func(r *http.Request) (int, string) {
+		if r.Method == http.MethodDelete {
+			return http.StatusNoContent, ""
+		}
+		return http.StatusOK, `{"id": "1234567890123456789"}`
+	}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	store := state.NewManagerWithStore(state.NewMemoryStore())
+	cfg := newTestConfig(server.URL + "/api/webhooks/1/abc?wait=true")
+	cfg.Notifications.Webhook.Preset = "discord"
+	cfg.Notifications.Webhook.MessageRefPath = "id"
+	sender := New(cfg, WithMessageRefStore(store))
+
+	if err := sender.Send(analyzer.StatusQuestion, "Which one?", "session-1"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := sender.DeleteLast("session-1"); err != nil {
+		t.Fatalf("DeleteLast failed: %v", err)
+	}
+
+	del := endpoint.request(1)
+	if del.method != http.MethodDelete || del.path != "/api/webhooks/1/abc/messages/1234567890123456789" || del.query != "" {
+		t.Errorf("Expected DELETE of the stored message, got %s %s?%s", del.method, del.path, del.query)
+	}
+}
+
+func TestSenderDeleteLastUnsupported(t *testing.T) {
+	api := &fakeSlackAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	store := state.NewManagerWithStore(state.NewMemoryStore())
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "slack"
+	cfg.Notifications.Webhook.MessageRefPath = "ts"
+	sender := New(cfg, WithMessageRefStore(store))
+
+	if err := sender.Send(analyzer.StatusQuestion, "Which one?", "session-1"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := sender.DeleteLast("session-1"); err != nil {
+		t.Fatalf("Expected DeleteLast to do nothing for Slack, got %v", err)
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.payloads) != 1 {
+		t.Errorf("Expected no delete request for Slack, got %d requests", len(api.payloads))
+	}
+}
+
+func TestMatrixDeleteRequest(t *testing.T) {
+	f := &MatrixFormatter{}
+	method, deleteURL, _, err := f.DeleteRequest("https://matrix.example.org/_matrix/client/r0/rooms/%21abc%3Aexample.org/send/m.room.message?access_token=x", "$event:example.org")
+	if err != nil {
+		t.Fatalf("DeleteRequest failed: %v", err)
+	}
+	if method != http.MethodPut {
+		t.Errorf("Expected PUT, got %s", method)
+	}
+	prefix := "https://matrix.example.org/_matrix/client/r0/rooms/%21abc%3Aexample.org/redact/$event:example.org/"
+	if !strings.HasPrefix(deleteURL, prefix) || !strings.HasSuffix(deleteURL, "?access_token=x") {
+		t.Errorf("Expected a redaction in the same room, got %s", deleteURL)
+	}
+
+	if _, _, _, err := f.DeleteRequest("https://example.com/hook", "$event"); err == nil {
+		t.Error("Expected an error for a URL that isn't a room send endpoint")
+	}
+}
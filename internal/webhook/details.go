@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/platform"
+)
+
+// Details carries optional context about where a notification originated
+type Details struct {
+	CWD       string    // working directory of the Claude session
+	Project   string    // project name derived from CWD, see platform.GetProjectName
+	GitBranch string    // empty when CWD is not inside a git repository
+	GitCommit string    // short commit hash
+	ThreadID  string    // thread to reply in, set per destination (see WithThreadStore)
+	EventTime time.Time // when the triggering event happened, zero if unknown (see maxEventAge)
+	Priority  Priority  // overrides the status's configured or built-in priority when set
+
+	footer *string // rendered footerTemplate, nil for the default footer
+}
+
+// resolveDetails fills in the project name and git information from CWD when not already set
+func resolveDetails(details Details) Details {
+	if details.Project == "" {
+		details.Project = platform.GetProjectName(details.CWD)
+	}
+	if details.GitBranch == "" {
+		details.GitBranch = platform.GetGitBranch(details.CWD)
+	}
+	if details.GitCommit == "" {
+		details.GitCommit = platform.GetGitCommit(details.CWD)
+	}
+	return details
+}
+
+// customFooter returns the rendered footerTemplate, and false when the default footer applies
+// An empty footer means the formatter should leave its footer out
+func (d Details) customFooter() (string, bool) {
+	if d.footer == nil {
+		return "", false
+	}
+	return *d.footer, true
+}
+
+// appendFooter adds the footer to text after a blank line, unless the footer is empty
+func appendFooter(text, footer string) string {
+	if footer == "" {
+		return text
+	}
+	return text + "\n\n" + footer
+}
+
+// gitLabel returns "branch (commit)", just the branch, or empty string without git info
+func (d Details) gitLabel() string {
+	if d.GitBranch == "" {
+		return ""
+	}
+	if d.GitCommit == "" {
+		return d.GitBranch
+	}
+	return fmt.Sprintf("%s (%s)", d.GitBranch, d.GitCommit)
+}
+
+// sessionFooter returns the session footer line, with the project and git branch appended when known
+// A configured footerTemplate replaces it
+func sessionFooter(sessionID string, details Details) string {
+	if footer, ok := details.customFooter(); ok {
+		return footer
+	}
+	footer := fmt.Sprintf("Session: %s", sessionID)
+	if details.Project != "" {
+		footer += fmt.Sprintf(" | Project: %s", details.Project)
+	}
+	if label := details.gitLabel(); label != "" {
+		footer += fmt.Sprintf(" | Branch: %s", label)
+	}
+	return footer
+}
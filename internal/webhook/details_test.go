@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+)
+
+// newGitRepo creates a temporary git repository with one commit on branch
+func newGitRepo(t *testing.T, branch string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"commit", "--allow-empty", "-m", "init"},
+		{"checkout", "-b", branch},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestResolveDetails(t *testing.T) {
+	repo := newGitRepo(t, "feature/login")
+	details := resolveDetails(Details{CWD: repo})
+	if details.GitBranch != "feature/login" {
+		t.Errorf("Expected branch 'feature/login', got %q", details.GitBranch)
+	}
+	if details.GitCommit == "" {
+		t.Error("Expected short commit hash")
+	}
+	if details.Project != filepath.Base(repo) {
+		t.Errorf("Expected project %q, got %q", filepath.Base(repo), details.Project)
+	}
+
+	dir := t.TempDir()
+	details = resolveDetails(Details{CWD: dir})
+	if details.GitBranch != "" || details.GitCommit != "" {
+		t.Errorf("Expected no git info outside a repository, got %+v", details)
+	}
+	if details.Project != filepath.Base(dir) {
+		t.Errorf("Expected project %q outside a repository, got %q", filepath.Base(dir), details.Project)
+	}
+
+	// A project name already known to the caller is kept
+	details = resolveDetails(Details{CWD: dir, Project: "stored"})
+	if details.Project != "stored" {
+		t.Errorf("Expected caller's project to be kept, got %q", details.Project)
+	}
+}
+
+func TestSessionFooter(t *testing.T) {
+	tests := []struct {
+		details  Details
+		expected string
+	}{
+		{Details{}, "Session: session-123"},
+		{Details{Project: "my-app"}, "Session: session-123 | Project: my-app"},
+		{Details{GitBranch: "main"}, "Session: session-123 | Branch: main"},
+		{Details{Project: "my-app", GitBranch: "main", GitCommit: "abc1234"}, "Session: session-123 | Project: my-app | Branch: main (abc1234)"},
+	}
+
+	for _, tt := range tests {
+		if got := sessionFooter("session-123", tt.details); got != tt.expected {
+			t.Errorf("sessionFooter(%+v) = %q, want %q", tt.details, got, tt.expected)
+		}
+	}
+}
+
+func TestDetailsGitLabel(t *testing.T) {
+	tests := []struct {
+		details  Details
+		expected string
+	}{
+		{Details{}, ""},
+		{Details{GitCommit: "abc1234"}, ""},
+		{Details{GitBranch: "main"}, "main"},
+		{Details{GitBranch: "main", GitCommit: "abc1234"}, "main (abc1234)"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.details.gitLabel(); got != tt.expected {
+			t.Errorf("gitLabel(%+v) = %q, want %q", tt.details, got, tt.expected)
+		}
+	}
+}
+
+func TestSenderSendWithDetailsGitBranch(t *testing.T) {
+	var received map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := New(newTestConfig(server.URL))
+
+	repo := newGitRepo(t, "feature/login")
+	if err := sender.SendWithDetails(analyzer.StatusTaskComplete, "Done", "session-123", Details{CWD: repo}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if received["git_branch"] != "feature/login" {
+		t.Errorf("Expected git_branch 'feature/login', got %v", received["git_branch"])
+	}
+	if commit, _ := received["git_commit"].(string); commit == "" {
+		t.Error("Expected git_commit in payload")
+	}
+
+	if err := sender.SendWithDetails(analyzer.StatusTaskComplete, "Done", "session-123", Details{CWD: t.TempDir()}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if _, ok := received["git_branch"]; ok {
+		t.Errorf("Expected no git_branch outside a repository, got %v", received["git_branch"])
+	}
+	if _, ok := received["git_commit"]; ok {
+		t.Errorf("Expected no git_commit outside a repository, got %v", received["git_commit"])
+	}
+}
@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestSenderFallbackDelivers(t *testing.T) {
+	var primaryHits atomic.Int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		primaryHits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+
+	var fallbackBody map[string]interface{}
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&fallbackBody)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fallback.Close()
+
+	var unusedHits atomic.Int32
+	unused := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		unusedHits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer unused.Close()
+
+	cfg := newTestConfig(primary.URL)
+	cfg.Notifications.Webhook.Preset = "slack"
+	cfg.Notifications.Webhook.Fallbacks = []config.WebhookDestination{
+		{Name: "broken", Preset: "custom", Format: "json", URL: "ftp://invalid"},
+		{Name: "telegram", Preset: "telegram", ChatID: "123", URL: fallback.URL},
+		{Name: "spare", Preset: "custom", Format: "json", URL: unused.URL},
+	}
+	sender := New(cfg)
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-1"); err != nil {
+		t.Fatalf("Expected fallback delivery to succeed, got %v", err)
+	}
+
+	if primaryHits.Load() != 3 {
+		t.Errorf("Expected the primary to be retried 3 times, got %d", primaryHits.Load())
+	}
+	if fallbackBody["chat_id"] != "123" {
+		t.Errorf("Expected the Telegram fallback to receive its own payload, got %v", fallbackBody)
+	}
+	if unusedHits.Load() != 0 {
+		t.Errorf("Expected fallbacks after the first success to be skipped, got %d requests", unusedHits.Load())
+	}
+
+	stats := sender.GetMetrics()
+	if stats.FallbackDeliveries != 1 {
+		t.Errorf("Expected 1 fallback delivery, got %d", stats.FallbackDeliveries)
+	}
+	if stats.DestinationStats["default"].FailedRequests != 1 || stats.DestinationStats["telegram"].SuccessfulRequests != 1 {
+		t.Errorf("Expected primary failure and fallback success per destination, got %+v", stats.DestinationStats)
+	}
+}
+
+func TestSenderFallbackOnOpenCircuit(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer primary.Close()
+
+	var fallbackHits atomic.Int32
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbackHits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fallback.Close()
+
+	cfg := newTestConfig(primary.URL)
+	cfg.Notifications.Webhook.Retry.Enabled = false
+	cfg.Notifications.Webhook.CircuitBreaker.FailureThreshold = 1
+	cfg.Notifications.Webhook.CircuitBreaker.Timeout = "1h"
+	cfg.Notifications.Webhook.Fallbacks = []config.WebhookDestination{
+		{Name: "backup", Preset: "custom", Format: "json", URL: fallback.URL},
+	}
+	sender := New(cfg)
+
+	// The first failure opens the breaker; both sends still reach the fallback
+	for i := 0; i < 2; i++ {
+		if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-1"); err != nil {
+			t.Fatalf("Send %d: expected fallback delivery, got %v", i, err)
+		}
+	}
+	if state := sender.destinations[0].breaker.GetState(); state != StateOpen {
+		t.Fatalf("Expected open circuit, got %v", state)
+	}
+	if fallbackHits.Load() != 2 {
+		t.Errorf("Expected 2 fallback deliveries, got %d", fallbackHits.Load())
+	}
+	if stats := sender.GetMetrics(); stats.CircuitOpenRequests != 1 {
+		t.Errorf("Expected 1 circuit-open rejection, got %d", stats.CircuitOpenRequests)
+	}
+}
+
+func TestSenderFallbacksExhausted(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+
+	cfg := newTestConfig(failing.URL)
+	cfg.Notifications.Webhook.Fallbacks = []config.WebhookDestination{
+		{Name: "backup", Preset: "custom", Format: "json", URL: failing.URL},
+	}
+	err := New(cfg).Send(analyzer.StatusTaskComplete, "Done", "session-1")
+
+	var destErr *DestinationError
+	if !errors.As(err, &destErr) {
+		t.Fatalf("Expected destination errors, got %v", err)
+	}
+	for _, name := range []string{"destination default", "destination backup"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected error to mention %q, got %v", name, err)
+		}
+	}
+}
+
+func TestSenderFallbackRespectsRateLimit(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+
+	var fallbackHits atomic.Int32
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fallbackHits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fallback.Close()
+
+	cfg := newTestConfig("")
+	cfg.Notifications.Webhook.RateLimit = config.RateLimitConfig{Enabled: true, RequestsPerMinute: 1}
+	cfg.Notifications.Webhook.Destinations = []config.WebhookDestination{
+		{Name: "a", URL: failing.URL, Format: "json"},
+		{Name: "b", URL: failing.URL, Format: "json"},
+	}
+	cfg.Notifications.Webhook.Routes = map[string]string{"question": "a", "task_complete": "b"}
+	cfg.Notifications.Webhook.Fallbacks = []config.WebhookDestination{
+		{Name: "backup", Preset: "custom", Format: "json", URL: fallback.URL},
+	}
+	sender := New(cfg)
+
+	// Each send has its own primary with capacity left, but they share the fallback's single token
+	if err := sender.Send(analyzer.StatusQuestion, "Which one?", "session-1"); err != nil {
+		t.Fatalf("Expected the first send to reach the fallback, got %v", err)
+	}
+	err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-1")
+	if !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("Expected the rate-limited fallback to be reported, got %v", err)
+	}
+
+	if fallbackHits.Load() != 1 {
+		t.Errorf("Expected the fallback's rate limit to stop the second request, got %d requests", fallbackHits.Load())
+	}
+	if stats := sender.GetMetrics(); stats.RateLimitedRequests != 1 || stats.FallbackDeliveries != 1 {
+		t.Errorf("Expected 1 rate limited fallback and 1 fallback delivery, got %d and %d", stats.RateLimitedRequests, stats.FallbackDeliveries)
+	}
+}
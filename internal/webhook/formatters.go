@@ -1,59 +1,345 @@
 package webhook
 
 import (
+	"encoding/json"
 	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/777genius/claude-notifications/internal/analyzer"
 	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/google/uuid"
 )
 
 // Formatter interface for different webhook formats
 type Formatter interface {
-	Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error)
+	Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error)
+}
+
+// HeaderFormatter is implemented by formatters whose service expects extra request headers
+// Custom headers from config are applied afterwards and take precedence
+type HeaderFormatter interface {
+	Headers() map[string]string
+}
+
+// BodyFormatter is implemented by formatters whose service doesn't take JSON bodies
+// EncodeBody turns the formatted payload into the request body and its content type;
+// other formatters' payloads are sent as JSON, or as text/plain when Format returns a string
+type BodyFormatter interface {
+	EncodeBody(payload interface{}) (body []byte, contentType string, err error)
+}
+
+// contentTypeForm is the content type of URL-encoded form bodies
+const contentTypeForm = "application/x-www-form-urlencoded"
+
+// encodeForm encodes a map payload as an application/x-www-form-urlencoded body
+// Values are written with fmt.Sprint, so formatters should use strings and numbers
+func encodeForm(payload interface{}) ([]byte, string, error) {
+	fields, ok := payload.(map[string]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("form body needs a map payload, got %T", payload)
+	}
+	values := url.Values{}
+	for key, value := range fields {
+		values.Set(key, fmt.Sprint(value))
+	}
+	return []byte(values.Encode()), contentTypeForm, nil
+}
+
+// EditFormatter is implemented by formatters whose service can edit a message it posted
+// EditRequest turns a formatted payload into the request replacing message ref, given the URL it was posted to
+type EditFormatter interface {
+	EditRequest(webhookURL, ref string, payload []byte) (method, editURL string, body []byte, err error)
+}
+
+// DeleteFormatter is implemented by formatters whose service can delete a message it posted
+// DeleteRequest returns the request removing message ref, given the URL it was posted to; body may be nil
+type DeleteFormatter interface {
+	DeleteRequest(webhookURL, ref string) (method, deleteURL string, body []byte, err error)
 }
 
 // SlackFormatter formats messages for Slack
-type SlackFormatter struct{}
+// By default it uses legacy attachments; Blocks switches to the Block Kit layout
+type SlackFormatter struct {
+	Blocks          bool
+	Mention         string   // user (U...) or user group (S...) ID mentioned for MentionStatuses
+	MentionStatuses []string // default: question
+	Channel         string   // channel ID for the chat.postMessage API; incoming webhooks post to a fixed channel
+}
 
-func (f *SlackFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
-	color := getColorForStatus(status)
+// newSlackFormatter builds the Slack formatter for a destination
+// Threaded destinations post through chat.postMessage, which needs the channel in the payload
+func newSlackFormatter(dest config.WebhookDestination) *SlackFormatter {
+	f := &SlackFormatter{Blocks: dest.SlackBlocks, Mention: dest.Mention, MentionStatuses: dest.MentionStatuses}
+	if dest.SlackThreads {
+		f.Channel = dest.ChatID
+	}
+	return f
+}
 
-	return map[string]interface{}{
-		"attachments": []map[string]interface{}{
-			{
-				"color":       color,
-				"title":       statusInfo.Title,
-				"text":        message,
-				"footer":      fmt.Sprintf("Session: %s | Claude Notifications", sessionID),
-				"footer_icon": "https://claude.ai/favicon.ico",
-				"ts":          time.Now().Unix(),
-				"mrkdwn_in":   []string{"text"},
-			},
+// Slack Block Kit text limits
+const (
+	slackHeaderLimit  = 150
+	slackSectionLimit = 3000
+)
+
+func (f *SlackFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	mention := ""
+	if f.Mention != "" && shouldMention(status, f.MentionStatuses) {
+		mention = slackMention(f.Mention)
+	}
+
+	if f.Blocks {
+		return f.addThreading(f.formatBlocks(status, message, sessionID, statusInfo, details, mention), details), nil
+	}
+
+	color := getColorForStatus(status, statusInfo)
+
+	attachment := map[string]interface{}{
+		"color":       color,
+		"title":       statusInfo.Title,
+		"text":        markdownToSlack(message),
+		"footer":      fmt.Sprintf("Session: %s | Claude Notifications", sessionID),
+		"footer_icon": "https://claude.ai/favicon.ico",
+		"ts":          time.Now().Unix(),
+		"mrkdwn_in":   []string{"text"},
+	}
+	if footer, ok := details.customFooter(); ok {
+		// The footer template replaces the session footer and the project and branch fields
+		attachment["footer"] = footer
+		if footer == "" {
+			delete(attachment, "footer")
+			delete(attachment, "footer_icon")
+		}
+	} else {
+		var fields []map[string]interface{}
+		if details.Project != "" {
+			fields = append(fields, map[string]interface{}{"title": "Project", "value": details.Project, "short": true})
+		}
+		if label := details.gitLabel(); label != "" {
+			fields = append(fields, map[string]interface{}{"title": "Branch", "value": label, "short": true})
+		}
+		if len(fields) > 0 {
+			attachment["fields"] = fields
+		}
+	}
+
+	payload := map[string]interface{}{
+		"attachments": []map[string]interface{}{attachment},
+	}
+	if mention != "" {
+		payload["text"] = mention
+	}
+
+	return f.addThreading(payload, details), nil
+}
+
+// addThreading sets the chat.postMessage channel and, once a thread exists, the parent message ts
+func (f *SlackFormatter) addThreading(payload map[string]interface{}, details Details) map[string]interface{} {
+	if f.Channel != "" {
+		payload["channel"] = f.Channel
+	}
+	if details.ThreadID != "" {
+		payload["thread_ts"] = details.ThreadID
+	}
+	return payload
+}
+
+// formatBlocks builds a Block Kit payload: header, mrkdwn message section and a context line
+// The top-level text is the fallback shown in push notifications
+func (f *SlackFormatter) formatBlocks(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details, mention string) map[string]interface{} {
+	header := fmt.Sprintf("%s %s", getEmojiForStatus(status, statusInfo), statusInfo.Title)
+	text := fmt.Sprintf("%s: %s", statusInfo.Title, message)
+	section := markdownToSlack(message)
+	if mention != "" {
+		text = mention + " " + text
+		section = mention + " " + section
+	}
+
+	var contextElements []map[string]interface{}
+	if footer, ok := details.customFooter(); ok {
+		if footer != "" {
+			contextElements = append(contextElements, map[string]interface{}{"type": "mrkdwn", "text": slackEscaper.Replace(footer)})
+		}
+	} else {
+		contextElements = append(contextElements, map[string]interface{}{
+			"type": "mrkdwn", "text": fmt.Sprintf("Session: `%s`", slackEscaper.Replace(sessionID)),
+		})
+		if details.Project != "" {
+			contextElements = append(contextElements, map[string]interface{}{
+				"type": "mrkdwn", "text": fmt.Sprintf("Project: `%s`", slackEscaper.Replace(details.Project)),
+			})
+		}
+		if label := details.gitLabel(); label != "" {
+			contextElements = append(contextElements, map[string]interface{}{
+				"type": "mrkdwn", "text": fmt.Sprintf("Branch: `%s`", slackEscaper.Replace(label)),
+			})
+		}
+	}
+
+	blocks := []map[string]interface{}{
+		{
+			"type": "header",
+			"text": map[string]interface{}{"type": "plain_text", "text": truncateRunes(header, slackHeaderLimit), "emoji": true},
 		},
-	}, nil
+		{
+			"type": "section",
+			"text": map[string]interface{}{"type": "mrkdwn", "text": truncateRunes(section, slackSectionLimit)},
+		},
+	}
+	if len(contextElements) > 0 {
+		blocks = append(blocks, map[string]interface{}{
+			"type":     "context",
+			"elements": contextElements,
+		})
+	}
+
+	return map[string]interface{}{
+		"text":   text,
+		"blocks": blocks,
+	}
+}
+
+// slackMention returns the mrkdwn mention for a user ID, or a user group ID (S...)
+func slackMention(id string) string {
+	if strings.HasPrefix(id, "S") {
+		return fmt.Sprintf("<!subteam^%s>", id)
+	}
+	return fmt.Sprintf("<@%s>", id)
+}
+
+// shouldMention reports whether status is one of the mention statuses (default: question)
+func shouldMention(status analyzer.Status, statuses []string) bool {
+	if len(statuses) == 0 {
+		return status == analyzer.StatusQuestion
+	}
+	for _, s := range statuses {
+		if s == string(status) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateRunes shortens s to at most limit runes, marking the cut with "..."
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-3]) + "..."
 }
 
 // DiscordFormatter formats messages for Discord with embeds
-type DiscordFormatter struct{}
+// When ButtonURL is set, messages get a link button; {sessionId} in the URL is replaced with the session ID
+type DiscordFormatter struct {
+	ButtonURL       string
+	Mention         string   // user ID, or &ID for a role, mentioned for MentionStatuses
+	MentionStatuses []string // default: question
+}
 
-func (f *DiscordFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
-	colorInt := getDiscordColorInt(status)
+func (f *DiscordFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	colorInt := getDiscordColorInt(status, statusInfo)
 
-	return map[string]interface{}{
+	embed := map[string]interface{}{
+		"title":       statusInfo.Title,
+		"description": markdownToDiscord(message),
+		"color":       colorInt,
+		"footer": map[string]interface{}{
+			"text": fmt.Sprintf("Session: %s", sessionID),
+		},
+		"timestamp": time.Now().Format(time.RFC3339),
+	}
+	if footer, ok := details.customFooter(); ok {
+		embed["footer"] = map[string]interface{}{"text": footer}
+		if footer == "" {
+			delete(embed, "footer")
+		}
+	} else {
+		var fields []map[string]interface{}
+		if details.Project != "" {
+			fields = append(fields, map[string]interface{}{"name": "Project", "value": details.Project, "inline": true})
+		}
+		if label := details.gitLabel(); label != "" {
+			fields = append(fields, map[string]interface{}{"name": "Branch", "value": label, "inline": true})
+		}
+		if len(fields) > 0 {
+			embed["fields"] = fields
+		}
+	}
+
+	payload := map[string]interface{}{
 		"username": "Claude Code",
-		"embeds": []map[string]interface{}{
+		"embeds":   []map[string]interface{}{embed},
+	}
+	if f.Mention != "" && shouldMention(status, f.MentionStatuses) {
+		// allowed_mentions restricts pings to the configured target, whatever the message says
+		if roleID, isRole := strings.CutPrefix(f.Mention, "&"); isRole {
+			payload["content"] = fmt.Sprintf("<@&%s>", roleID)
+			payload["allowed_mentions"] = map[string]interface{}{"parse": []string{}, "roles": []string{roleID}}
+		} else {
+			payload["content"] = fmt.Sprintf("<@%s>", f.Mention)
+			payload["allowed_mentions"] = map[string]interface{}{"parse": []string{}, "users": []string{f.Mention}}
+		}
+	}
+	if f.ButtonURL != "" {
+		// Action row (type 1) holding a link-style (style 5) button (type 2)
+		payload["components"] = []map[string]interface{}{
 			{
-				"title":       statusInfo.Title,
-				"description": message,
-				"color":       colorInt,
-				"footer": map[string]interface{}{
-					"text": fmt.Sprintf("Session: %s", sessionID),
+				"type": 1,
+				"components": []map[string]interface{}{
+					{
+						"type":  2,
+						"style": 5,
+						"label": "Open session",
+						"url":   strings.ReplaceAll(f.ButtonURL, "{sessionId}", url.PathEscape(sessionID)),
+					},
 				},
-				"timestamp": time.Now().Format(time.RFC3339),
 			},
-		},
-	}, nil
+		}
+	}
+
+	return payload, nil
+}
+
+// EditRequest returns the PATCH to the webhook's messages/{ref} endpoint
+// The username is fixed once posted, so it is dropped from the edit
+func (f *DiscordFormatter) EditRequest(webhookURL, ref string, payload []byte) (string, string, []byte, error) {
+	messageURL, err := discordMessageURL(webhookURL, ref)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return "", "", nil, err
+	}
+	delete(body, "username")
+	data, err := json.Marshal(body)
+	return http.MethodPatch, messageURL, data, err
+}
+
+// DeleteRequest returns the DELETE of the webhook's messages/{ref} endpoint
+func (f *DiscordFormatter) DeleteRequest(webhookURL, ref string) (string, string, []byte, error) {
+	messageURL, err := discordMessageURL(webhookURL, ref)
+	return http.MethodDelete, messageURL, nil, err
+}
+
+// discordMessageURL returns the webhook's messages/{ref} endpoint, without the wait query
+func discordMessageURL(webhookURL, ref string) (string, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/messages/" + ref
+	query := u.Query()
+	query.Del("wait")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
 }
 
 // TelegramFormatter formats messages for Telegram with HTML
@@ -61,11 +347,24 @@ type TelegramFormatter struct {
 	ChatID string
 }
 
-func (f *TelegramFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
-	// HTML formatting for Telegram
-	emoji := getEmojiForStatus(status)
-	text := fmt.Sprintf("<b>%s %s</b>\n\n%s\n\n<i>Session: %s</i>",
-		emoji, statusInfo.Title, message, sessionID)
+func (f *TelegramFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	// HTML formatting for Telegram, dynamic fields are escaped so only our tags are markup
+	emoji := getEmojiForStatus(status, statusInfo)
+	text := fmt.Sprintf("<b>%s %s</b>\n\n%s",
+		emoji, html.EscapeString(statusInfo.Title), markdownToTelegramHTML(message))
+	if footer, ok := details.customFooter(); ok {
+		if footer != "" {
+			text += fmt.Sprintf("\n\n<i>%s</i>", html.EscapeString(footer))
+		}
+	} else {
+		text += fmt.Sprintf("\n\n<i>Session: %s</i>", html.EscapeString(sessionID))
+		if details.Project != "" {
+			text += fmt.Sprintf("\n<i>Project: %s</i>", html.EscapeString(details.Project))
+		}
+		if label := details.gitLabel(); label != "" {
+			text += fmt.Sprintf("\n<i>Branch: %s</i>", html.EscapeString(label))
+		}
+	}
 
 	return map[string]interface{}{
 		"chat_id":    f.ChatID,
@@ -74,8 +373,65 @@ func (f *TelegramFormatter) Format(status analyzer.Status, message, sessionID st
 	}, nil
 }
 
+// EditRequest returns the editMessageText call for message ref, next to the bot's sendMessage URL
+func (f *TelegramFormatter) EditRequest(webhookURL, ref string, payload []byte) (string, string, []byte, error) {
+	editURL, err := telegramMethodURL(webhookURL, "editMessageText")
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return "", "", nil, err
+	}
+	body["message_id"] = telegramMessageID(ref)
+	data, err := json.Marshal(body)
+	return http.MethodPost, editURL, data, err
+}
+
+// DeleteRequest returns the deleteMessage call for message ref, next to the bot's sendMessage URL
+func (f *TelegramFormatter) DeleteRequest(webhookURL, ref string) (string, string, []byte, error) {
+	deleteURL, err := telegramMethodURL(webhookURL, "deleteMessage")
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"chat_id":    f.ChatID,
+		"message_id": telegramMessageID(ref),
+	})
+	return http.MethodPost, deleteURL, data, err
+}
+
+// telegramMethodURL replaces the sendMessage method of a bot API URL, e.g. with editMessageText
+func telegramMethodURL(webhookURL, method string) (string, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "", err
+	}
+	base, ok := strings.CutSuffix(u.Path, "/sendMessage")
+	if !ok {
+		// The path holds the bot token, so it is left out of the error
+		return "", fmt.Errorf("telegram webhook URL must end in /sendMessage to call %s", method)
+	}
+	u.Path = base + "/" + method
+	return u.String(), nil
+}
+
+// telegramMessageID returns ref as a number when it is one, as the bot API expects
+func telegramMessageID(ref string) interface{} {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return id
+	}
+	return ref
+}
+
 // getColorForStatus returns color hex code for status (Slack)
-func getColorForStatus(status analyzer.Status) string {
+// The status color override from config takes precedence
+func getColorForStatus(status analyzer.Status, statusInfo config.StatusInfo) string {
+	if statusInfo.Color != "" {
+		return statusInfo.Color
+	}
 	switch status {
 	case analyzer.StatusTaskComplete:
 		return "#28a745" // Green
@@ -91,7 +447,10 @@ func getColorForStatus(status analyzer.Status) string {
 }
 
 // getDiscordColorInt returns Discord color integer for status
-func getDiscordColorInt(status analyzer.Status) int {
+func getDiscordColorInt(status analyzer.Status, statusInfo config.StatusInfo) int {
+	if color, ok := parseHexColor(statusInfo.Color); ok {
+		return color
+	}
 	switch status {
 	case analyzer.StatusTaskComplete:
 		return 0x28a745 // Green
@@ -106,8 +465,25 @@ func getDiscordColorInt(status analyzer.Status) int {
 	}
 }
 
+// parseHexColor parses a #rrggbb color into an integer
+func parseHexColor(color string) (int, bool) {
+	hex, ok := strings.CutPrefix(color, "#")
+	if !ok || len(hex) != 6 {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int(value), true
+}
+
 // getEmojiForStatus returns emoji for status (Telegram)
-func getEmojiForStatus(status analyzer.Status) string {
+// The status emoji override from config takes precedence
+func getEmojiForStatus(status analyzer.Status, statusInfo config.StatusInfo) string {
+	if statusInfo.Emoji != "" {
+		return statusInfo.Emoji
+	}
 	switch status {
 	case analyzer.StatusTaskComplete:
 		return "✅"
@@ -125,7 +501,31 @@ func getEmojiForStatus(status analyzer.Status) string {
 // LarkFormatter formats messages for Feishu/Lark with interactive cards
 type LarkFormatter struct{}
 
-func (f *LarkFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo) (interface{}, error) {
+func (f *LarkFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	elements := []map[string]interface{}{
+		{
+			"tag": "div",
+			"text": map[string]interface{}{
+				"tag":     "plain_text",
+				"content": message,
+			},
+		},
+	}
+	if footer := sessionFooter(sessionID, details); footer != "" {
+		elements = append(elements,
+			map[string]interface{}{
+				"tag": "hr",
+			},
+			map[string]interface{}{
+				"tag": "div",
+				"text": map[string]interface{}{
+					"tag":     "plain_text",
+					"content": footer,
+				},
+			},
+		)
+	}
+
 	return map[string]interface{}{
 		"msg_type": "interactive",
 		"card": map[string]interface{}{
@@ -137,33 +537,19 @@ func (f *LarkFormatter) Format(status analyzer.Status, message, sessionID string
 					"tag":     "plain_text",
 					"content": statusInfo.Title,
 				},
-				"template": getLarkColorTemplate(status),
-			},
-			"elements": []map[string]interface{}{
-				{
-					"tag": "div",
-					"text": map[string]interface{}{
-						"tag":     "plain_text",
-						"content": message,
-					},
-				},
-				{
-					"tag": "hr",
-				},
-				{
-					"tag": "div",
-					"text": map[string]interface{}{
-						"tag":     "plain_text",
-						"content": fmt.Sprintf("Session: %s", sessionID),
-					},
-				},
+				"template": getLarkColorTemplate(status, statusInfo),
 			},
+			"elements": elements,
 		},
 	}, nil
 }
 
 // getLarkColorTemplate returns Lark color template for status
-func getLarkColorTemplate(status analyzer.Status) string {
+// A status color override is mapped to the closest Lark template
+func getLarkColorTemplate(status analyzer.Status, statusInfo config.StatusInfo) string {
+	if color, ok := parseHexColor(statusInfo.Color); ok {
+		return closestLarkTemplate(color)
+	}
 	switch status {
 	case analyzer.StatusTaskComplete:
 		return "green"
@@ -177,3 +563,568 @@ func getLarkColorTemplate(status analyzer.Status) string {
 		return "grey"
 	}
 }
+
+// larkTemplates approximates the header colors of Lark card templates
+var larkTemplates = []struct {
+	name  string
+	color int
+}{
+	{"blue", 0x3370ff},
+	{"wathet", 0x50a8f0},
+	{"turquoise", 0x2cb9a5},
+	{"green", 0x34c724},
+	{"yellow", 0xffc60a},
+	{"orange", 0xff8800},
+	{"red", 0xf54a45},
+	{"carmine", 0xe03e6d},
+	{"violet", 0xd136d1},
+	{"purple", 0x7f3bf5},
+	{"indigo", 0x4954e6},
+	{"grey", 0x8f959e},
+}
+
+// closestLarkTemplate returns the Lark template whose color is nearest to color
+func closestLarkTemplate(color int) string {
+	best, bestDist := "grey", -1
+	for _, tmpl := range larkTemplates {
+		dr := (color>>16)&0xff - (tmpl.color>>16)&0xff
+		dg := (color>>8)&0xff - (tmpl.color>>8)&0xff
+		db := color&0xff - tmpl.color&0xff
+		if dist := dr*dr + dg*dg + db*db; bestDist < 0 || dist < bestDist {
+			best, bestDist = tmpl.name, dist
+		}
+	}
+	return best
+}
+
+// TeamsFormatter formats messages for Microsoft Teams with MessageCards
+type TeamsFormatter struct{}
+
+func (f *TeamsFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	// MessageCard expects the theme color without the leading '#'
+	color := strings.TrimPrefix(getColorForStatus(status, statusInfo), "#")
+
+	card := map[string]interface{}{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"themeColor": color,
+		"summary":    statusInfo.Title,
+		"title":      statusInfo.Title,
+		"text":       message,
+	}
+
+	if footer, ok := details.customFooter(); ok {
+		if footer != "" {
+			card["sections"] = []map[string]interface{}{{"text": footer}}
+		}
+		return card, nil
+	}
+
+	facts := []map[string]interface{}{
+		{
+			"name":  "Session",
+			"value": sessionID,
+		},
+	}
+	if details.Project != "" {
+		facts = append(facts, map[string]interface{}{
+			"name":  "Project",
+			"value": details.Project,
+		})
+	}
+	if label := details.gitLabel(); label != "" {
+		facts = append(facts, map[string]interface{}{
+			"name":  "Branch",
+			"value": label,
+		})
+	}
+	card["sections"] = []map[string]interface{}{
+		{
+			"facts": facts,
+		},
+	}
+	return card, nil
+}
+
+// GoogleChatFormatter formats messages for Google Chat with cardsV2
+type GoogleChatFormatter struct{}
+
+func (f *GoogleChatFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	// Google Chat cards don't support custom colors, so the emoji carries the status
+	emoji := getEmojiForStatus(status, statusInfo)
+
+	return map[string]interface{}{
+		"cardsV2": []map[string]interface{}{
+			{
+				"cardId": "claude-notification",
+				"card": map[string]interface{}{
+					"header": map[string]interface{}{
+						"title": fmt.Sprintf("%s %s", emoji, statusInfo.Title),
+					},
+					"sections": []map[string]interface{}{
+						{
+							"widgets": []map[string]interface{}{
+								{
+									"decoratedText": map[string]interface{}{
+										"text":        message,
+										"wrapText":    true,
+										"bottomLabel": sessionFooter(sessionID, details),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// MatrixFormatter formats messages as Matrix m.room.message event content
+type MatrixFormatter struct{}
+
+func (f *MatrixFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	emoji := getEmojiForStatus(status, statusInfo)
+	footer := sessionFooter(sessionID, details)
+	body := appendFooter(fmt.Sprintf("%s %s\n\n%s", emoji, statusInfo.Title, message), footer)
+	formattedBody := fmt.Sprintf("<b>%s %s</b><br><br>%s",
+		emoji, html.EscapeString(statusInfo.Title), html.EscapeString(message))
+	if footer != "" {
+		formattedBody += fmt.Sprintf("<br><br><i>%s</i>", html.EscapeString(footer))
+	}
+
+	return map[string]interface{}{
+		"msgtype":        "m.text",
+		"body":           body,
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formattedBody,
+	}, nil
+}
+
+// DeleteRequest returns the redaction of event ref, in the room of the configured send endpoint
+// Redactions are PUT with a fresh transaction ID, e.g. .../rooms/{room}/redact/{event}/{txn}
+func (f *MatrixFormatter) DeleteRequest(webhookURL, ref string) (string, string, []byte, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "", "", nil, err
+	}
+	path := u.EscapedPath()
+	room := strings.LastIndex(path, "/send/")
+	if room < 0 {
+		return "", "", nil, fmt.Errorf("matrix webhook URL must be a room /send/ endpoint to redact messages")
+	}
+	rawPath := path[:room] + "/redact/" + url.PathEscape(ref) + "/" + uuid.New().String()
+	if u.Path, err = url.PathUnescape(rawPath); err != nil {
+		return "", "", nil, err
+	}
+	u.RawPath = rawPath
+	return http.MethodPut, u.String(), []byte(`{}`), nil
+}
+
+// NtfyFormatter formats messages for ntfy JSON publishing
+type NtfyFormatter struct {
+	Topic string
+}
+
+func (f *NtfyFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	return map[string]interface{}{
+		"topic":    f.Topic,
+		"title":    statusInfo.Title,
+		"message":  message,
+		"priority": getNtfyPriority(status, statusInfo, details),
+		"tags":     []string{getNtfyTag(status)},
+	}, nil
+}
+
+// getNtfyPriority returns ntfy priority (1-5) for status, unless overridden
+func getNtfyPriority(status analyzer.Status, statusInfo config.StatusInfo, details Details) int {
+	if p, ok := overridePriority(statusInfo, details); ok {
+		return p.ntfyPriority()
+	}
+	switch status {
+	case analyzer.StatusQuestion, analyzer.StatusPlanReady, analyzer.StatusSessionLimitReached:
+		return 4 // high
+	case analyzer.StatusAPIError:
+		return 5 // urgent
+	default:
+		return 3 // default
+	}
+}
+
+// getNtfyTag returns the ntfy emoji tag (shortcode) for the built-in status emoji
+// Emoji overrides are not applied because ntfy tags must be shortcodes
+func getNtfyTag(status analyzer.Status) string {
+	switch getEmojiForStatus(status, config.StatusInfo{}) {
+	case "✅":
+		return "white_check_mark"
+	case "🔍":
+		return "mag"
+	case "❓":
+		return "question"
+	case "📋":
+		return "clipboard"
+	default:
+		return "information_source"
+	}
+}
+
+// RocketChatFormatter formats messages for Rocket.Chat incoming webhooks with attachments
+type RocketChatFormatter struct{}
+
+func (f *RocketChatFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	attachment := map[string]interface{}{
+		"title": statusInfo.Title,
+		"text":  message,
+		"color": getColorForStatus(status, statusInfo),
+		"ts":    time.Now().Format(time.RFC3339),
+	}
+
+	if footer, ok := details.customFooter(); ok {
+		// Attachments have no footer, so the footer template goes under the text
+		attachment["text"] = appendFooter(message, footer)
+	} else {
+		fields := []map[string]interface{}{
+			{"title": "Session", "value": sessionID, "short": true},
+		}
+		if details.Project != "" {
+			fields = append(fields, map[string]interface{}{"title": "Project", "value": details.Project, "short": true})
+		}
+		if label := details.gitLabel(); label != "" {
+			fields = append(fields, map[string]interface{}{"title": "Branch", "value": label, "short": true})
+		}
+		attachment["fields"] = fields
+	}
+
+	return map[string]interface{}{
+		"alias":       "Claude Code",
+		"attachments": []map[string]interface{}{attachment},
+	}, nil
+}
+
+// Mattermost display identity; it only takes effect if the integration allows overriding it
+const (
+	mattermostUsername = "Claude Code"
+	mattermostIconURL  = "https://claude.ai/favicon.ico"
+)
+
+// MattermostFormatter formats messages for Mattermost incoming webhooks
+// Mattermost renders standard markdown, so the message is sent as-is rather than converted to Slack mrkdwn
+type MattermostFormatter struct{}
+
+func (f *MattermostFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	attachment := map[string]interface{}{
+		"fallback": fmt.Sprintf("%s: %s", statusInfo.Title, message),
+		"color":    getColorForStatus(status, statusInfo),
+		"text":     message,
+		"footer":   "Claude Notifications",
+	}
+
+	if footer, ok := details.customFooter(); ok {
+		attachment["footer"] = footer
+		if footer == "" {
+			delete(attachment, "footer")
+		}
+	} else {
+		fields := []map[string]interface{}{
+			{"title": "Session", "value": fmt.Sprintf("`%s`", sessionID), "short": true},
+		}
+		if details.Project != "" {
+			fields = append(fields, map[string]interface{}{"title": "Project", "value": fmt.Sprintf("`%s`", details.Project), "short": true})
+		}
+		if label := details.gitLabel(); label != "" {
+			fields = append(fields, map[string]interface{}{"title": "Branch", "value": fmt.Sprintf("`%s`", label), "short": true})
+		}
+		attachment["fields"] = fields
+	}
+
+	return map[string]interface{}{
+		"username":    mattermostUsername,
+		"icon_url":    mattermostIconURL,
+		"text":        fmt.Sprintf("**%s**", statusInfo.Title),
+		"attachments": []map[string]interface{}{attachment},
+		// The card is shown in the message's info panel
+		"props": map[string]interface{}{
+			"card": appendFooter(fmt.Sprintf("**%s**", statusInfo.Title), sessionFooter(sessionID, details)),
+		},
+	}, nil
+}
+
+// GotifyFormatter formats messages for Gotify
+// The app token is sent in the X-Gotify-Key header rather than the URL query
+type GotifyFormatter struct {
+	Token string
+}
+
+func (f *GotifyFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	payload := map[string]interface{}{
+		"title":    statusInfo.Title,
+		"message":  appendFooter(message, sessionFooter(sessionID, details)),
+		"priority": getGotifyPriority(status, statusInfo, details),
+	}
+
+	// Gotify has no sound field, so the sound travels as an extra for clients that read it
+	if statusInfo.MobileSound != "" {
+		payload["extras"] = map[string]interface{}{
+			gotifySoundExtra: map[string]interface{}{"sound": statusInfo.MobileSound},
+		}
+	}
+	return payload, nil
+}
+
+// gotifySoundExtra is the Gotify extras namespace carrying the configured mobile sound
+const gotifySoundExtra = "claude-notifications::notification"
+
+// Headers returns the Gotify authentication header, or none without a token
+func (f *GotifyFormatter) Headers() map[string]string {
+	if f.Token == "" {
+		return nil
+	}
+	return map[string]string{"X-Gotify-Key": f.Token}
+}
+
+// getGotifyPriority returns Gotify priority for status, unless overridden
+// Gotify clients treat 8-10 as high priority, 4-7 as normal
+func getGotifyPriority(status analyzer.Status, statusInfo config.StatusInfo, details Details) int {
+	if p, ok := overridePriority(statusInfo, details); ok {
+		return p.gotifyPriority()
+	}
+	switch status {
+	case analyzer.StatusQuestion:
+		return 8 // Claude is blocked waiting on a human
+	case analyzer.StatusAPIError:
+		return 7
+	case analyzer.StatusPlanReady, analyzer.StatusSessionLimitReached:
+		return 6
+	case analyzer.StatusTaskComplete, analyzer.StatusReviewComplete:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// WeComFormatter formats messages for WeCom (WeChat Work) group robots
+// WeCom robot markdown supports few tags, so only bold and newlines are used
+type WeComFormatter struct{}
+
+func (f *WeComFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	emoji := getEmojiForStatus(status, statusInfo)
+	content := appendFooter(fmt.Sprintf("**%s %s**\n\n%s", emoji, statusInfo.Title, message), sessionFooter(sessionID, details))
+
+	return map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]interface{}{
+			"content": content,
+		},
+	}, nil
+}
+
+// ZulipFormatter formats messages as Zulip stream messages
+// Without a configured topic, each session gets its own topic
+// The messages API only accepts form parameters, so the payload is sent form-encoded
+type ZulipFormatter struct {
+	Stream string
+	Topic  string
+}
+
+func (f *ZulipFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	topic := f.Topic
+	if topic == "" {
+		topic = sessionID
+	}
+
+	emoji := getEmojiForStatus(status, statusInfo)
+	content := fmt.Sprintf("%s **%s**\n\n%s", emoji, statusInfo.Title, message)
+	if footer := sessionFooter(sessionID, details); footer != "" {
+		content += fmt.Sprintf("\n\n*%s*", footer)
+	}
+
+	return map[string]interface{}{
+		"type":    "stream",
+		"to":      f.Stream,
+		"topic":   topic,
+		"content": content,
+	}, nil
+}
+
+// EncodeBody sends the message as form parameters
+func (f *ZulipFormatter) EncodeBody(payload interface{}) ([]byte, string, error) {
+	return encodeForm(payload)
+}
+
+// PagerDutyFormatter formats messages as PagerDuty Events API v2 trigger events
+type PagerDutyFormatter struct {
+	RoutingKey string
+}
+
+// pagerDutySummaryLimit is the maximum summary length in characters accepted by the Events API
+const pagerDutySummaryLimit = 1024
+
+func (f *PagerDutyFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	summary := truncateRunes(fmt.Sprintf("%s: %s", statusInfo.Title, message), pagerDutySummaryLimit)
+
+	customDetails := map[string]interface{}{
+		"status":     string(status),
+		"session_id": sessionID,
+	}
+	if details.Project != "" {
+		customDetails["project"] = details.Project
+	}
+	if details.GitBranch != "" {
+		customDetails["git_branch"] = details.GitBranch
+	}
+	if details.GitCommit != "" {
+		customDetails["git_commit"] = details.GitCommit
+	}
+
+	return map[string]interface{}{
+		"routing_key":  f.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    getPagerDutyDedupKey(sessionID),
+		"payload": map[string]interface{}{
+			"summary":        summary,
+			"source":         "claude-notifications",
+			"severity":       getPagerDutySeverity(status, statusInfo, details),
+			"timestamp":      time.Now().Format(time.RFC3339),
+			"custom_details": customDetails,
+		},
+	}, nil
+}
+
+// getPagerDutyDedupKey returns the dedup key for a session, so repeat events update one incident
+func getPagerDutyDedupKey(sessionID string) string {
+	return fmt.Sprintf("claude-notifications-%s", sessionID)
+}
+
+// getPagerDutySeverity returns PagerDuty severity for status, unless overridden
+func getPagerDutySeverity(status analyzer.Status, statusInfo config.StatusInfo, details Details) string {
+	if p, ok := overridePriority(statusInfo, details); ok {
+		return p.pagerDutySeverity()
+	}
+	switch status {
+	case analyzer.StatusQuestion:
+		return "critical" // Claude is blocked waiting on a human
+	case analyzer.StatusAPIError:
+		return "error"
+	case analyzer.StatusSessionLimitReached:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// OpsGenieFormatter formats messages as OpsGenie Alerts API create-alert requests
+// The API key is sent in the Authorization header
+type OpsGenieFormatter struct {
+	APIKey string
+}
+
+// OpsGenie field limits in characters, longer values are rejected or cut by the Alerts API
+const (
+	opsGenieMessageLimit     = 130
+	opsGenieDescriptionLimit = 15000
+)
+
+func (f *OpsGenieFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	title := truncateRunes(fmt.Sprintf("%s: %s", statusInfo.Title, message), opsGenieMessageLimit)
+	description := truncateRunes(appendFooter(message, sessionFooter(sessionID, details)), opsGenieDescriptionLimit)
+
+	alertDetails := map[string]interface{}{
+		"status":     string(status),
+		"session_id": sessionID,
+	}
+	if details.Project != "" {
+		alertDetails["project"] = details.Project
+	}
+	if details.GitBranch != "" {
+		alertDetails["git_branch"] = details.GitBranch
+	}
+	if details.GitCommit != "" {
+		alertDetails["git_commit"] = details.GitCommit
+	}
+
+	return map[string]interface{}{
+		"message":     title,
+		"alias":       sessionID, // OpsGenie deduplicates open alerts by alias
+		"description": description,
+		"priority":    getOpsGeniePriority(status, statusInfo, details),
+		"tags":        []string{"claude-notifications", string(status)},
+		"source":      "claude-notifications",
+		"details":     alertDetails,
+	}, nil
+}
+
+// Headers returns the OpsGenie authentication header
+func (f *OpsGenieFormatter) Headers() map[string]string {
+	return map[string]string{"Authorization": "GenieKey " + f.APIKey}
+}
+
+// getOpsGeniePriority returns OpsGenie priority for status, unless overridden; P1 is the most urgent
+func getOpsGeniePriority(status analyzer.Status, statusInfo config.StatusInfo, details Details) string {
+	if p, ok := overridePriority(statusInfo, details); ok {
+		return p.opsGeniePriority()
+	}
+	switch status {
+	case analyzer.StatusQuestion:
+		return "P1" // Claude is blocked waiting on a human
+	case analyzer.StatusAPIError:
+		return "P2"
+	case analyzer.StatusSessionLimitReached:
+		return "P3"
+	case analyzer.StatusPlanReady:
+		return "P4"
+	default:
+		return "P5"
+	}
+}
+
+// PushoverFormatter formats messages for the Pushover messages API
+type PushoverFormatter struct {
+	Token string
+	User  string
+}
+
+func (f *PushoverFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	return map[string]interface{}{
+		"token":    f.Token,
+		"user":     f.User,
+		"title":    statusInfo.Title,
+		"message":  appendFooter(message, sessionFooter(sessionID, details)),
+		"priority": getPushoverPriority(status, statusInfo, details),
+		"sound":    getPushoverSound(status, statusInfo),
+	}, nil
+}
+
+// getPushoverPriority returns Pushover priority for status, unless overridden (1 = high, bypasses quiet hours)
+func getPushoverPriority(status analyzer.Status, statusInfo config.StatusInfo, details Details) int {
+	if p, ok := overridePriority(statusInfo, details); ok {
+		return p.pushoverPriority()
+	}
+	if status == analyzer.StatusQuestion {
+		return 1
+	}
+	return 0
+}
+
+// getPushoverSound returns the Pushover notification sound for status
+// The status's mobileSound wins, e.g. "vibrate" to only vibrate or "none" for silence
+func getPushoverSound(status analyzer.Status, statusInfo config.StatusInfo) string {
+	if statusInfo.MobileSound != "" {
+		return statusInfo.MobileSound
+	}
+	switch status {
+	case analyzer.StatusTaskComplete:
+		return "magic"
+	case analyzer.StatusReviewComplete:
+		return "pianobar"
+	case analyzer.StatusQuestion:
+		return "echo"
+	case analyzer.StatusPlanReady:
+		return "incoming"
+	case analyzer.StatusSessionLimitReached:
+		return "falling"
+	case analyzer.StatusAPIError:
+		return "siren"
+	default:
+		return "pushover"
+	}
+}
@@ -2,8 +2,10 @@ package webhook
 
 import (
 	"encoding/json"
+	"net/url"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/777genius/claude-notifications/internal/analyzer"
 	"github.com/777genius/claude-notifications/internal/config"
@@ -20,6 +22,7 @@ func TestSlackFormatterFormat(t *testing.T) {
 		"The task has been completed successfully",
 		"session-123",
 		statusInfo,
+		Details{},
 	)
 
 	if err != nil {
@@ -89,7 +92,7 @@ func TestSlackFormatterColors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(string(tt.status), func(t *testing.T) {
-			result, err := formatter.Format(tt.status, "test", "session-1", statusInfo)
+			result, err := formatter.Format(tt.status, "test", "session-1", statusInfo, Details{})
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
@@ -105,6 +108,65 @@ func TestSlackFormatterColors(t *testing.T) {
 	}
 }
 
+func TestSlackFormatterBlocks(t *testing.T) {
+	formatter := &SlackFormatter{Blocks: true}
+	statusInfo := config.StatusInfo{Title: "Task Complete"}
+
+	result, err := formatter.Format(
+		analyzer.StatusTaskComplete,
+		"Updated **auth.go**",
+		"session-123",
+		statusInfo,
+		Details{GitBranch: "main", GitCommit: "abc1234"},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if _, ok := resultMap["attachments"]; ok {
+		t.Error("Block Kit payload should not use attachments")
+	}
+	if text, _ := resultMap["text"].(string); text != "Task Complete: Updated **auth.go**" {
+		t.Errorf("Expected fallback text, got %q", text)
+	}
+
+	blocks, ok := resultMap["blocks"].([]map[string]interface{})
+	if !ok {
+		t.Fatal("Should have blocks array")
+	}
+
+	byType := map[string]map[string]interface{}{}
+	for _, block := range blocks {
+		byType[block["type"].(string)] = block
+	}
+
+	header, ok := byType["header"]
+	if !ok {
+		t.Fatal("Should have a header block")
+	}
+	if text := header["text"].(map[string]interface{})["text"]; text != "✅ Task Complete" {
+		t.Errorf("Expected header title, got %v", text)
+	}
+
+	section, ok := byType["section"]
+	if !ok {
+		t.Fatal("Should have a section block")
+	}
+	if text := section["text"].(map[string]interface{})["text"]; text != "Updated *auth.go*" {
+		t.Errorf("Expected mrkdwn message, got %v", text)
+	}
+
+	context, ok := byType["context"]
+	if !ok {
+		t.Fatal("Should have a context block")
+	}
+	data, _ := json.Marshal(context)
+	if !strings.Contains(string(data), "session-123") || !strings.Contains(string(data), "main") {
+		t.Errorf("Context should carry session and branch, got %s", data)
+	}
+}
+
 func TestDiscordFormatterFormat(t *testing.T) {
 	formatter := &DiscordFormatter{}
 	statusInfo := config.StatusInfo{
@@ -116,6 +178,7 @@ func TestDiscordFormatterFormat(t *testing.T) {
 		"What should we do next?",
 		"session-456",
 		statusInfo,
+		Details{},
 	)
 
 	if err != nil {
@@ -200,7 +263,7 @@ func TestDiscordFormatterColors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(string(tt.status), func(t *testing.T) {
-			result, err := formatter.Format(tt.status, "test", "session-1", statusInfo)
+			result, err := formatter.Format(tt.status, "test", "session-1", statusInfo, Details{})
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
@@ -216,6 +279,113 @@ func TestDiscordFormatterColors(t *testing.T) {
 	}
 }
 
+func TestSlackFormatterMention(t *testing.T) {
+	statusInfo := config.StatusInfo{Title: "Test"}
+
+	tests := []struct {
+		name      string
+		formatter *SlackFormatter
+		status    analyzer.Status
+		want      string
+	}{
+		{"question mentions user", &SlackFormatter{Mention: "U123"}, analyzer.StatusQuestion, "<@U123>"},
+		{"task complete not mentioned", &SlackFormatter{Mention: "U123"}, analyzer.StatusTaskComplete, ""},
+		{"user group", &SlackFormatter{Mention: "S456"}, analyzer.StatusQuestion, "<!subteam^S456>"},
+		{"custom statuses", &SlackFormatter{Mention: "U123", MentionStatuses: []string{"task_complete"}}, analyzer.StatusTaskComplete, "<@U123>"},
+		{"no mention configured", &SlackFormatter{}, analyzer.StatusQuestion, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.formatter.Format(tt.status, "msg", "session-1", statusInfo, Details{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			text, _ := result.(map[string]interface{})["text"].(string)
+			if text != tt.want {
+				t.Errorf("Expected text %q, got %q", tt.want, text)
+			}
+		})
+	}
+
+	// Block Kit puts the mention in the message section
+	result, _ := (&SlackFormatter{Blocks: true, Mention: "U123"}).Format(analyzer.StatusQuestion, "msg", "session-1", statusInfo, Details{})
+	blocks := result.(map[string]interface{})["blocks"].([]map[string]interface{})
+	if text := blocks[1]["text"].(map[string]interface{})["text"]; text != "<@U123> msg" {
+		t.Errorf("Expected mention in section, got %v", text)
+	}
+}
+
+func TestDiscordFormatterMention(t *testing.T) {
+	statusInfo := config.StatusInfo{Title: "Test"}
+	formatter := &DiscordFormatter{Mention: "123456"}
+
+	result, err := formatter.Format(analyzer.StatusQuestion, "msg", "session-1", statusInfo, Details{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	payload := result.(map[string]interface{})
+	if payload["content"] != "<@123456>" {
+		t.Errorf("Expected user mention content, got %v", payload["content"])
+	}
+	data, _ := json.Marshal(payload["allowed_mentions"])
+	if string(data) != `{"parse":[],"users":["123456"]}` {
+		t.Errorf("Expected allowed_mentions for the user, got %s", data)
+	}
+
+	result, _ = formatter.Format(analyzer.StatusTaskComplete, "msg", "session-1", statusInfo, Details{})
+	payload = result.(map[string]interface{})
+	if _, ok := payload["content"]; ok {
+		t.Error("Task complete should not mention")
+	}
+	if _, ok := payload["allowed_mentions"]; ok {
+		t.Error("Task complete should not set allowed_mentions")
+	}
+
+	result, _ = (&DiscordFormatter{Mention: "&789"}).Format(analyzer.StatusQuestion, "msg", "session-1", statusInfo, Details{})
+	payload = result.(map[string]interface{})
+	if payload["content"] != "<@&789>" {
+		t.Errorf("Expected role mention content, got %v", payload["content"])
+	}
+	data, _ = json.Marshal(payload["allowed_mentions"])
+	if string(data) != `{"parse":[],"roles":["789"]}` {
+		t.Errorf("Expected allowed_mentions for the role, got %s", data)
+	}
+}
+
+func TestDiscordFormatterButton(t *testing.T) {
+	statusInfo := config.StatusInfo{Title: "Plan Ready"}
+
+	result, err := (&DiscordFormatter{}).Format(analyzer.StatusPlanReady, "Plan", "session 1", statusInfo, Details{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := result.(map[string]interface{})["components"]; ok {
+		t.Error("Components should be omitted without a button URL")
+	}
+
+	formatter := &DiscordFormatter{ButtonURL: "https://dash.example.com/sessions/{sessionId}"}
+	result, err = formatter.Format(analyzer.StatusPlanReady, "Plan", "session 1", statusInfo, Details{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	components, ok := result.(map[string]interface{})["components"].([]map[string]interface{})
+	if !ok || len(components) != 1 {
+		t.Fatalf("Expected one action row, got %v", result.(map[string]interface{})["components"])
+	}
+	if components[0]["type"] != 1 {
+		t.Errorf("Expected action row type 1, got %v", components[0]["type"])
+	}
+	button := components[0]["components"].([]map[string]interface{})[0]
+	if button["type"] != 2 || button["style"] != 5 {
+		t.Errorf("Expected link button, got type=%v style=%v", button["type"], button["style"])
+	}
+	if button["url"] != "https://dash.example.com/sessions/session%201" {
+		t.Errorf("Expected session ID in URL, got %v", button["url"])
+	}
+}
+
 func TestTelegramFormatterFormat(t *testing.T) {
 	formatter := &TelegramFormatter{ChatID: "123456789"}
 	statusInfo := config.StatusInfo{
@@ -227,6 +397,7 @@ func TestTelegramFormatterFormat(t *testing.T) {
 		"Code review finished",
 		"session-789",
 		statusInfo,
+		Details{},
 	)
 
 	if err != nil {
@@ -303,7 +474,7 @@ func TestTelegramFormatterEmojis(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(string(tt.status), func(t *testing.T) {
-			result, err := formatter.Format(tt.status, "test", "session-1", statusInfo)
+			result, err := formatter.Format(tt.status, "test", "session-1", statusInfo, Details{})
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
@@ -318,6 +489,31 @@ func TestTelegramFormatterEmojis(t *testing.T) {
 	}
 }
 
+func TestStatusStyleOverrides(t *testing.T) {
+	statusInfo := config.StatusInfo{Emoji: "🎉", Color: "#ff00aa"}
+
+	if emoji := getEmojiForStatus(analyzer.StatusTaskComplete, statusInfo); emoji != "🎉" {
+		t.Errorf("Expected emoji override, got %s", emoji)
+	}
+	if color := getColorForStatus(analyzer.StatusTaskComplete, statusInfo); color != "#ff00aa" {
+		t.Errorf("Expected color override, got %s", color)
+	}
+	if color := getDiscordColorInt(analyzer.StatusTaskComplete, statusInfo); color != 0xff00aa {
+		t.Errorf("Expected Discord color 0xff00aa, got 0x%x", color)
+	}
+	if tmpl := getLarkColorTemplate(analyzer.StatusTaskComplete, statusInfo); tmpl != "violet" {
+		t.Errorf("Expected closest Lark template violet, got %s", tmpl)
+	}
+	if tmpl := getLarkColorTemplate(analyzer.StatusTaskComplete, config.StatusInfo{Color: "#2ec730"}); tmpl != "green" {
+		t.Errorf("Expected closest Lark template green, got %s", tmpl)
+	}
+
+	// ntfy tags stay on the built-in shortcodes
+	if tag := getNtfyTag(analyzer.StatusTaskComplete); tag != "white_check_mark" {
+		t.Errorf("Expected built-in ntfy tag, got %s", tag)
+	}
+}
+
 func TestGetColorForStatus(t *testing.T) {
 	tests := []struct {
 		status   analyzer.Status
@@ -332,7 +528,7 @@ func TestGetColorForStatus(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(string(tt.status), func(t *testing.T) {
-			result := getColorForStatus(tt.status)
+			result := getColorForStatus(tt.status, config.StatusInfo{})
 			if result != tt.expected {
 				t.Errorf("Expected %s, got %s", tt.expected, result)
 			}
@@ -354,7 +550,7 @@ func TestGetDiscordColorInt(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(string(tt.status), func(t *testing.T) {
-			result := getDiscordColorInt(tt.status)
+			result := getDiscordColorInt(tt.status, config.StatusInfo{})
 			if result != tt.expected {
 				t.Errorf("Expected 0x%x, got 0x%x", tt.expected, result)
 			}
@@ -376,7 +572,7 @@ func TestGetEmojiForStatus(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(string(tt.status), func(t *testing.T) {
-			result := getEmojiForStatus(tt.status)
+			result := getEmojiForStatus(tt.status, config.StatusInfo{})
 			if result != tt.expected {
 				t.Errorf("Expected %s, got %s", tt.expected, result)
 			}
@@ -395,6 +591,7 @@ func TestLarkFormatterFormat(t *testing.T) {
 		"The task has been completed successfully",
 		"session-123",
 		statusInfo,
+		Details{},
 	)
 
 	if err != nil {
@@ -505,7 +702,7 @@ func TestLarkFormatterColors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(string(tt.status), func(t *testing.T) {
-			result, err := formatter.Format(tt.status, "test", "session-1", statusInfo)
+			result, err := formatter.Format(tt.status, "test", "session-1", statusInfo, Details{})
 			if err != nil {
 				t.Fatalf("Unexpected error: %v", err)
 			}
@@ -531,6 +728,7 @@ func TestLarkFormatterUnknownStatus(t *testing.T) {
 		"Unknown status",
 		"session-999",
 		statusInfo,
+		Details{},
 	)
 
 	if err != nil {
@@ -561,10 +759,915 @@ func TestGetLarkColorTemplate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(string(tt.status), func(t *testing.T) {
-			result := getLarkColorTemplate(tt.status)
+			result := getLarkColorTemplate(tt.status, config.StatusInfo{})
 			if result != tt.expected {
 				t.Errorf("Expected %s, got %s", tt.expected, result)
 			}
 		})
 	}
 }
+
+func TestTeamsFormatterFormat(t *testing.T) {
+	formatter := &TeamsFormatter{}
+	statusInfo := config.StatusInfo{
+		Title: "Task Complete",
+	}
+
+	result, err := formatter.Format(
+		analyzer.StatusTaskComplete,
+		"The task has been completed successfully",
+		"session-123",
+		statusInfo,
+		Details{},
+	)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Verify structure
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	// Check card type
+	if resultMap["@type"] != "MessageCard" {
+		t.Errorf("Expected @type 'MessageCard', got %v", resultMap["@type"])
+	}
+
+	// Check theme color (same palette as Slack, without '#')
+	themeColor, ok := resultMap["themeColor"].(string)
+	if !ok || themeColor != "28a745" {
+		t.Errorf("Expected themeColor 28a745, got %v", themeColor)
+	}
+
+	// Check title
+	title, ok := resultMap["title"].(string)
+	if !ok || title != "Task Complete" {
+		t.Errorf("Expected title 'Task Complete', got %v", title)
+	}
+
+	// Check text
+	text, ok := resultMap["text"].(string)
+	if !ok || text != "The task has been completed successfully" {
+		t.Errorf("Expected message text, got %v", text)
+	}
+
+	// Check session fact
+	sections, ok := resultMap["sections"].([]map[string]interface{})
+	if !ok || len(sections) == 0 {
+		t.Fatal("Should have sections array")
+	}
+	facts, ok := sections[0]["facts"].([]map[string]interface{})
+	if !ok || len(facts) == 0 {
+		t.Fatal("Should have facts array")
+	}
+	if facts[0]["value"] != "session-123" {
+		t.Errorf("Expected session fact 'session-123', got %v", facts[0]["value"])
+	}
+
+	// Verify it's valid JSON
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Errorf("Result should be JSON-serializable: %v", err)
+	}
+	if len(data) == 0 {
+		t.Error("JSON data should not be empty")
+	}
+}
+
+func TestTeamsFormatterColors(t *testing.T) {
+	formatter := &TeamsFormatter{}
+	statusInfo := config.StatusInfo{Title: "Test"}
+
+	tests := []struct {
+		status        analyzer.Status
+		expectedColor string
+	}{
+		{analyzer.StatusTaskComplete, "28a745"},
+		{analyzer.StatusReviewComplete, "17a2b8"},
+		{analyzer.StatusQuestion, "ffc107"},
+		{analyzer.StatusPlanReady, "007bff"},
+		{analyzer.Status("unknown"), "6c757d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			result, err := formatter.Format(tt.status, "test", "session-1", statusInfo, Details{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			color := resultMap["themeColor"].(string)
+
+			if color != tt.expectedColor {
+				t.Errorf("Expected themeColor %s for %s, got %s", tt.expectedColor, tt.status, color)
+			}
+		})
+	}
+}
+
+func TestGoogleChatFormatterFormat(t *testing.T) {
+	formatter := &GoogleChatFormatter{}
+	statusInfo := config.StatusInfo{
+		Title: "Task Complete",
+	}
+
+	result, err := formatter.Format(
+		analyzer.StatusTaskComplete,
+		"The task has been completed successfully",
+		"session-123",
+		statusInfo,
+		Details{},
+	)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Verify structure
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	cards, ok := resultMap["cardsV2"].([]map[string]interface{})
+	if !ok || len(cards) == 0 {
+		t.Fatal("Should have cardsV2 array")
+	}
+
+	card, ok := cards[0]["card"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Should have card map")
+	}
+
+	// Check header title is prefixed with the status emoji
+	header, ok := card["header"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Should have header map")
+	}
+	if header["title"] != "✅ Task Complete" {
+		t.Errorf("Expected title '✅ Task Complete', got %v", header["title"])
+	}
+
+	// Check decorated text widget
+	sections := card["sections"].([]map[string]interface{})
+	widgets := sections[0]["widgets"].([]map[string]interface{})
+	decorated, ok := widgets[0]["decoratedText"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Should have decoratedText widget")
+	}
+	if decorated["text"] != "The task has been completed successfully" {
+		t.Errorf("Expected message text, got %v", decorated["text"])
+	}
+	label, ok := decorated["bottomLabel"].(string)
+	if !ok || !strings.Contains(label, "session-123") {
+		t.Errorf("Bottom label should contain session ID, got %v", decorated["bottomLabel"])
+	}
+
+	// Verify JSON contains the message
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Result should be JSON-serializable: %v", err)
+	}
+	if !strings.Contains(string(data), `"cardsV2"`) || !strings.Contains(string(data), "The task has been completed successfully") {
+		t.Errorf("JSON should contain cardsV2 and message, got %s", data)
+	}
+}
+
+func TestMatrixFormatterFormat(t *testing.T) {
+	formatter := &MatrixFormatter{}
+	statusInfo := config.StatusInfo{
+		Title: "Task Complete",
+	}
+
+	result, err := formatter.Format(
+		analyzer.StatusTaskComplete,
+		"Refactored <auth> module",
+		"session-123",
+		statusInfo,
+		Details{},
+	)
+
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	if resultMap["msgtype"] != "m.text" {
+		t.Errorf("Expected msgtype 'm.text', got %v", resultMap["msgtype"])
+	}
+	if resultMap["format"] != "org.matrix.custom.html" {
+		t.Errorf("Expected format 'org.matrix.custom.html', got %v", resultMap["format"])
+	}
+
+	// Plaintext body carries the raw message
+	body, ok := resultMap["body"].(string)
+	if !ok || !strings.Contains(body, "Refactored <auth> module") {
+		t.Errorf("Body should contain message, got %v", body)
+	}
+
+	// HTML body bolds the title, italicizes the session, and escapes the message
+	formatted, ok := resultMap["formatted_body"].(string)
+	if !ok {
+		t.Fatal("Should have formatted_body")
+	}
+	if !strings.Contains(formatted, "<b>✅ Task Complete</b>") {
+		t.Errorf("formatted_body should bold the title, got %s", formatted)
+	}
+	if !strings.Contains(formatted, "Refactored &lt;auth&gt; module") {
+		t.Errorf("formatted_body should contain escaped message, got %s", formatted)
+	}
+	if !strings.Contains(formatted, "<i>Session: session-123</i>") {
+		t.Errorf("formatted_body should italicize session, got %s", formatted)
+	}
+}
+
+func TestNtfyFormatterFormat(t *testing.T) {
+	formatter := &NtfyFormatter{Topic: "claude"}
+	statusInfo := config.StatusInfo{Title: "Task Complete"}
+
+	result, err := formatter.Format(analyzer.StatusTaskComplete, "All done", "session-123", statusInfo, Details{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	if resultMap["topic"] != "claude" {
+		t.Errorf("Expected topic 'claude', got %v", resultMap["topic"])
+	}
+	if resultMap["title"] != "Task Complete" {
+		t.Errorf("Expected title 'Task Complete', got %v", resultMap["title"])
+	}
+	if resultMap["message"] != "All done" {
+		t.Errorf("Expected message 'All done', got %v", resultMap["message"])
+	}
+}
+
+func TestNtfyFormatterPriorityAndTags(t *testing.T) {
+	formatter := &NtfyFormatter{Topic: "claude"}
+	statusInfo := config.StatusInfo{Title: "Test"}
+
+	tests := []struct {
+		status           analyzer.Status
+		expectedPriority int
+		expectedTag      string
+	}{
+		{analyzer.StatusTaskComplete, 3, "white_check_mark"},
+		{analyzer.StatusReviewComplete, 3, "mag"},
+		{analyzer.StatusQuestion, 4, "question"},
+		{analyzer.StatusPlanReady, 4, "clipboard"},
+		{analyzer.StatusSessionLimitReached, 4, "information_source"},
+		{analyzer.StatusAPIError, 5, "information_source"},
+		{analyzer.StatusUnknown, 3, "information_source"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			result, _ := formatter.Format(tt.status, "msg", "session", statusInfo, Details{})
+			resultMap := result.(map[string]interface{})
+
+			if resultMap["priority"] != tt.expectedPriority {
+				t.Errorf("Expected priority %d, got %v", tt.expectedPriority, resultMap["priority"])
+			}
+
+			tags, ok := resultMap["tags"].([]string)
+			if !ok || len(tags) != 1 || tags[0] != tt.expectedTag {
+				t.Errorf("Expected tags [%s], got %v", tt.expectedTag, resultMap["tags"])
+			}
+		})
+	}
+}
+
+func TestFormattersProject(t *testing.T) {
+	statusInfo := config.StatusInfo{Title: "Task Complete"}
+	details := Details{Project: "my-app", GitBranch: "main"}
+
+	slack, _ := (&SlackFormatter{}).Format(analyzer.StatusTaskComplete, "Done", "session-123", statusInfo, details)
+	attachment := slack.(map[string]interface{})["attachments"].([]map[string]interface{})[0]
+	fields, ok := attachment["fields"].([]map[string]interface{})
+	if !ok || len(fields) != 2 || fields[0]["title"] != "Project" || fields[0]["value"] != "my-app" {
+		t.Errorf("Expected Slack project field before branch, got %v", attachment["fields"])
+	}
+
+	discord, _ := (&DiscordFormatter{}).Format(analyzer.StatusTaskComplete, "Done", "session-123", statusInfo, Details{Project: "my-app"})
+	embed := discord.(map[string]interface{})["embeds"].([]map[string]interface{})[0]
+	fields, ok = embed["fields"].([]map[string]interface{})
+	if !ok || len(fields) != 1 || fields[0]["name"] != "Project" || fields[0]["value"] != "my-app" {
+		t.Errorf("Expected Discord project field, got %v", embed["fields"])
+	}
+
+	telegram, _ := (&TelegramFormatter{}).Format(analyzer.StatusTaskComplete, "Done", "session-123", statusInfo, details)
+	if text := telegram.(map[string]interface{})["text"].(string); !strings.Contains(text, "<i>Project: my-app</i>\n<i>Branch: main</i>") {
+		t.Errorf("Expected Telegram project line, got %s", text)
+	}
+
+	matrix, _ := (&MatrixFormatter{}).Format(analyzer.StatusTaskComplete, "Done", "session-123", statusInfo, details)
+	if body := matrix.(map[string]interface{})["body"].(string); !strings.HasSuffix(body, "Session: session-123 | Project: my-app | Branch: main") {
+		t.Errorf("Expected Matrix project footer, got %s", body)
+	}
+}
+
+func TestFormattersGitBranch(t *testing.T) {
+	statusInfo := config.StatusInfo{Title: "Task Complete"}
+	details := Details{GitBranch: "feature/login", GitCommit: "abc1234"}
+
+	slack, _ := (&SlackFormatter{}).Format(analyzer.StatusTaskComplete, "Done", "session-123", statusInfo, details)
+	attachment := slack.(map[string]interface{})["attachments"].([]map[string]interface{})[0]
+	fields, ok := attachment["fields"].([]map[string]interface{})
+	if !ok || len(fields) != 1 || fields[0]["title"] != "Branch" || fields[0]["value"] != "feature/login (abc1234)" {
+		t.Errorf("Expected Slack branch field, got %v", attachment["fields"])
+	}
+
+	discord, _ := (&DiscordFormatter{}).Format(analyzer.StatusTaskComplete, "Done", "session-123", statusInfo, details)
+	embed := discord.(map[string]interface{})["embeds"].([]map[string]interface{})[0]
+	fields, ok = embed["fields"].([]map[string]interface{})
+	if !ok || len(fields) != 1 || fields[0]["name"] != "Branch" || fields[0]["value"] != "feature/login (abc1234)" {
+		t.Errorf("Expected Discord branch field, got %v", embed["fields"])
+	}
+
+	telegram, _ := (&TelegramFormatter{}).Format(analyzer.StatusTaskComplete, "Done", "session-123", statusInfo, details)
+	if text := telegram.(map[string]interface{})["text"].(string); !strings.Contains(text, "<i>Branch: feature/login (abc1234)</i>") {
+		t.Errorf("Expected Telegram branch line, got %s", text)
+	}
+
+	matrix, _ := (&MatrixFormatter{}).Format(analyzer.StatusTaskComplete, "Done", "session-123", statusInfo, details)
+	if body := matrix.(map[string]interface{})["body"].(string); !strings.HasSuffix(body, "Session: session-123 | Branch: feature/login (abc1234)") {
+		t.Errorf("Expected Matrix branch footer, got %s", body)
+	}
+
+	// Without git info the fields are omitted
+	slack, _ = (&SlackFormatter{}).Format(analyzer.StatusTaskComplete, "Done", "session-123", statusInfo, Details{})
+	attachment = slack.(map[string]interface{})["attachments"].([]map[string]interface{})[0]
+	if _, ok := attachment["fields"]; ok {
+		t.Errorf("Expected no Slack fields without git info, got %v", attachment["fields"])
+	}
+}
+
+func TestTelegramFormatterEscapesHTML(t *testing.T) {
+	formatter := &TelegramFormatter{ChatID: "123"}
+	statusInfo := config.StatusInfo{Title: "Build <fast> & done"}
+
+	result, err := formatter.Format(analyzer.StatusTaskComplete, "Removed <script>alert(1)</script> from a & b", "session<1>&2", statusInfo, Details{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	text := result.(map[string]interface{})["text"].(string)
+	expected := "<b>✅ Build &lt;fast&gt; &amp; done</b>\n\n" +
+		"Removed &lt;script&gt;alert(1)&lt;/script&gt; from a &amp; b\n\n" +
+		"<i>Session: session&lt;1&gt;&amp;2</i>"
+	if text != expected {
+		t.Errorf("Expected escaped HTML:\n%s\ngot:\n%s", expected, text)
+	}
+
+	// Only the formatter's own tags remain as markup
+	stripped := text
+	for _, tag := range []string{"<b>", "</b>", "<i>", "</i>"} {
+		stripped = strings.ReplaceAll(stripped, tag, "")
+	}
+	if strings.ContainsAny(stripped, "<>") {
+		t.Errorf("Unexpected raw markup in %q", text)
+	}
+	if strings.Contains(strings.ReplaceAll(stripped, "&amp;", ""), "& ") {
+		t.Errorf("Unescaped ampersand in %q", text)
+	}
+}
+
+func TestOpsGenieFormatterFormat(t *testing.T) {
+	formatter := &OpsGenieFormatter{APIKey: "api-key"}
+	statusInfo := config.StatusInfo{Title: "Question"}
+
+	result, err := formatter.Format(analyzer.StatusQuestion, "Which database?", "session-123", statusInfo, Details{GitBranch: "main"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	if resultMap["message"] != "Question: Which database?" {
+		t.Errorf("Expected message 'Question: Which database?', got %v", resultMap["message"])
+	}
+	if resultMap["alias"] != "session-123" {
+		t.Errorf("Expected alias to equal the session ID, got %v", resultMap["alias"])
+	}
+	tags := resultMap["tags"].([]string)
+	if len(tags) != 2 || tags[0] != "claude-notifications" || tags[1] != "question" {
+		t.Errorf("Expected tags [claude-notifications question], got %v", tags)
+	}
+	alertDetails := resultMap["details"].(map[string]interface{})
+	if alertDetails["git_branch"] != "main" {
+		t.Errorf("Expected git_branch 'main', got %v", alertDetails["git_branch"])
+	}
+	if key := formatter.Headers()["Authorization"]; key != "GenieKey api-key" {
+		t.Errorf("Expected Authorization header 'GenieKey api-key', got %q", key)
+	}
+
+	// Long messages are cut to the Alerts API limit
+	long, _ := formatter.Format(analyzer.StatusQuestion, strings.Repeat("a", 200), "session-123", statusInfo, Details{})
+	if n := len(long.(map[string]interface{})["message"].(string)); n != opsGenieMessageLimit {
+		t.Errorf("Expected message cut to %d bytes, got %d", opsGenieMessageLimit, n)
+	}
+
+	// Multi-byte text is cut between characters, never inside one
+	wide, _ := formatter.Format(analyzer.StatusQuestion, strings.Repeat("é", opsGenieDescriptionLimit), "session-123", statusInfo, Details{})
+	wideMap := wide.(map[string]interface{})
+	for _, field := range []string{"message", "description"} {
+		text := wideMap[field].(string)
+		if !utf8.ValidString(text) {
+			t.Errorf("Expected valid UTF-8 %s, got invalid text ending %q", field, text[len(text)-8:])
+		}
+		if !strings.HasSuffix(text, "é...") {
+			t.Errorf("Expected %s cut after a whole character, got ending %q", field, text[len(text)-8:])
+		}
+	}
+	if n := utf8.RuneCountInString(wideMap["message"].(string)); n != opsGenieMessageLimit {
+		t.Errorf("Expected message cut to %d characters, got %d", opsGenieMessageLimit, n)
+	}
+	if n := utf8.RuneCountInString(wideMap["description"].(string)); n != opsGenieDescriptionLimit {
+		t.Errorf("Expected description cut to %d characters, got %d", opsGenieDescriptionLimit, n)
+	}
+}
+
+func TestOpsGenieFormatterPriority(t *testing.T) {
+	formatter := &OpsGenieFormatter{APIKey: "api-key"}
+	statusInfo := config.StatusInfo{Title: "Test"}
+
+	tests := []struct {
+		status   analyzer.Status
+		expected string
+	}{
+		{analyzer.StatusQuestion, "P1"},
+		{analyzer.StatusAPIError, "P2"},
+		{analyzer.StatusSessionLimitReached, "P3"},
+		{analyzer.StatusPlanReady, "P4"},
+		{analyzer.StatusTaskComplete, "P5"},
+		{analyzer.StatusReviewComplete, "P5"},
+		{analyzer.StatusUnknown, "P5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			result, _ := formatter.Format(tt.status, "msg", "session-"+string(tt.status), statusInfo, Details{})
+			resultMap := result.(map[string]interface{})
+
+			if resultMap["priority"] != tt.expected {
+				t.Errorf("Expected priority %q, got %v", tt.expected, resultMap["priority"])
+			}
+			if resultMap["alias"] != "session-"+string(tt.status) {
+				t.Errorf("Expected alias to equal the session ID, got %v", resultMap["alias"])
+			}
+		})
+	}
+}
+
+func TestPushoverFormatterFormat(t *testing.T) {
+	formatter := &PushoverFormatter{Token: "app-token", User: "user-key"}
+	statusInfo := config.StatusInfo{Title: "Task Complete"}
+
+	result, err := formatter.Format(analyzer.StatusTaskComplete, "All done", "session-123", statusInfo, Details{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	if resultMap["token"] != "app-token" {
+		t.Errorf("Expected token 'app-token', got %v", resultMap["token"])
+	}
+	if resultMap["user"] != "user-key" {
+		t.Errorf("Expected user 'user-key', got %v", resultMap["user"])
+	}
+	if resultMap["title"] != "Task Complete" {
+		t.Errorf("Expected title 'Task Complete', got %v", resultMap["title"])
+	}
+	if resultMap["message"] != "All done\n\nSession: session-123" {
+		t.Errorf("Expected message with session footer, got %q", resultMap["message"])
+	}
+}
+
+func TestPushoverFormatterPriorityAndSound(t *testing.T) {
+	formatter := &PushoverFormatter{Token: "app-token", User: "user-key"}
+	statusInfo := config.StatusInfo{Title: "Test"}
+
+	tests := []struct {
+		status           analyzer.Status
+		expectedPriority int
+		expectedSound    string
+	}{
+		{analyzer.StatusTaskComplete, 0, "magic"},
+		{analyzer.StatusReviewComplete, 0, "pianobar"},
+		{analyzer.StatusQuestion, 1, "echo"},
+		{analyzer.StatusPlanReady, 0, "incoming"},
+		{analyzer.StatusSessionLimitReached, 0, "falling"},
+		{analyzer.StatusAPIError, 0, "siren"},
+		{analyzer.StatusUnknown, 0, "pushover"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			result, _ := formatter.Format(tt.status, "msg", "session", statusInfo, Details{})
+			resultMap := result.(map[string]interface{})
+
+			if resultMap["priority"] != tt.expectedPriority {
+				t.Errorf("Expected priority %d, got %v", tt.expectedPriority, resultMap["priority"])
+			}
+			if resultMap["sound"] != tt.expectedSound {
+				t.Errorf("Expected sound %q, got %v", tt.expectedSound, resultMap["sound"])
+			}
+		})
+	}
+}
+
+func TestPushoverFormatterMobileSound(t *testing.T) {
+	formatter := &PushoverFormatter{Token: "app-token", User: "user-key"}
+	statusInfo := config.StatusInfo{Title: "Test", MobileSound: "vibrate"}
+
+	result, _ := formatter.Format(analyzer.StatusQuestion, "msg", "session", statusInfo, Details{})
+	if sound := result.(map[string]interface{})["sound"]; sound != "vibrate" {
+		t.Errorf("Expected configured sound 'vibrate', got %v", sound)
+	}
+}
+
+func TestGotifyFormatterFormat(t *testing.T) {
+	formatter := &GotifyFormatter{Token: "app-token"}
+	statusInfo := config.StatusInfo{Title: "Task Complete"}
+
+	result, err := formatter.Format(analyzer.StatusTaskComplete, "All done", "session-123", statusInfo, Details{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	if resultMap["title"] != "Task Complete" {
+		t.Errorf("Expected title 'Task Complete', got %v", resultMap["title"])
+	}
+	if resultMap["message"] != "All done\n\nSession: session-123" {
+		t.Errorf("Expected message with session footer, got %q", resultMap["message"])
+	}
+	if _, ok := resultMap["token"]; ok {
+		t.Error("Token must not be sent in the body")
+	}
+	if key := formatter.Headers()["X-Gotify-Key"]; key != "app-token" {
+		t.Errorf("Expected X-Gotify-Key header 'app-token', got %q", key)
+	}
+}
+
+func TestGotifyFormatterHeadersWithoutToken(t *testing.T) {
+	formatter := &GotifyFormatter{}
+	if _, ok := formatter.Headers()["X-Gotify-Key"]; ok {
+		t.Error("X-Gotify-Key header must be omitted without a token")
+	}
+}
+
+func TestGotifyFormatterPriority(t *testing.T) {
+	formatter := &GotifyFormatter{Token: "app-token"}
+	statusInfo := config.StatusInfo{Title: "Test"}
+
+	tests := []struct {
+		status   analyzer.Status
+		expected int
+	}{
+		{analyzer.StatusQuestion, 8},
+		{analyzer.StatusAPIError, 7},
+		{analyzer.StatusPlanReady, 6},
+		{analyzer.StatusSessionLimitReached, 6},
+		{analyzer.StatusTaskComplete, 4},
+		{analyzer.StatusReviewComplete, 4},
+		{analyzer.StatusUnknown, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			result, _ := formatter.Format(tt.status, "msg", "session", statusInfo, Details{})
+			resultMap := result.(map[string]interface{})
+
+			if resultMap["priority"] != tt.expected {
+				t.Errorf("Expected priority %d, got %v", tt.expected, resultMap["priority"])
+			}
+		})
+	}
+}
+
+func TestGotifyFormatterMobileSound(t *testing.T) {
+	formatter := &GotifyFormatter{Token: "app-token"}
+
+	result, _ := formatter.Format(analyzer.StatusQuestion, "msg", "session", config.StatusInfo{Title: "Test"}, Details{})
+	if _, ok := result.(map[string]interface{})["extras"]; ok {
+		t.Error("Expected no extras without a configured sound")
+	}
+
+	statusInfo := config.StatusInfo{Title: "Test", MobileSound: "alarm"}
+	result, _ = formatter.Format(analyzer.StatusQuestion, "msg", "session", statusInfo, Details{})
+	extras, ok := result.(map[string]interface{})["extras"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected extras with the configured sound")
+	}
+	notification, _ := extras["claude-notifications::notification"].(map[string]interface{})
+	if notification["sound"] != "alarm" {
+		t.Errorf("Expected sound 'alarm' in extras, got %v", extras)
+	}
+
+	// ntfy has no per-message sound, the setting is ignored
+	ntfy, _ := (&NtfyFormatter{Topic: "claude"}).Format(analyzer.StatusQuestion, "msg", "session", statusInfo, Details{})
+	if _, ok := ntfy.(map[string]interface{})["sound"]; ok {
+		t.Error("Expected ntfy payload to ignore the mobile sound")
+	}
+}
+
+func TestWeComFormatterFormat(t *testing.T) {
+	formatter := &WeComFormatter{}
+	statusInfo := config.StatusInfo{Title: "Task Complete"}
+
+	result, err := formatter.Format(analyzer.StatusTaskComplete, "All done", "session-123", statusInfo, Details{GitBranch: "main"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	if resultMap["msgtype"] != "markdown" {
+		t.Errorf("Expected msgtype 'markdown', got %v", resultMap["msgtype"])
+	}
+
+	markdown, ok := resultMap["markdown"].(map[string]interface{})
+	if !ok {
+		t.Fatal("markdown should be a map")
+	}
+	content, _ := markdown["content"].(string)
+	if !strings.HasPrefix(content, "**") || !strings.Contains(content, "Task Complete**") {
+		t.Errorf("Expected bold title in content, got %q", content)
+	}
+	if !strings.Contains(content, "All done") {
+		t.Errorf("Expected message in content, got %q", content)
+	}
+	if !strings.HasSuffix(content, "Session: session-123 | Branch: main") {
+		t.Errorf("Expected session line in content, got %q", content)
+	}
+}
+
+func TestZulipFormatterFormat(t *testing.T) {
+	formatter := &ZulipFormatter{Stream: "claude", Topic: "builds"}
+	statusInfo := config.StatusInfo{Title: "Task Complete"}
+
+	result, err := formatter.Format(analyzer.StatusTaskComplete, "All done", "session-123", statusInfo, Details{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	if resultMap["type"] != "stream" {
+		t.Errorf("Expected type 'stream', got %v", resultMap["type"])
+	}
+	if resultMap["to"] != "claude" {
+		t.Errorf("Expected to 'claude', got %v", resultMap["to"])
+	}
+	if resultMap["topic"] != "builds" {
+		t.Errorf("Expected topic 'builds', got %v", resultMap["topic"])
+	}
+	content, _ := resultMap["content"].(string)
+	if !strings.Contains(content, "**Task Complete**") {
+		t.Errorf("Expected bold title in content, got %q", content)
+	}
+	if !strings.Contains(content, "All done") {
+		t.Errorf("Expected message in content, got %q", content)
+	}
+}
+
+func TestZulipFormatterTopicDefaultsToSession(t *testing.T) {
+	formatter := &ZulipFormatter{Stream: "claude"}
+	statusInfo := config.StatusInfo{Title: "Question"}
+
+	result, _ := formatter.Format(analyzer.StatusQuestion, "Which database?", "session-123", statusInfo, Details{})
+	resultMap := result.(map[string]interface{})
+
+	if resultMap["topic"] != "session-123" {
+		t.Errorf("Expected topic to default to session ID, got %v", resultMap["topic"])
+	}
+}
+
+func TestZulipFormatterEncodeBody(t *testing.T) {
+	formatter := &ZulipFormatter{Stream: "claude", Topic: "a&b=c"}
+	statusInfo := config.StatusInfo{Title: "Task Complete"}
+
+	payload, _ := formatter.Format(analyzer.StatusTaskComplete, "Done: 100% & more", "session-123", statusInfo, Details{})
+	body, contentType, err := formatter.EncodeBody(payload)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if contentType != "application/x-www-form-urlencoded" {
+		t.Errorf("Expected form content type, got %q", contentType)
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		t.Fatalf("Body is not form-encoded: %q: %v", body, err)
+	}
+	if values.Get("type") != "stream" || values.Get("to") != "claude" || values.Get("topic") != "a&b=c" {
+		t.Errorf("Unexpected form values: %v", values)
+	}
+	if !strings.Contains(values.Get("content"), "Done: 100% & more") {
+		t.Errorf("Expected message to survive encoding, got %q", values.Get("content"))
+	}
+
+	if _, _, err := formatter.EncodeBody("not a map"); err == nil {
+		t.Error("Expected an error for a non-map payload")
+	}
+}
+
+func TestPagerDutyFormatterFormat(t *testing.T) {
+	formatter := &PagerDutyFormatter{RoutingKey: "routing-key"}
+	statusInfo := config.StatusInfo{Title: "Question"}
+
+	result, err := formatter.Format(analyzer.StatusQuestion, "Which database?", "session-123", statusInfo, Details{GitBranch: "main"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	if resultMap["routing_key"] != "routing-key" {
+		t.Errorf("Expected routing_key 'routing-key', got %v", resultMap["routing_key"])
+	}
+	if resultMap["event_action"] != "trigger" {
+		t.Errorf("Expected event_action 'trigger', got %v", resultMap["event_action"])
+	}
+	if resultMap["dedup_key"] != "claude-notifications-session-123" {
+		t.Errorf("Expected dedup_key derived from session, got %v", resultMap["dedup_key"])
+	}
+
+	payload := resultMap["payload"].(map[string]interface{})
+	if payload["summary"] != "Question: Which database?" {
+		t.Errorf("Expected summary 'Question: Which database?', got %v", payload["summary"])
+	}
+	if payload["source"] != "claude-notifications" {
+		t.Errorf("Expected source 'claude-notifications', got %v", payload["source"])
+	}
+	customDetails := payload["custom_details"].(map[string]interface{})
+	if customDetails["git_branch"] != "main" {
+		t.Errorf("Expected git_branch 'main', got %v", customDetails["git_branch"])
+	}
+
+	// Same session yields the same dedup key so PagerDuty groups events into one incident
+	other, _ := formatter.Format(analyzer.StatusTaskComplete, "Done", "session-123", statusInfo, Details{})
+	if other.(map[string]interface{})["dedup_key"] != resultMap["dedup_key"] {
+		t.Error("Expected dedup_key to be stable for a session")
+	}
+
+	// Long multi-byte summaries are cut between characters, never inside one
+	wide, _ := formatter.Format(analyzer.StatusQuestion, strings.Repeat("é", pagerDutySummaryLimit), "session-123", statusInfo, Details{})
+	summary := wide.(map[string]interface{})["payload"].(map[string]interface{})["summary"].(string)
+	if !utf8.ValidString(summary) || !strings.HasSuffix(summary, "é...") {
+		t.Errorf("Expected summary cut after a whole character, got ending %q", summary[len(summary)-8:])
+	}
+	if n := utf8.RuneCountInString(summary); n != pagerDutySummaryLimit {
+		t.Errorf("Expected summary cut to %d characters, got %d", pagerDutySummaryLimit, n)
+	}
+}
+
+func TestPagerDutyFormatterSeverity(t *testing.T) {
+	formatter := &PagerDutyFormatter{RoutingKey: "routing-key"}
+	statusInfo := config.StatusInfo{Title: "Test"}
+
+	tests := []struct {
+		status   analyzer.Status
+		expected string
+	}{
+		{analyzer.StatusQuestion, "critical"},
+		{analyzer.StatusAPIError, "error"},
+		{analyzer.StatusSessionLimitReached, "warning"},
+		{analyzer.StatusTaskComplete, "info"},
+		{analyzer.StatusReviewComplete, "info"},
+		{analyzer.StatusPlanReady, "info"},
+		{analyzer.StatusUnknown, "info"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			result, _ := formatter.Format(tt.status, "msg", "session", statusInfo, Details{})
+			payload := result.(map[string]interface{})["payload"].(map[string]interface{})
+
+			if payload["severity"] != tt.expected {
+				t.Errorf("Expected severity %q, got %v", tt.expected, payload["severity"])
+			}
+		})
+	}
+}
+
+func TestRocketChatFormatterFormat(t *testing.T) {
+	formatter := &RocketChatFormatter{}
+	statusInfo := config.StatusInfo{Title: "Task Complete"}
+
+	result, err := formatter.Format(analyzer.StatusTaskComplete, "All done", "session-123", statusInfo, Details{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	attachments, ok := resultMap["attachments"].([]map[string]interface{})
+	if !ok || len(attachments) != 1 {
+		t.Fatalf("Expected one attachment, got %v", resultMap["attachments"])
+	}
+
+	attachment := attachments[0]
+	if attachment["color"] != "#28a745" {
+		t.Errorf("Expected color #28a745, got %v", attachment["color"])
+	}
+	if attachment["title"] != "Task Complete" {
+		t.Errorf("Expected title 'Task Complete', got %v", attachment["title"])
+	}
+	if attachment["text"] != "All done" {
+		t.Errorf("Expected text 'All done', got %v", attachment["text"])
+	}
+	if _, ok := attachment["ts"].(string); !ok {
+		t.Errorf("Expected ts timestamp string, got %v", attachment["ts"])
+	}
+
+	fields := attachment["fields"].([]map[string]interface{})
+	if len(fields) != 1 || fields[0]["value"] != "session-123" {
+		t.Errorf("Expected session field, got %v", fields)
+	}
+}
+
+func TestMattermostFormatterFormat(t *testing.T) {
+	formatter := &MattermostFormatter{}
+	statusInfo := config.StatusInfo{Title: "Question"}
+
+	result, err := formatter.Format(analyzer.StatusQuestion, "Which **database**?", "session-123", statusInfo, Details{GitBranch: "main"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	if resultMap["username"] != "Claude Code" {
+		t.Errorf("Expected username 'Claude Code', got %v", resultMap["username"])
+	}
+	if resultMap["icon_url"] == "" || resultMap["icon_url"] == nil {
+		t.Error("Expected icon_url to be set")
+	}
+	if resultMap["text"] != "**Question**" {
+		t.Errorf("Expected markdown title text, got %v", resultMap["text"])
+	}
+
+	attachments, ok := resultMap["attachments"].([]map[string]interface{})
+	if !ok || len(attachments) != 1 {
+		t.Fatalf("Expected one attachment, got %v", resultMap["attachments"])
+	}
+
+	attachment := attachments[0]
+	if attachment["color"] != "#ffc107" {
+		t.Errorf("Expected color #ffc107, got %v", attachment["color"])
+	}
+	// Mattermost renders standard markdown, so it must not be converted to Slack mrkdwn
+	if attachment["text"] != "Which **database**?" {
+		t.Errorf("Expected unconverted markdown text, got %v", attachment["text"])
+	}
+
+	fields := attachment["fields"].([]map[string]interface{})
+	if len(fields) != 2 || fields[1]["value"] != "`main`" {
+		t.Errorf("Expected session and branch fields, got %v", fields)
+	}
+
+	props, ok := resultMap["props"].(map[string]interface{})
+	if !ok || !strings.Contains(props["card"].(string), "session-123") {
+		t.Errorf("Expected props card with session, got %v", resultMap["props"])
+	}
+}
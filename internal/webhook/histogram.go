@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Latency histogram layout: bucket 0 holds latencies up to 1ms, bucket i up to histogramGrowth^i ms.
+// 90 buckets growing by 15% reach about 5 minutes; slower requests land in the last bucket.
+// Percentiles interpolate within a bucket, so they are accurate to a few percent.
+const (
+	histogramGrowth  = 1.15
+	histogramBuckets = 90
+)
+
+// latencyHistogram counts latencies in fixed exponential buckets, so memory stays constant
+type latencyHistogram struct {
+	mu     sync.Mutex
+	counts [histogramBuckets]int64
+	total  int64
+	maxMs  float64
+}
+
+// LatencyPercentiles summarizes a latency distribution in milliseconds
+type LatencyPercentiles struct {
+	Count int64   `json:"count"`
+	P50Ms float64 `json:"p50_ms"`
+	P95Ms float64 `json:"p95_ms"`
+	P99Ms float64 `json:"p99_ms"`
+}
+
+// record adds a latency to the histogram
+func (h *latencyHistogram) record(latency time.Duration) {
+	ms := float64(latency) / float64(time.Millisecond)
+	if ms < 0 {
+		ms = 0
+	}
+
+	idx := 0
+	if ms > 1 {
+		idx = min(int(math.Ceil(math.Log(ms)/math.Log(histogramGrowth))), histogramBuckets-1)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[idx]++
+	h.total++
+	h.maxMs = max(h.maxMs, ms)
+}
+
+// percentiles returns p50, p95 and p99 of the recorded latencies
+func (h *latencyHistogram) percentiles() LatencyPercentiles {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return LatencyPercentiles{
+		Count: h.total,
+		P50Ms: h.quantile(0.50),
+		P95Ms: h.quantile(0.95),
+		P99Ms: h.quantile(0.99),
+	}
+}
+
+// quantile returns the latency below which fraction q of samples fall, interpolating within the bucket
+// Must be called with h.mu held
+func (h *latencyHistogram) quantile(q float64) float64 {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := q * float64(h.total)
+	var seen float64
+	for i, count := range h.counts {
+		if count == 0 {
+			continue
+		}
+		if seen+float64(count) >= rank {
+			lower := 0.0
+			if i > 0 {
+				lower = math.Pow(histogramGrowth, float64(i-1))
+			}
+			// The overflow bucket and the slowest sample cap the estimate
+			upper := math.Min(math.Pow(histogramGrowth, float64(i)), h.maxMs)
+			if i == histogramBuckets-1 {
+				upper = h.maxMs
+			}
+			if upper < lower {
+				return upper
+			}
+			return lower + (upper-lower)*(rank-seen)/float64(count)
+		}
+		seen += float64(count)
+	}
+	return h.maxMs
+}
@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Claude writes CommonMark; each chat platform understands a different dialect.
+// The converters below translate the subset that shows up in notifications:
+// code fences, code spans, bold and links.
+
+var (
+	// markdownCodeRe matches a fenced code block (group 1) or an inline code span (group 2)
+	markdownCodeRe = regexp.MustCompile("(?s)```(?:[\\w+-]*\\n)?(.*?)```|`([^`\\n]+)`")
+	markdownBoldRe = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	markdownLinkRe = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// markdownConverter renders plain text and code for one target dialect
+type markdownConverter struct {
+	text      func(s string) string
+	codeSpan  func(code string) string
+	codeBlock func(code string) string
+}
+
+// convert splits md into code and non-code parts so formatting inside code is left alone
+func (c markdownConverter) convert(md string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range markdownCodeRe.FindAllStringSubmatchIndex(md, -1) {
+		b.WriteString(c.text(md[last:m[0]]))
+		if m[2] >= 0 {
+			b.WriteString(c.codeBlock(md[m[2]:m[3]]))
+		} else {
+			b.WriteString(c.codeSpan(md[m[4]:m[5]]))
+		}
+		last = m[1]
+	}
+	b.WriteString(c.text(md[last:]))
+	return b.String()
+}
+
+// boldText returns the text of a bold match, whichever delimiter was used
+func boldText(groups []string) string {
+	if groups[1] != "" {
+		return groups[1]
+	}
+	return groups[2]
+}
+
+var telegramMarkdown = markdownConverter{
+	text: func(s string) string {
+		s = html.EscapeString(s)
+		s = markdownLinkRe.ReplaceAllString(s, `<a href="$2">$1</a>`)
+		return markdownBoldRe.ReplaceAllStringFunc(s, func(m string) string {
+			return "<b>" + boldText(markdownBoldRe.FindStringSubmatch(m)) + "</b>"
+		})
+	},
+	codeSpan: func(code string) string {
+		return "<code>" + html.EscapeString(code) + "</code>"
+	},
+	codeBlock: func(code string) string {
+		return "<pre>" + html.EscapeString(code) + "</pre>"
+	},
+}
+
+// slackEscaper escapes the three characters Slack treats as control characters
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+var slackMarkdown = markdownConverter{
+	text: func(s string) string {
+		s = slackEscaper.Replace(s)
+		s = markdownLinkRe.ReplaceAllString(s, "<$2|$1>")
+		return markdownBoldRe.ReplaceAllStringFunc(s, func(m string) string {
+			return "*" + boldText(markdownBoldRe.FindStringSubmatch(m)) + "*"
+		})
+	},
+	codeSpan: func(code string) string {
+		return "`" + slackEscaper.Replace(code) + "`"
+	},
+	codeBlock: func(code string) string {
+		return "```" + slackEscaper.Replace(code) + "```"
+	},
+}
+
+var discordMarkdown = markdownConverter{
+	text: func(s string) string {
+		// Discord renders __text__ as underline, so normalize bold to **text**
+		return markdownBoldRe.ReplaceAllStringFunc(s, func(m string) string {
+			return "**" + boldText(markdownBoldRe.FindStringSubmatch(m)) + "**"
+		})
+	},
+	codeSpan: func(code string) string {
+		return "`" + code + "`"
+	},
+	codeBlock: func(code string) string {
+		return "```\n" + code + "```"
+	},
+}
+
+// markdownToTelegramHTML converts markdown to Telegram HTML, escaping everything else
+func markdownToTelegramHTML(md string) string {
+	return telegramMarkdown.convert(md)
+}
+
+// markdownToSlack converts markdown to Slack mrkdwn
+func markdownToSlack(md string) string {
+	return slackMarkdown.convert(md)
+}
+
+// markdownToDiscord converts markdown to the Discord dialect
+func markdownToDiscord(md string) string {
+	return discordMarkdown.convert(md)
+}
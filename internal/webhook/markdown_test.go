@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestMarkdownToTelegramHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Code span", "Run `go test ./...` now", "Run <code>go test ./...</code> now"},
+		{"Code span is escaped", "Use `a < b && c`", "Use <code>a &lt; b &amp;&amp; c</code>"},
+		{"Bold", "This is **important**", "This is <b>important</b>"},
+		{"Underscore bold", "This is __important__", "This is <b>important</b>"},
+		{"Link", "See [docs](https://example.com/a?b=1&c=2)", `See <a href="https://example.com/a?b=1&amp;c=2">docs</a>`},
+		{"Code block", "Done:\n```go\nfmt.Println(\"<hi>\")\n```", "Done:\n<pre>fmt.Println(&#34;&lt;hi&gt;&#34;)\n</pre>"},
+		{"Bold inside code is literal", "`**not bold**`", "<code>**not bold**</code>"},
+		{"Plain text is escaped", "if x < 3 & y > 2", "if x &lt; 3 &amp; y &gt; 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdownToTelegramHTML(tt.input); got != tt.expected {
+				t.Errorf("markdownToTelegramHTML(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMarkdownToSlack(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Code span", "Run `go test ./...` now", "Run `go test ./...` now"},
+		{"Code span is escaped", "Use `a < b`", "Use `a &lt; b`"},
+		{"Bold", "This is **important**", "This is *important*"},
+		{"Link", "See [docs](https://example.com)", "See <https://example.com|docs>"},
+		{"Code block", "```\nx := 1\n```", "```x := 1\n```"},
+		{"Bold inside code is literal", "`**not bold**`", "`**not bold**`"},
+		{"Plain text is escaped", "a & b <c>", "a &amp; b &lt;c&gt;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := markdownToSlack(tt.input); got != tt.expected {
+				t.Errorf("markdownToSlack(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMarkdownToDiscord(t *testing.T) {
+	if got := markdownToDiscord("__bold__ and `__code__`"); got != "**bold** and `__code__`" {
+		t.Errorf("Expected underscore bold to be normalized outside code, got %q", got)
+	}
+	if got := markdownToDiscord("See [docs](https://example.com)"); got != "See [docs](https://example.com)" {
+		t.Errorf("Expected links to pass through, got %q", got)
+	}
+}
+
+func TestFormattersConvertMarkdown(t *testing.T) {
+	statusInfo := config.StatusInfo{Title: "Task Complete"}
+	message := "Updated `main.go`"
+
+	telegram, _ := (&TelegramFormatter{ChatID: "1"}).Format(analyzer.StatusTaskComplete, message, "session-123", statusInfo, Details{})
+	if text := telegram.(map[string]interface{})["text"].(string); !strings.Contains(text, "Updated <code>main.go</code>") {
+		t.Errorf("Expected Telegram code span as <code>, got %q", text)
+	}
+
+	slack, _ := (&SlackFormatter{}).Format(analyzer.StatusTaskComplete, "Updated **main.go**", "session-123", statusInfo, Details{})
+	attachment := slack.(map[string]interface{})["attachments"].([]map[string]interface{})[0]
+	if attachment["text"] != "Updated *main.go*" {
+		t.Errorf("Expected Slack bold as *text*, got %q", attachment["text"])
+	}
+}
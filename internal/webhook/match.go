@@ -0,0 +1,91 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// successMatcher checks that a 2xx response body reports logical success
+// e.g. Telegram returns 200 with {"ok": false, ...} when a message is rejected
+type successMatcher struct {
+	path   []string
+	equals string
+	re     *regexp.Regexp
+}
+
+// newSuccessMatcher creates a matcher from config
+// Returns nil if no checks are configured
+func newSuccessMatcher(cfg config.SuccessMatchConfig) (*successMatcher, error) {
+	if cfg.JSONPath == "" && cfg.Regex == "" {
+		return nil, nil
+	}
+
+	m := &successMatcher{equals: cfg.Equals}
+	if cfg.JSONPath != "" {
+		m.path = strings.Split(cfg.JSONPath, ".")
+	}
+	if cfg.Regex != "" {
+		re, err := regexp.Compile(cfg.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid successMatch regex: %w", err)
+		}
+		m.re = re
+	}
+	return m, nil
+}
+
+// Match reports whether body satisfies every configured check
+func (m *successMatcher) Match(body []byte) bool {
+	if m.re != nil && !m.re.Match(body) {
+		return false
+	}
+
+	if len(m.path) > 0 {
+		value, ok := lookupJSONPath(body, m.path)
+		if !ok || fmt.Sprint(value) != m.equals {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lookupJSONPath returns the value at path in a JSON body
+// Numbers are returned as json.Number, so large IDs print exactly
+func lookupJSONPath(body []byte, path []string) (interface{}, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, false
+	}
+	for _, key := range path {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// ResponseMismatchError is returned when a 2xx response body fails the success matcher
+type ResponseMismatchError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ResponseMismatchError) Error() string {
+	body := e.Body
+	if len(body) > 200 {
+		body = body[:200] + "..."
+	}
+	return fmt.Sprintf("HTTP %d response did not match success criteria: %s", e.StatusCode, body)
+}
@@ -0,0 +1,50 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestNewSuccessMatcherDisabled(t *testing.T) {
+	m, err := newSuccessMatcher(config.SuccessMatchConfig{})
+	if err != nil || m != nil {
+		t.Errorf("Expected nil matcher without config, got %v, %v", m, err)
+	}
+
+	if _, err := newSuccessMatcher(config.SuccessMatchConfig{Regex: "(unclosed"}); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+}
+
+func TestSuccessMatcherMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.SuccessMatchConfig
+		body     string
+		expected bool
+	}{
+		{"bool true", config.SuccessMatchConfig{JSONPath: "ok", Equals: "true"}, `{"ok":true,"result":{}}`, true},
+		{"bool false", config.SuccessMatchConfig{JSONPath: "ok", Equals: "true"}, `{"ok":false,"description":"Bad Request"}`, false},
+		{"nested string", config.SuccessMatchConfig{JSONPath: "result.status", Equals: "sent"}, `{"result":{"status":"sent"}}`, true},
+		{"number", config.SuccessMatchConfig{JSONPath: "code", Equals: "0"}, `{"code":0}`, true},
+		{"missing key", config.SuccessMatchConfig{JSONPath: "result.status", Equals: "sent"}, `{"result":{}}`, false},
+		{"path through non-object", config.SuccessMatchConfig{JSONPath: "ok.value", Equals: "true"}, `{"ok":true}`, false},
+		{"non-JSON body", config.SuccessMatchConfig{JSONPath: "ok", Equals: "true"}, `ok`, false},
+		{"regex match", config.SuccessMatchConfig{Regex: `^ok$`}, `ok`, true},
+		{"regex mismatch", config.SuccessMatchConfig{Regex: `^ok$`}, `error: invalid token`, false},
+		{"both must match", config.SuccessMatchConfig{JSONPath: "ok", Equals: "true", Regex: `warning`}, `{"ok":true}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newSuccessMatcher(tt.cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := m.Match([]byte(tt.body)); got != tt.expected {
+				t.Errorf("Match(%s) = %v, want %v", tt.body, got, tt.expected)
+			}
+		})
+	}
+}
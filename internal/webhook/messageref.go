@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/777genius/claude-notifications/internal/logging"
+)
+
+// MessageRefStore remembers the ID an endpoint returned for a session's last notification
+// state.Manager implements it, so the reference survives across hook processes
+type MessageRefStore interface {
+	LastMessageRef(sessionID string) (destination, ref string, err error)
+	SetLastMessageRef(sessionID, destination, ref string) error
+}
+
+// WithMessageRefStore records message IDs for destinations configured with messageRefPath
+func WithMessageRefStore(store MessageRefStore) Option {
+	return func(s *Sender) {
+		s.messageRefs = store
+	}
+}
+
+// extractMessageRef returns the value at the destination's messageRefPath in a response body
+// Returns empty string if no path is configured or the body has no value there
+func extractMessageRef(dest destination, body []byte) string {
+	if dest.MessageRefPath == "" {
+		return ""
+	}
+	value, ok := lookupJSONPath(body, strings.Split(dest.MessageRefPath, "."))
+	if !ok || value == nil {
+		return ""
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// rememberMessageRef stores the message ID from a successful response as the session's last message
+func (s *Sender) rememberMessageRef(dest destination, sessionID string, body []byte) {
+	if s.messageRefs == nil || dest.MessageRefPath == "" {
+		return
+	}
+
+	ref := extractMessageRef(dest, body)
+	if ref == "" {
+		logging.Warn("No message ID at %s in response from %s", dest.MessageRefPath, dest.Name)
+		return
+	}
+	if err := s.messageRefs.SetLastMessageRef(sessionID, dest.Name, ref); err != nil {
+		logging.Warn("Failed to save message ID for session %s: %v", sessionID, err)
+	}
+}
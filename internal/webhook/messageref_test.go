@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/state"
+)
+
+func TestExtractMessageRef(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+		want string
+	}{
+		{"telegram", "result.message_id", `{"ok": true, "result": {"message_id": 4242}}`, "4242"},
+		{"discord snowflake", "id", `{"id": "1234567890123456789", "channel_id": "42"}`, "1234567890123456789"},
+		{"large number kept exact", "id", `{"id": 1234567890123456789}`, "1234567890123456789"},
+		{"no path", "", `{"id": "1"}`, ""},
+		{"missing field", "result.message_id", `{"ok": true}`, ""},
+		{"object value", "result", `{"result": {"message_id": 1}}`, ""},
+		{"null value", "id", `{"id": null}`, ""},
+		{"not JSON", "id", `ok`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := destination{WebhookDestination: config.WebhookDestination{MessageRefPath: tt.path}}
+			if got := extractMessageRef(dest, []byte(tt.body)); got != tt.want {
+				t.Errorf("extractMessageRef(%q, %s) = %q, want %q", tt.path, tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSenderStoresMessageRef(t *testing.T) {
+	var messageID atomic.Int32
+	messageID.Store(100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"ok": true, "result": {"message_id": %d, "chat": {"id": 1}}}`, messageID.Add(1))
+	}))
+	defer server.Close()
+
+	store := state.NewManagerWithStore(state.NewMemoryStore())
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "telegram"
+	cfg.Notifications.Webhook.ChatID = "1"
+	cfg.Notifications.Webhook.MessageRefPath = "result.message_id"
+	sender := New(cfg, WithMessageRefStore(store))
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-1"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	sessionState, err := store.Load("session-1")
+	if err != nil || sessionState == nil {
+		t.Fatalf("Expected session state, got %v, %v", sessionState, err)
+	}
+	if sessionState.LastMessageRef != "101" || sessionState.LastMessageDestination != "default" {
+		t.Errorf("Expected ref 101 from default, got %q from %q", sessionState.LastMessageRef, sessionState.LastMessageDestination)
+	}
+
+	// The latest notification replaces the reference
+	if err := sender.Send(analyzer.StatusQuestion, "Which one?", "session-1"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if dest, ref, _ := store.LastMessageRef("session-1"); ref != "102" || dest != "default" {
+		t.Errorf("Expected ref 102 from default, got %q from %q", ref, dest)
+	}
+
+	// Without messageRefPath nothing is recorded
+	cfg.Notifications.Webhook.MessageRefPath = ""
+	if err := New(cfg, WithMessageRefStore(store)).Send(analyzer.StatusTaskComplete, "Done", "session-2"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if _, ref, _ := store.LastMessageRef("session-2"); ref != "" {
+		t.Errorf("Expected no ref without messageRefPath, got %q", ref)
+	}
+}
@@ -15,28 +15,51 @@ type Metrics struct {
 	successfulRequests  atomic.Int64
 	failedRequests      atomic.Int64
 	retriedRequests     atomic.Int64
+	retryBackoff        atomic.Int64 // in milliseconds
 	rateLimitedRequests atomic.Int64
 	circuitOpenRequests atomic.Int64
+	fallbackDeliveries  atomic.Int64
+	formatFailures      atomic.Int64
 
 	// Status-based counters
 	statusCounters map[analyzer.Status]*atomic.Int64
 	mu             sync.RWMutex
 
+	// Sends by the number of retries they needed, sends without retries are not counted
+	retryCounts map[int]int64
+
+	// Destination-based counters
+	destinationCounters map[string]*destinationCounter
+
 	// Latency tracking
-	totalLatency atomic.Int64 // in milliseconds
-	requestCount atomic.Int64 // for average calculation
+	totalLatency       atomic.Int64 // in milliseconds
+	requestCount       atomic.Int64 // for average calculation
+	statusLatency      map[analyzer.Status]*latencyHistogram
+	destinationLatency map[string]*latencyHistogram
 
 	// Circuit breaker state
-	circuitBreakerState atomic.Int32 // 0=closed, 1=open, 2=half-open
+	circuitBreakerState atomic.Int32 // 0=closed, 1=open, 2=half-open, the worst across destinations
+	destinationStates   map[string]CircuitBreakerState
 }
 
 // NewMetrics creates a new metrics tracker
 func NewMetrics() *Metrics {
 	return &Metrics{
-		statusCounters: make(map[analyzer.Status]*atomic.Int64),
+		statusCounters:      make(map[analyzer.Status]*atomic.Int64),
+		retryCounts:         make(map[int]int64),
+		destinationCounters: make(map[string]*destinationCounter),
+		statusLatency:       make(map[analyzer.Status]*latencyHistogram),
+		destinationLatency:  make(map[string]*latencyHistogram),
+		destinationStates:   make(map[string]CircuitBreakerState),
 	}
 }
 
+// destinationCounter tracks delivery outcomes for a single destination
+type destinationCounter struct {
+	successes atomic.Int64
+	failures  atomic.Int64
+}
+
 // RecordRequest records a webhook request attempt
 func (m *Metrics) RecordRequest() {
 	m.totalRequests.Add(1)
@@ -47,6 +70,7 @@ func (m *Metrics) RecordSuccess(status analyzer.Status, latency time.Duration) {
 	m.successfulRequests.Add(1)
 	m.recordLatency(latency)
 	m.incrementStatusCounter(status)
+	m.getStatusHistogram(status).record(latency)
 }
 
 // RecordFailure records a failed webhook delivery
@@ -59,6 +83,16 @@ func (m *Metrics) RecordRetry() {
 	m.retriedRequests.Add(1)
 }
 
+// RecordRetries records a send that needed retries, and the time it spent backing off between attempts
+func (m *Metrics) RecordRetries(retries int, backoff time.Duration) {
+	m.retriedRequests.Add(int64(retries))
+	m.retryBackoff.Add(backoff.Milliseconds())
+
+	m.mu.Lock()
+	m.retryCounts[retries]++
+	m.mu.Unlock()
+}
+
 // RecordRateLimited records a rate-limited request
 func (m *Metrics) RecordRateLimited() {
 	m.rateLimitedRequests.Add(1)
@@ -69,6 +103,16 @@ func (m *Metrics) RecordCircuitOpen() {
 	m.circuitOpenRequests.Add(1)
 }
 
+// RecordFallbackDelivery records a notification delivered by a fallback destination
+func (m *Metrics) RecordFallbackDelivery() {
+	m.fallbackDeliveries.Add(1)
+}
+
+// RecordFormatFailure records a delivery that failed because its formatter errored or panicked
+func (m *Metrics) RecordFormatFailure() {
+	m.formatFailures.Add(1)
+}
+
 // recordLatency records request latency
 func (m *Metrics) recordLatency(latency time.Duration) {
 	m.totalLatency.Add(latency.Milliseconds())
@@ -88,11 +132,68 @@ func (m *Metrics) incrementStatusCounter(status analyzer.Status) {
 	counter.Add(1)
 }
 
+// RecordDestinationSuccess records a successful delivery to a destination
+func (m *Metrics) RecordDestinationSuccess(name string, latency time.Duration) {
+	m.getDestinationCounter(name).successes.Add(1)
+	m.getDestinationHistogram(name).record(latency)
+}
+
+// RecordDestinationFailure records a failed delivery to a destination
+func (m *Metrics) RecordDestinationFailure(name string) {
+	m.getDestinationCounter(name).failures.Add(1)
+}
+
+// getDestinationCounter returns the counter for a destination, creating it if needed
+func (m *Metrics) getDestinationCounter(name string) *destinationCounter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counter, exists := m.destinationCounters[name]
+	if !exists {
+		counter = &destinationCounter{}
+		m.destinationCounters[name] = counter
+	}
+	return counter
+}
+
+// getStatusHistogram returns the latency histogram for a status, creating it if needed
+func (m *Metrics) getStatusHistogram(status analyzer.Status) *latencyHistogram {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, exists := m.statusLatency[status]
+	if !exists {
+		h = &latencyHistogram{}
+		m.statusLatency[status] = h
+	}
+	return h
+}
+
+// getDestinationHistogram returns the latency histogram for a destination, creating it if needed
+func (m *Metrics) getDestinationHistogram(name string) *latencyHistogram {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, exists := m.destinationLatency[name]
+	if !exists {
+		h = &latencyHistogram{}
+		m.destinationLatency[name] = h
+	}
+	return h
+}
+
 // UpdateCircuitBreakerState updates the circuit breaker state
 func (m *Metrics) UpdateCircuitBreakerState(state CircuitBreakerState) {
 	m.circuitBreakerState.Store(int32(state))
 }
 
+// UpdateDestinationCircuitBreakerState updates the circuit breaker state of a destination
+func (m *Metrics) UpdateDestinationCircuitBreakerState(name string, state CircuitBreakerState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.destinationStates[name] = state
+}
+
 // GetStats returns current statistics
 func (m *Metrics) GetStats() Stats {
 	m.mu.RLock()
@@ -100,6 +201,29 @@ func (m *Metrics) GetStats() Stats {
 	for status, counter := range m.statusCounters {
 		statusCounts[status] = counter.Load()
 	}
+	retryCounts := make(map[int]int64, len(m.retryCounts))
+	for retries, sends := range m.retryCounts {
+		retryCounts[retries] = sends
+	}
+	destinationStats := make(map[string]DestinationStats)
+	for name, counter := range m.destinationCounters {
+		destinationStats[name] = DestinationStats{
+			SuccessfulRequests: counter.successes.Load(),
+			FailedRequests:     counter.failures.Load(),
+		}
+	}
+	statusLatency := make(map[analyzer.Status]LatencyPercentiles)
+	for status, h := range m.statusLatency {
+		statusLatency[status] = h.percentiles()
+	}
+	destinationLatency := make(map[string]LatencyPercentiles)
+	for name, h := range m.destinationLatency {
+		destinationLatency[name] = h.percentiles()
+	}
+	destinationStates := make(map[string]CircuitBreakerState, len(m.destinationStates))
+	for name, state := range m.destinationStates {
+		destinationStates[name] = state
+	}
 	m.mu.RUnlock()
 
 	requestCount := m.requestCount.Load()
@@ -113,42 +237,72 @@ func (m *Metrics) GetStats() Stats {
 		SuccessfulRequests:  m.successfulRequests.Load(),
 		FailedRequests:      m.failedRequests.Load(),
 		RetriedRequests:     m.retriedRequests.Load(),
+		RetryCounts:         retryCounts,
+		RetryBackoffMs:      m.retryBackoff.Load(),
 		RateLimitedRequests: m.rateLimitedRequests.Load(),
 		CircuitOpenRequests: m.circuitOpenRequests.Load(),
+		FallbackDeliveries:  m.fallbackDeliveries.Load(),
+		FormatFailures:      m.formatFailures.Load(),
 		StatusCounts:        statusCounts,
+		DestinationStats:    destinationStats,
 		AverageLatencyMs:    avgLatency,
+		StatusLatency:       statusLatency,
+		DestinationLatency:  destinationLatency,
 		CircuitBreakerState: CircuitBreakerState(m.circuitBreakerState.Load()),
+		BreakerStates:       destinationStates,
 	}
 }
 
-// Reset resets all metrics (useful for testing)
+// Reset zeroes all counters and drops the per-status and per-destination breakdowns
 func (m *Metrics) Reset() {
 	m.totalRequests.Store(0)
 	m.successfulRequests.Store(0)
 	m.failedRequests.Store(0)
 	m.retriedRequests.Store(0)
+	m.retryBackoff.Store(0)
 	m.rateLimitedRequests.Store(0)
 	m.circuitOpenRequests.Store(0)
+	m.fallbackDeliveries.Store(0)
+	m.formatFailures.Store(0)
 	m.totalLatency.Store(0)
 	m.requestCount.Store(0)
 	m.circuitBreakerState.Store(0)
 
 	m.mu.Lock()
 	m.statusCounters = make(map[analyzer.Status]*atomic.Int64)
+	m.retryCounts = make(map[int]int64)
+	m.destinationCounters = make(map[string]*destinationCounter)
+	m.statusLatency = make(map[analyzer.Status]*latencyHistogram)
+	m.destinationLatency = make(map[string]*latencyHistogram)
+	m.destinationStates = make(map[string]CircuitBreakerState)
 	m.mu.Unlock()
 }
 
 // Stats represents a snapshot of metrics
 type Stats struct {
-	TotalRequests       int64
-	SuccessfulRequests  int64
-	FailedRequests      int64
-	RetriedRequests     int64
-	RateLimitedRequests int64
-	CircuitOpenRequests int64
-	StatusCounts        map[analyzer.Status]int64
-	AverageLatencyMs    int64
-	CircuitBreakerState CircuitBreakerState
+	TotalRequests       int64                                  `json:"total_requests"`
+	SuccessfulRequests  int64                                  `json:"successful_requests"`
+	FailedRequests      int64                                  `json:"failed_requests"`
+	RetriedRequests     int64                                  `json:"retried_requests"`
+	RetryCounts         map[int]int64                          `json:"retry_counts"`     // sends by the number of retries they needed
+	RetryBackoffMs      int64                                  `json:"retry_backoff_ms"` // total time spent waiting between attempts
+	RateLimitedRequests int64                                  `json:"rate_limited_requests"`
+	CircuitOpenRequests int64                                  `json:"circuit_open_requests"`
+	FallbackDeliveries  int64                                  `json:"fallback_deliveries"` // notifications delivered by a fallback after the routed destinations failed
+	FormatFailures      int64                                  `json:"format_failures"`     // failed deliveries whose formatter errored or panicked, also counted in FailedRequests
+	StatusCounts        map[analyzer.Status]int64              `json:"status_counts"`
+	DestinationStats    map[string]DestinationStats            `json:"destination_stats"`
+	AverageLatencyMs    int64                                  `json:"average_latency_ms"`
+	StatusLatency       map[analyzer.Status]LatencyPercentiles `json:"status_latency"`         // successful delivery latency by status
+	DestinationLatency  map[string]LatencyPercentiles          `json:"destination_latency"`    // successful delivery latency by destination
+	CircuitBreakerState CircuitBreakerState                    `json:"circuit_breaker_state"`  // worst state across destinations: open, half-open, closed
+	BreakerStates       map[string]CircuitBreakerState         `json:"circuit_breaker_states"` // circuit breaker state by destination
+}
+
+// DestinationStats represents delivery counts for a single destination
+type DestinationStats struct {
+	SuccessfulRequests int64 `json:"successful_requests"`
+	FailedRequests     int64 `json:"failed_requests"`
 }
 
 // SuccessRate returns the success rate as a percentage
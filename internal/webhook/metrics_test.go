@@ -1,7 +1,14 @@
 package webhook
 
 import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -341,3 +348,184 @@ func TestMetricsLargeLatencyValues(t *testing.T) {
 		t.Errorf("Expected average latency %d ms, got %d ms", expectedAvg, stats.AverageLatencyMs)
 	}
 }
+
+func TestMetricsDestinationCounters(t *testing.T) {
+	m := NewMetrics()
+
+	m.RecordDestinationSuccess("slack", 10*time.Millisecond)
+	m.RecordDestinationSuccess("slack", 10*time.Millisecond)
+	m.RecordDestinationFailure("telegram")
+
+	stats := m.GetStats()
+	if stats.DestinationStats["slack"].SuccessfulRequests != 2 {
+		t.Errorf("Expected 2 successes for slack, got %d", stats.DestinationStats["slack"].SuccessfulRequests)
+	}
+	if stats.DestinationStats["telegram"].FailedRequests != 1 {
+		t.Errorf("Expected 1 failure for telegram, got %d", stats.DestinationStats["telegram"].FailedRequests)
+	}
+
+	m.Reset()
+	if len(m.GetStats().DestinationStats) != 0 {
+		t.Error("Expected destination stats to be cleared after reset")
+	}
+}
+
+func TestMetricsLatencyPercentiles(t *testing.T) {
+	m := NewMetrics()
+
+	// 1ms..1000ms uniformly for task_complete via slack, a constant 40ms for question via teams
+	for i := 1; i <= 1000; i++ {
+		latency := time.Duration(i) * time.Millisecond
+		m.RecordSuccess(analyzer.StatusTaskComplete, latency)
+		m.RecordDestinationSuccess("slack", latency)
+	}
+	for i := 0; i < 100; i++ {
+		m.RecordSuccess(analyzer.StatusQuestion, 40*time.Millisecond)
+		m.RecordDestinationSuccess("teams", 40*time.Millisecond)
+	}
+
+	within := func(name string, got, want float64) {
+		t.Helper()
+		if math.Abs(got-want) > want*0.08 {
+			t.Errorf("Expected %s ≈ %.0fms, got %.1fms", name, want, got)
+		}
+	}
+
+	stats := m.GetStats()
+	for name, p := range map[string]LatencyPercentiles{
+		"status":      stats.StatusLatency[analyzer.StatusTaskComplete],
+		"destination": stats.DestinationLatency["slack"],
+	} {
+		if p.Count != 1000 {
+			t.Errorf("Expected 1000 %s samples, got %d", name, p.Count)
+		}
+		within(name+" p50", p.P50Ms, 500)
+		within(name+" p95", p.P95Ms, 950)
+		within(name+" p99", p.P99Ms, 990)
+	}
+
+	question := stats.StatusLatency[analyzer.StatusQuestion]
+	within("question p50", question.P50Ms, 40)
+	within("question p99", question.P99Ms, 40)
+	if teams := stats.DestinationLatency["teams"]; teams.P99Ms > 40 {
+		t.Errorf("Expected percentiles capped by the slowest sample, got %.1fms", teams.P99Ms)
+	}
+
+	m.Reset()
+	if stats := m.GetStats(); len(stats.StatusLatency) != 0 || len(stats.DestinationLatency) != 0 {
+		t.Error("Expected latency percentiles to be cleared after reset")
+	}
+}
+
+func TestLatencyHistogramOverflow(t *testing.T) {
+	var h latencyHistogram
+	h.record(time.Hour)
+	h.record(-time.Second)
+
+	p := h.percentiles()
+	hour := float64(time.Hour / time.Millisecond)
+	if p.P99Ms < hour/2 || p.P99Ms > hour {
+		t.Errorf("Expected overflow bucket to extend to the slowest sample, got %.0fms", p.P99Ms)
+	}
+	if p.P50Ms > 1 {
+		t.Errorf("Expected negative latency to land in the first bucket, got %.1fms", p.P50Ms)
+	}
+}
+
+func TestStatsJSONRoundTrip(t *testing.T) {
+	m := NewMetrics()
+	m.RecordRequest()
+	m.RecordRequest()
+	m.RecordSuccess(analyzer.StatusQuestion, 120*time.Millisecond)
+	m.RecordDestinationSuccess("chat", 120*time.Millisecond)
+	m.RecordFailure()
+	m.RecordDestinationFailure("pager")
+	m.RecordRetries(2, 30*time.Millisecond)
+	m.UpdateCircuitBreakerState(StateHalfOpen)
+
+	stats := m.GetStats()
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"circuit_breaker_state":"half-open"`) {
+		t.Errorf("Expected the circuit breaker state by name, got %s", data)
+	}
+
+	var decoded Stats
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, stats) {
+		t.Errorf("Expected stats to round-trip\nwant %+v\ngot  %+v", stats, decoded)
+	}
+}
+
+func TestSenderRecordsRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.CircuitBreaker.Enabled = false
+	sender := New(cfg)
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	stats := sender.GetMetrics()
+	if stats.RetriedRequests != 2 {
+		t.Errorf("Expected 2 retries, got %d", stats.RetriedRequests)
+	}
+	if len(stats.RetryCounts) != 1 || stats.RetryCounts[2] != 1 {
+		t.Errorf("Expected one send needing 2 retries, got %v", stats.RetryCounts)
+	}
+	// Backoff starts at 10ms with equal jitter, so two waits take at least 5ms + 10ms
+	if stats.RetryBackoffMs < 15 {
+		t.Errorf("Expected at least 15ms of backoff, got %dms", stats.RetryBackoffMs)
+	}
+
+	// A send that succeeds first time adds no retries
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done again", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if stats := sender.GetMetrics(); stats.RetriedRequests != 2 || len(stats.RetryCounts) != 1 {
+		t.Errorf("Expected no retries for a first-time success, got %d (%v)", stats.RetriedRequests, stats.RetryCounts)
+	}
+}
+
+func TestSenderDumpAndResetMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := New(newTestConfig(server.URL))
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	data, err := sender.DumpMetricsJSON()
+	if err != nil {
+		t.Fatalf("DumpMetricsJSON failed: %v", err)
+	}
+	var dumped Stats
+	if err := json.Unmarshal(data, &dumped); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if dumped.SuccessfulRequests != 1 || dumped.StatusCounts[analyzer.StatusTaskComplete] != 1 {
+		t.Errorf("Expected the dump to include the send, got %+v", dumped)
+	}
+
+	sender.ResetMetrics()
+	stats := sender.GetMetrics()
+	if stats.TotalRequests != 0 || stats.SuccessfulRequests != 0 || len(stats.StatusCounts) != 0 || len(stats.DestinationStats) != 0 {
+		t.Errorf("Expected reset to zero the counters, got %+v", stats)
+	}
+}
@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"net/http"
+)
+
+// Option customizes a Sender at construction
+type Option func(*Sender)
+
+// WithHTTPClient makes the sender use client for every request
+// The client replaces the one built from config, so the configured timeout, proxy and TLS settings are not applied
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Sender) {
+		if client != nil {
+			s.client = client
+		}
+	}
+}
+
+// WithTransport makes the sender send requests through rt, keeping the configured timeout
+// The configured proxy and TLS settings are not applied, as they belong to the default transport
+// Useful for fake transports in tests and for instrumented transports
+func WithTransport(rt http.RoundTripper) Option {
+	return func(s *Sender) {
+		if rt != nil {
+			s.client = &http.Client{Timeout: s.client.Timeout, Transport: rt}
+		}
+	}
+}
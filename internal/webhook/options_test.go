@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+)
+
+// fakeTransport answers requests with the queued status codes, repeating the last one
+type fakeTransport struct {
+	statuses []int
+	calls    atomic.Int32
+}
+
+func (t *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	n := int(t.calls.Add(1))
+	status := t.statuses[min(n, len(t.statuses))-1]
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(http.StatusText(status))),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+func TestSenderWithTransportCountsRetries(t *testing.T) {
+	transport := &fakeTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}}
+	// The URL is never dialed, the fake transport answers every request
+	sender := New(newTestConfig("https://hooks.example.com/webhook"), WithTransport(transport))
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "msg", "session-1"); err != nil {
+		t.Fatalf("Expected send to succeed on the third attempt, got %v", err)
+	}
+	if calls := transport.calls.Load(); calls != 3 {
+		t.Errorf("Expected 3 requests across retries, got %d", calls)
+	}
+
+	stats := sender.GetMetrics()
+	if stats.SuccessfulRequests != 1 || stats.FailedRequests != 0 {
+		t.Errorf("Expected 1 successful delivery, got %+v", stats)
+	}
+	if sender.client.Timeout != defaultHTTPTimeout {
+		t.Errorf("Expected configured timeout to be kept, got %v", sender.client.Timeout)
+	}
+}
+
+func TestSenderWithHTTPClient(t *testing.T) {
+	transport := &fakeTransport{statuses: []int{http.StatusOK}}
+	client := &http.Client{Transport: transport, Timeout: time.Second}
+	sender := New(newTestConfig("https://hooks.example.com/webhook"), WithHTTPClient(client))
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "msg", "session-1"); err != nil {
+		t.Fatalf("Expected send to succeed, got %v", err)
+	}
+	if sender.client != client {
+		t.Error("Expected the injected client to be used")
+	}
+	if calls := transport.calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 request, got %d", calls)
+	}
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/logging"
+)
+
+// PayloadOverflowFail rejects notifications whose payload exceeds the limit instead of truncating
+const PayloadOverflowFail = "fail"
+
+// truncationMarker ends a message that was shortened to fit the payload limit
+const truncationMarker = "… [truncated]"
+
+// maxTruncationAttempts bounds how often the payload is rebuilt while shrinking the message
+const maxTruncationAttempts = 8
+
+// PayloadTooLargeError is returned when a payload does not fit payloadLimit.maxBytes
+type PayloadTooLargeError struct {
+	Size  int
+	Limit int
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("payload is %d bytes, exceeds the %d byte limit", e.Size, e.Limit)
+}
+
+// buildPayload builds the webhook payload and enforces payloadLimit
+// Oversized payloads are rebuilt with a shortened message, or rejected when overflow is "fail"
+func (s *Sender) buildPayload(dest destination, status analyzer.Status, message, sessionID string, details Details) ([]byte, string, error) {
+	data, contentType, err := s.formatPayload(dest, status, message, sessionID, details)
+	limit := s.cfg.Notifications.Webhook.PayloadLimit
+	if err != nil || limit.MaxBytes <= 0 || len(data) <= limit.MaxBytes {
+		return data, contentType, err
+	}
+
+	if limit.Overflow == PayloadOverflowFail {
+		return nil, "", &PayloadTooLargeError{Size: len(data), Limit: limit.MaxBytes}
+	}
+
+	// Shrink the message by the overshoot until the payload fits; escaping and
+	// formatting can make the payload grow faster than the message, hence the loop
+	budget := len(message) - (len(data) - limit.MaxBytes)
+	for attempt := 0; attempt < maxTruncationAttempts && budget > 0; attempt++ {
+		data, contentType, err = s.formatPayload(dest, status, truncateBytes(message, budget), sessionID, details)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(data) <= limit.MaxBytes {
+			logging.Warn("Webhook message to %s truncated to fit the %d byte payload limit", dest.Name, limit.MaxBytes)
+			return data, contentType, nil
+		}
+		budget -= len(data) - limit.MaxBytes
+	}
+
+	return nil, "", fmt.Errorf("message cannot be truncated to fit: %w", &PayloadTooLargeError{Size: len(data), Limit: limit.MaxBytes})
+}
+
+// truncateBytes shortens s to at most limit bytes including truncationMarker, cutting on a rune boundary
+func truncateBytes(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit - len(truncationMarker)
+	if cut <= 0 {
+		return ""
+	}
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + truncationMarker
+}
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestSenderPayloadLimitTruncates(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.PayloadLimit = config.PayloadLimitConfig{MaxBytes: 300}
+	sender := New(cfg)
+
+	message := strings.Repeat(`Refactored "auth" module. `, 50)
+	if err := sender.Send(analyzer.StatusTaskComplete, message, "session-123"); err != nil {
+		t.Fatalf("Expected truncated send to succeed, got %v", err)
+	}
+
+	if len(body) > 300 {
+		t.Errorf("Expected payload within 300 bytes, got %d", len(body))
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	msg, _ := payload["message"].(string)
+	if !strings.HasPrefix(msg, "Refactored") || !strings.HasSuffix(msg, truncationMarker) {
+		t.Errorf("Expected truncated message with marker, got %q", msg)
+	}
+}
+
+func TestSenderPayloadLimitFail(t *testing.T) {
+	requests := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.PayloadLimit = config.PayloadLimitConfig{MaxBytes: 100, Overflow: PayloadOverflowFail}
+	sender := New(cfg)
+
+	err := sender.Send(analyzer.StatusTaskComplete, strings.Repeat("x", 500), "session-123")
+	var tooLarge *PayloadTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("Expected PayloadTooLargeError, got %v", err)
+	}
+	if tooLarge.Limit != 100 || tooLarge.Size <= 100 {
+		t.Errorf("Expected size over limit 100, got %+v", tooLarge)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no request for an oversized payload, got %d", requests.Load())
+	}
+}
+
+func TestSenderPayloadLimitCannotFit(t *testing.T) {
+	cfg := newTestConfig("https://example.com/webhook")
+	// Smaller than the payload envelope itself
+	cfg.Notifications.Webhook.PayloadLimit = config.PayloadLimitConfig{MaxBytes: 10}
+	sender := New(cfg)
+
+	_, _, err := sender.buildPayload(sender.destinations[0], analyzer.StatusTaskComplete, "hello", "session-123", Details{})
+	var tooLarge *PayloadTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Errorf("Expected PayloadTooLargeError, got %v", err)
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	if got := truncateBytes("short", 100); got != "short" {
+		t.Errorf("Expected message under the limit unchanged, got %q", got)
+	}
+
+	got := truncateBytes(strings.Repeat("é", 50), 30)
+	if len(got) > 30 {
+		t.Errorf("Expected at most 30 bytes, got %d", len(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("Expected valid UTF-8 after cutting multi-byte runes, got %q", got)
+	}
+	if !strings.HasSuffix(got, truncationMarker) {
+		t.Errorf("Expected truncation marker, got %q", got)
+	}
+}
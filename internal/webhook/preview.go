@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"fmt"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+)
+
+// Preview returns the body and content type that would be sent for a notification,
+// without sending it or touching rate limits, the circuit breaker, metrics or the spool
+// With several destinations, the first one the status routes to is previewed; see PreviewDestination.
+// The body is returned before optional gzip compression.
+func (s *Sender) Preview(status analyzer.Status, message, sessionID string) ([]byte, string, error) {
+	if s.initErr != nil {
+		return nil, "", fmt.Errorf("webhook sender misconfigured: %w", s.initErr)
+	}
+	_, destinations := s.route(status)
+	if len(destinations) == 0 {
+		return nil, "", fmt.Errorf("no webhook destinations configured")
+	}
+	return s.preview(destinations[0], status, message, sessionID)
+}
+
+// PreviewDestination is Preview for the named destination
+func (s *Sender) PreviewDestination(name string, status analyzer.Status, message, sessionID string) ([]byte, string, error) {
+	if s.initErr != nil {
+		return nil, "", fmt.Errorf("webhook sender misconfigured: %w", s.initErr)
+	}
+	for _, dest := range s.destinations {
+		if dest.Name == name {
+			return s.preview(dest, status, message, sessionID)
+		}
+	}
+	return nil, "", fmt.Errorf("unknown webhook destination: %s", name)
+}
+
+// preview builds the payload for a single destination
+func (s *Sender) preview(dest destination, status analyzer.Status, message, sessionID string) ([]byte, string, error) {
+	payload, contentType, err := s.buildPayload(dest, status, message, sessionID, resolveDetails(Details{}))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build payload: %w", err)
+	}
+	return payload, contentType, nil
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestSenderPreview(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Preview must not send requests")
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig("")
+	cfg.Notifications.Webhook.Destinations = []config.WebhookDestination{
+		{Name: "slack", Preset: "slack", URL: server.URL},
+		{Name: "lark", Preset: "lark", URL: server.URL},
+	}
+	sender := New(cfg)
+
+	slackBody, slackType, err := sender.Preview(analyzer.StatusTaskComplete, "All done", "session-1")
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	larkBody, larkType, err := sender.PreviewDestination("lark", analyzer.StatusTaskComplete, "All done", "session-1")
+	if err != nil {
+		t.Fatalf("PreviewDestination failed: %v", err)
+	}
+
+	if slackType != "application/json" || larkType != "application/json" {
+		t.Errorf("Expected JSON content types, got %q and %q", slackType, larkType)
+	}
+
+	var slack, lark map[string]interface{}
+	if err := json.Unmarshal(slackBody, &slack); err != nil {
+		t.Fatalf("Invalid Slack preview: %v", err)
+	}
+	if err := json.Unmarshal(larkBody, &lark); err != nil {
+		t.Fatalf("Invalid Lark preview: %v", err)
+	}
+	if _, ok := slack["attachments"]; !ok {
+		t.Errorf("Expected Slack attachments in preview, got %s", slackBody)
+	}
+	if lark["msg_type"] != "interactive" {
+		t.Errorf("Expected Lark interactive card in preview, got %s", larkBody)
+	}
+
+	if _, _, err := sender.PreviewDestination("teams", analyzer.StatusTaskComplete, "All done", "session-1"); err == nil {
+		t.Error("Expected unknown destination to fail")
+	}
+	if stats := sender.GetMetrics(); stats.TotalRequests != 0 {
+		t.Errorf("Expected previews not to be counted in metrics, got %d", stats.TotalRequests)
+	}
+}
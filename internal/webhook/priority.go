@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// Priority is the urgency of a notification, translated by each formatter into
+// the service's own scale (ntfy 1-5, Gotify 0-10, Pushover -2..1, PagerDuty severity, OpsGenie P1-P5)
+type Priority int
+
+const (
+	PriorityUnset Priority = iota // use the status's configured or built-in priority
+	PriorityMin
+	PriorityLow
+	PriorityDefault
+	PriorityHigh
+	PriorityUrgent
+)
+
+// String returns the priority name as used in config, e.g. "high"
+func (p Priority) String() string {
+	if p < PriorityMin || p > PriorityUrgent {
+		return ""
+	}
+	return config.Priorities[p-PriorityMin]
+}
+
+// ParsePriority returns the priority for a config name, and false for an unknown name
+func ParsePriority(name string) (Priority, bool) {
+	for i, p := range config.Priorities {
+		if p == name {
+			return PriorityMin + Priority(i), true
+		}
+	}
+	return PriorityUnset, false
+}
+
+// StatusPriority returns the generic priority for status, as exposed to templates
+// Presets keep their own per-status defaults, see the getXPriority functions
+func StatusPriority(status analyzer.Status) Priority {
+	switch status {
+	case analyzer.StatusQuestion:
+		return PriorityUrgent // Claude is blocked waiting on a human
+	case analyzer.StatusAPIError, analyzer.StatusSessionLimitReached, analyzer.StatusPlanReady:
+		return PriorityHigh
+	default:
+		return PriorityDefault
+	}
+}
+
+// overridePriority returns details.Priority when set, then the status's priority override
+// false means neither is set and the preset's own default for the status applies
+func overridePriority(statusInfo config.StatusInfo, details Details) (Priority, bool) {
+	if details.Priority != PriorityUnset {
+		return details.Priority, true
+	}
+	return ParsePriority(statusInfo.Priority)
+}
+
+// resolvePriority returns the overridden priority, or StatusPriority without an override
+func resolvePriority(status analyzer.Status, statusInfo config.StatusInfo, details Details) Priority {
+	if p, ok := overridePriority(statusInfo, details); ok {
+		return p
+	}
+	return StatusPriority(status)
+}
+
+// ntfyPriority returns the ntfy priority (1 = min, 5 = urgent)
+func (p Priority) ntfyPriority() int {
+	return int(p)
+}
+
+// gotifyPriority returns the Gotify priority
+// Gotify clients treat 8-10 as high priority, 4-7 as normal
+func (p Priority) gotifyPriority() int {
+	return (int(p) - 1) * 2
+}
+
+// pushoverPriority returns the Pushover priority (1 = high, bypasses quiet hours; -2 = no alert)
+func (p Priority) pushoverPriority() int {
+	switch p {
+	case PriorityUrgent:
+		return 1
+	case PriorityLow:
+		return -1
+	case PriorityMin:
+		return -2
+	default:
+		return 0
+	}
+}
+
+// pagerDutySeverity returns the PagerDuty event severity
+func (p Priority) pagerDutySeverity() string {
+	switch p {
+	case PriorityUrgent:
+		return "critical"
+	case PriorityHigh:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// opsGeniePriority returns the OpsGenie priority, P1 being the most urgent
+func (p Priority) opsGeniePriority() string {
+	switch p {
+	case PriorityUrgent:
+		return "P1"
+	case PriorityHigh:
+		return "P2"
+	case PriorityDefault:
+		return "P3"
+	case PriorityLow:
+		return "P4"
+	default:
+		return "P5"
+	}
+}
@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestParsePriority(t *testing.T) {
+	for _, name := range config.Priorities {
+		p, ok := ParsePriority(name)
+		if !ok {
+			t.Fatalf("Expected %q to parse", name)
+		}
+		if p.String() != name {
+			t.Errorf("Expected %q to round-trip, got %q", name, p.String())
+		}
+	}
+	if _, ok := ParsePriority("critical"); ok {
+		t.Error("Expected unknown priority name to be rejected")
+	}
+	if PriorityUnset.String() != "" {
+		t.Errorf("Expected unset priority to have no name, got %q", PriorityUnset.String())
+	}
+}
+
+func TestSenderNtfyPriority(t *testing.T) {
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		payload = nil
+		_ = json.Unmarshal(body, &payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "ntfy"
+	cfg.Notifications.Webhook.Topic = "claude"
+
+	// The preset's own default for the status is used without an override
+	if err := New(cfg).Send(analyzer.StatusQuestion, "Which one?", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if payload["priority"] != float64(4) {
+		t.Errorf("Expected default ntfy priority 4 for question, got %v", payload["priority"])
+	}
+
+	// A per-status config override replaces the mapping
+	info := cfg.Statuses["question"]
+	info.Priority = "low"
+	cfg.Statuses["question"] = info
+	sender := New(cfg)
+	if err := sender.Send(analyzer.StatusQuestion, "Which one?", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if payload["priority"] != float64(2) {
+		t.Errorf("Expected overridden ntfy priority 2, got %v", payload["priority"])
+	}
+
+	// An explicit priority in the details wins over both
+	details := Details{Priority: PriorityUrgent}
+	if err := sender.SendWithDetails(analyzer.StatusQuestion, "Which one?", "session-123", details); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if payload["priority"] != float64(5) {
+		t.Errorf("Expected explicit ntfy priority 5, got %v", payload["priority"])
+	}
+}
@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+)
+
+// metricPrefix is prepended to all exported Prometheus metric names
+const metricPrefix = "claude_notifications_webhook"
+
+// MetricsHandler returns an HTTP handler exposing webhook metrics
+// in Prometheus text exposition format
+// Safe to serve concurrently with Send
+func (s *Sender) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Report the live breaker states rather than the last recorded ones
+		s.recordCircuitStates()
+		stats := s.GetMetrics()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writePrometheus(w, stats)
+	})
+}
+
+// writePrometheus writes stats in Prometheus text exposition format
+func writePrometheus(w io.Writer, stats Stats) {
+	writeMetric(w, "requests_total", "counter", "Total webhook delivery attempts.", stats.TotalRequests)
+	writeMetric(w, "successful_requests_total", "counter", "Successful webhook deliveries.", stats.SuccessfulRequests)
+	writeMetric(w, "failed_requests_total", "counter", "Failed webhook deliveries.", stats.FailedRequests)
+	writeMetric(w, "retried_requests_total", "counter", "Webhook retry attempts.", stats.RetriedRequests)
+	writeMetric(w, "retry_backoff_milliseconds_total", "counter", "Time spent waiting between webhook retry attempts.", stats.RetryBackoffMs)
+	writeMetric(w, "rate_limited_requests_total", "counter", "Webhooks dropped by the rate limiter.", stats.RateLimitedRequests)
+	writeMetric(w, "circuit_open_requests_total", "counter", "Webhooks rejected by the open circuit breaker.", stats.CircuitOpenRequests)
+	writeMetric(w, "fallback_deliveries_total", "counter", "Webhooks delivered by a fallback destination.", stats.FallbackDeliveries)
+	writeMetric(w, "format_failures_total", "counter", "Webhooks whose preset formatter errored or panicked.", stats.FormatFailures)
+
+	// Per-status successes (sorted for stable output)
+	statuses := make([]string, 0, len(stats.StatusCounts))
+	for status := range stats.StatusCounts {
+		statuses = append(statuses, string(status))
+	}
+	sort.Strings(statuses)
+
+	fmt.Fprintf(w, "# HELP %s_status_total Successful webhook deliveries by notification status.\n", metricPrefix)
+	fmt.Fprintf(w, "# TYPE %s_status_total counter\n", metricPrefix)
+	for _, status := range statuses {
+		fmt.Fprintf(w, "%s_status_total{status=%q} %d\n", metricPrefix, status, stats.StatusCounts[analyzer.Status(status)])
+	}
+
+	// Per-destination outcomes (sorted for stable output)
+	destinations := make([]string, 0, len(stats.DestinationStats))
+	for name := range stats.DestinationStats {
+		destinations = append(destinations, name)
+	}
+	sort.Strings(destinations)
+
+	fmt.Fprintf(w, "# HELP %s_destination_requests_total Webhook deliveries by destination and outcome.\n", metricPrefix)
+	fmt.Fprintf(w, "# TYPE %s_destination_requests_total counter\n", metricPrefix)
+	for _, name := range destinations {
+		dest := stats.DestinationStats[name]
+		fmt.Fprintf(w, "%s_destination_requests_total{destination=%q,outcome=\"success\"} %d\n", metricPrefix, name, dest.SuccessfulRequests)
+		fmt.Fprintf(w, "%s_destination_requests_total{destination=%q,outcome=\"failure\"} %d\n", metricPrefix, name, dest.FailedRequests)
+	}
+
+	writeMetric(w, "average_latency_milliseconds", "gauge", "Average latency of successful deliveries.", stats.AverageLatencyMs)
+
+	// Latency percentiles by status and destination
+	fmt.Fprintf(w, "# HELP %s_status_latency_milliseconds Latency percentiles of successful deliveries by notification status.\n", metricPrefix)
+	fmt.Fprintf(w, "# TYPE %s_status_latency_milliseconds gauge\n", metricPrefix)
+	for _, status := range statuses {
+		if p, ok := stats.StatusLatency[analyzer.Status(status)]; ok {
+			writeQuantiles(w, "status_latency_milliseconds", "status", status, p)
+		}
+	}
+	fmt.Fprintf(w, "# HELP %s_destination_latency_milliseconds Latency percentiles of successful deliveries by destination.\n", metricPrefix)
+	fmt.Fprintf(w, "# TYPE %s_destination_latency_milliseconds gauge\n", metricPrefix)
+	for _, name := range destinations {
+		if p, ok := stats.DestinationLatency[name]; ok {
+			writeQuantiles(w, "destination_latency_milliseconds", "destination", name, p)
+		}
+	}
+
+	writeMetric(w, "circuit_breaker_state", "gauge", "Worst circuit breaker state across destinations (0=closed, 1=open, 2=half-open).", int64(stats.CircuitBreakerState))
+
+	breakers := make([]string, 0, len(stats.BreakerStates))
+	for name := range stats.BreakerStates {
+		breakers = append(breakers, name)
+	}
+	sort.Strings(breakers)
+
+	fmt.Fprintf(w, "# HELP %s_destination_circuit_breaker_state Circuit breaker state by destination (0=closed, 1=open, 2=half-open).\n", metricPrefix)
+	fmt.Fprintf(w, "# TYPE %s_destination_circuit_breaker_state gauge\n", metricPrefix)
+	for _, name := range breakers {
+		fmt.Fprintf(w, "%s_destination_circuit_breaker_state{destination=%q} %d\n", metricPrefix, name, stats.BreakerStates[name])
+	}
+}
+
+// writeMetric writes a single unlabeled metric with its HELP and TYPE lines
+func writeMetric(w io.Writer, name, metricType, help string, value int64) {
+	fmt.Fprintf(w, "# HELP %s_%s %s\n", metricPrefix, name, help)
+	fmt.Fprintf(w, "# TYPE %s_%s %s\n", metricPrefix, name, metricType)
+	fmt.Fprintf(w, "%s_%s %d\n", metricPrefix, name, value)
+}
+
+// writeQuantiles writes the p50, p95 and p99 samples of a labeled latency metric
+func writeQuantiles(w io.Writer, name, label, value string, p LatencyPercentiles) {
+	fmt.Fprintf(w, "%s_%s{%s=%q,quantile=\"0.5\"} %.3f\n", metricPrefix, name, label, value, p.P50Ms)
+	fmt.Fprintf(w, "%s_%s{%s=%q,quantile=\"0.95\"} %.3f\n", metricPrefix, name, label, value, p.P95Ms)
+	fmt.Fprintf(w, "%s_%s{%s=%q,quantile=\"0.99\"} %.3f\n", metricPrefix, name, label, value, p.P99Ms)
+}
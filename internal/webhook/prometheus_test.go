@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+)
+
+func TestSenderMetricsHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := New(newTestConfig(server.URL))
+	_ = sender.Send(analyzer.StatusTaskComplete, "Test", "session-1")
+	_ = sender.Send(analyzer.StatusQuestion, "Test", "session-1")
+
+	rec := httptest.NewRecorder()
+	sender.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Errorf("Expected text/plain content type, got %s", rec.Header().Get("Content-Type"))
+	}
+
+	body := rec.Body.String()
+	expected := []string{
+		"# TYPE claude_notifications_webhook_requests_total counter",
+		"claude_notifications_webhook_requests_total 2",
+		"claude_notifications_webhook_successful_requests_total 2",
+		"claude_notifications_webhook_failed_requests_total 0",
+		`claude_notifications_webhook_status_total{status="question"} 1`,
+		`claude_notifications_webhook_status_total{status="task_complete"} 1`,
+		`claude_notifications_webhook_destination_requests_total{destination="default",outcome="success"} 2`,
+		"# TYPE claude_notifications_webhook_circuit_breaker_state gauge",
+		"claude_notifications_webhook_circuit_breaker_state 0",
+		`claude_notifications_webhook_status_latency_milliseconds{status="question",quantile="0.95"}`,
+		`claude_notifications_webhook_destination_latency_milliseconds{destination="default",quantile="0.99"}`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected metrics output to contain %q, got:\n%s", line, body)
+		}
+	}
+}
+
+func TestSenderMetricsHandlerCircuitOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Retry.Enabled = false
+	sender := New(cfg)
+
+	for i := 0; i < 3; i++ {
+		_ = sender.Send(analyzer.StatusTaskComplete, "Test", "session-1")
+	}
+
+	rec := httptest.NewRecorder()
+	sender.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if !strings.Contains(rec.Body.String(), "claude_notifications_webhook_circuit_breaker_state 1") {
+		t.Errorf("Expected open circuit breaker gauge, got:\n%s", rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `claude_notifications_webhook_destination_circuit_breaker_state{destination="default"} 1`) {
+		t.Errorf("Expected open circuit breaker gauge for the destination, got:\n%s", rec.Body.String())
+	}
+}
+
+func TestSenderMetricsHandlerConcurrentWithSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := New(newTestConfig(server.URL))
+	metricsServer := httptest.NewServer(sender.MetricsHandler())
+	defer metricsServer.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = sender.Send(analyzer.StatusTaskComplete, "Test", "session-1")
+		}()
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(metricsServer.URL)
+			if err != nil {
+				t.Errorf("Scrape failed: %v", err)
+				return
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+}
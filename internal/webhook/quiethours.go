@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// QuietHours is a recurring daily window in which webhooks are suppressed
+type QuietHours struct {
+	start    int // minutes since midnight
+	end      int // minutes since midnight
+	location *time.Location
+	days     map[time.Weekday]bool // nil means every day
+}
+
+// NewQuietHours parses a quiet hours config, returns nil if quiet hours are disabled
+func NewQuietHours(cfg config.QuietHoursConfig) (*QuietHours, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	start, err := parseClock(cfg.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours start: %w", err)
+	}
+	end, err := parseClock(cfg.End)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours end: %w", err)
+	}
+
+	location := time.Local
+	if cfg.Timezone != "" {
+		location, err = time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quiet hours timezone: %w", err)
+		}
+	}
+
+	var days map[time.Weekday]bool
+	if len(cfg.Days) > 0 {
+		days = make(map[time.Weekday]bool, len(cfg.Days))
+		for _, name := range cfg.Days {
+			day, ok := weekdays[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("invalid quiet hours day: %s", name)
+			}
+			days[day] = true
+		}
+	}
+
+	return &QuietHours{start: start, end: end, location: location, days: days}, nil
+}
+
+// Contains reports whether t falls inside the quiet window
+// A window that wraps past midnight belongs to the day it starts on
+func (q *QuietHours) Contains(t time.Time) bool {
+	t = t.In(q.location)
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	switch {
+	case q.start == q.end:
+		// Whole day
+		return q.onDay(day)
+	case q.start < q.end:
+		return minute >= q.start && minute < q.end && q.onDay(day)
+	case minute >= q.start:
+		return q.onDay(day)
+	case minute < q.end:
+		// Early-morning tail of a window that started yesterday
+		return q.onDay((day + 6) % 7)
+	default:
+		return false
+	}
+}
+
+// onDay reports whether a window starting on day is active
+func (q *QuietHours) onDay(day time.Weekday) bool {
+	return q.days == nil || q.days[day]
+}
+
+// parseClock parses "HH:MM" into minutes since midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
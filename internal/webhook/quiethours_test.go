@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestNewQuietHoursDisabled(t *testing.T) {
+	q, err := NewQuietHours(config.QuietHoursConfig{Start: "22:00", End: "08:00"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if q != nil {
+		t.Error("Expected nil quiet hours when disabled")
+	}
+}
+
+func TestNewQuietHoursDefaultsToLocalTimezone(t *testing.T) {
+	q, err := NewQuietHours(config.QuietHoursConfig{Enabled: true, Start: "22:00", End: "08:00"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if q.location != time.Local {
+		t.Errorf("Expected local timezone, got %v", q.location)
+	}
+}
+
+func TestNewQuietHoursInvalid(t *testing.T) {
+	tests := []config.QuietHoursConfig{
+		{Enabled: true, Start: "25:00", End: "08:00"},
+		{Enabled: true, Start: "22:00", End: "8am"},
+		{Enabled: true, Start: "22:00", End: "08:00", Timezone: "Mars/Olympus_Mons"},
+		{Enabled: true, Start: "22:00", End: "08:00", Days: []string{"funday"}},
+	}
+
+	for _, cfg := range tests {
+		if _, err := NewQuietHours(cfg); err == nil {
+			t.Errorf("Expected error for %+v", cfg)
+		}
+	}
+}
+
+func TestQuietHoursWrapsPastMidnight(t *testing.T) {
+	q, err := NewQuietHours(config.QuietHoursConfig{
+		Enabled:  true,
+		Start:    "22:00",
+		End:      "07:30",
+		Timezone: "UTC",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		at       string
+		expected bool
+	}{
+		{"2025-01-15T21:59:00Z", false},
+		{"2025-01-15T22:00:00Z", true},
+		{"2025-01-15T23:59:00Z", true},
+		{"2025-01-16T00:00:00Z", true},
+		{"2025-01-16T07:29:00Z", true},
+		{"2025-01-16T07:30:00Z", false},
+		{"2025-01-16T12:00:00Z", false},
+	}
+
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		if got := q.Contains(at); got != tt.expected {
+			t.Errorf("Contains(%s) = %v, want %v", tt.at, got, tt.expected)
+		}
+	}
+}
+
+func TestQuietHoursWrapUsesStartDay(t *testing.T) {
+	// Friday night window spills into Saturday morning, but Saturday night is not quiet
+	q, err := NewQuietHours(config.QuietHoursConfig{
+		Enabled:  true,
+		Start:    "22:00",
+		End:      "06:00",
+		Timezone: "UTC",
+		Days:     []string{"fri"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		at       string
+		expected bool
+	}{
+		{"2025-01-17T23:00:00Z", true},  // Friday night
+		{"2025-01-18T03:00:00Z", true},  // Saturday morning, window started Friday
+		{"2025-01-18T23:00:00Z", false}, // Saturday night
+		{"2025-01-17T03:00:00Z", false}, // Friday morning, window started Thursday
+	}
+
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		if got := q.Contains(at); got != tt.expected {
+			t.Errorf("Contains(%s) = %v, want %v", tt.at, got, tt.expected)
+		}
+	}
+}
+
+func TestQuietHoursWeekendOnly(t *testing.T) {
+	q, err := NewQuietHours(config.QuietHoursConfig{
+		Enabled:  true,
+		Start:    "00:00",
+		End:      "00:00",
+		Timezone: "UTC",
+		Days:     []string{"Sat", "sun"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		at       string
+		expected bool
+	}{
+		{"2025-01-17T23:59:00Z", false}, // Friday
+		{"2025-01-18T00:00:00Z", true},  // Saturday
+		{"2025-01-18T15:00:00Z", true},  // Saturday
+		{"2025-01-19T23:59:00Z", true},  // Sunday
+		{"2025-01-20T00:00:00Z", false}, // Monday
+		{"2025-01-15T12:00:00Z", false}, // Wednesday
+	}
+
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		if got := q.Contains(at); got != tt.expected {
+			t.Errorf("Contains(%s) = %v, want %v", tt.at, got, tt.expected)
+		}
+	}
+}
+
+func TestQuietHoursTimezone(t *testing.T) {
+	q, err := NewQuietHours(config.QuietHoursConfig{Enabled: true, Start: "22:00", End: "06:00"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	q.location = time.FixedZone("UTC+3", 3*60*60)
+
+	// 20:00 UTC is 23:00 in UTC+3
+	at, _ := time.Parse(time.RFC3339, "2025-01-15T20:00:00Z")
+	if !q.Contains(at) {
+		t.Error("Expected window to be evaluated in the configured timezone")
+	}
+}
+
+func TestSenderSkipsDuringQuietHours(t *testing.T) {
+	requests := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	// Start equal to end covers the whole day, so now is always quiet
+	cfg.Notifications.Webhook.QuietHours = config.QuietHoursConfig{Enabled: true, Start: "00:00", End: "00:00"}
+	sender := New(cfg)
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Quiet message", "session-123"); err != nil {
+		t.Fatalf("Expected quiet hours to skip without error, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no HTTP request during quiet hours, got %d", requests.Load())
+	}
+	if stats := sender.GetMetrics(); stats.FailedRequests != 0 {
+		t.Errorf("Expected no failures during quiet hours, got %d", stats.FailedRequests)
+	}
+}
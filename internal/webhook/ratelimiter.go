@@ -104,3 +104,68 @@ func (rl *RateLimiter) GetStats() (tokens float64, capacity int, rate float64) {
 	defer rl.mu.Unlock()
 	return rl.tokens, rl.capacity, rl.rate
 }
+
+// sessionBucketTTL is how long an idle session bucket is kept
+// A bucket idle this long has refilled completely, so dropping it loses nothing
+const sessionBucketTTL = time.Minute
+
+// SessionRateLimiter applies a separate token bucket to each session
+type SessionRateLimiter struct {
+	requestsPerMinute int
+	buckets           map[string]*sessionBucket
+	lastSweep         time.Time
+	mu                sync.Mutex
+}
+
+// sessionBucket is a session's limiter and when it was last used
+type sessionBucket struct {
+	limiter  *RateLimiter
+	lastUsed time.Time
+}
+
+// NewSessionRateLimiter creates a per-session rate limiter
+// requestsPerMinute: maximum requests allowed per minute for each session
+func NewSessionRateLimiter(requestsPerMinute int) *SessionRateLimiter {
+	return &SessionRateLimiter{
+		requestsPerMinute: requestsPerMinute,
+		buckets:           make(map[string]*sessionBucket),
+		lastSweep:         time.Now(),
+	}
+}
+
+// Allow checks if a request for sessionID is allowed under its rate limit
+func (sl *SessionRateLimiter) Allow(sessionID string) bool {
+	sl.mu.Lock()
+	now := time.Now()
+	if now.Sub(sl.lastSweep) >= sessionBucketTTL {
+		sl.sweep(now)
+	}
+
+	bucket, ok := sl.buckets[sessionID]
+	if !ok {
+		bucket = &sessionBucket{limiter: NewRateLimiter(sl.requestsPerMinute)}
+		sl.buckets[sessionID] = bucket
+	}
+	bucket.lastUsed = now
+	sl.mu.Unlock()
+
+	return bucket.limiter.Allow()
+}
+
+// sweep removes buckets idle for longer than sessionBucketTTL
+// Must be called with sl.mu held
+func (sl *SessionRateLimiter) sweep(now time.Time) {
+	for sessionID, bucket := range sl.buckets {
+		if now.Sub(bucket.lastUsed) >= sessionBucketTTL {
+			delete(sl.buckets, sessionID)
+		}
+	}
+	sl.lastSweep = now
+}
+
+// Len returns the number of tracked sessions
+func (sl *SessionRateLimiter) Len() int {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	return len(sl.buckets)
+}
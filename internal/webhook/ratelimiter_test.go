@@ -261,3 +261,44 @@ func TestRateLimiterSteadyState(t *testing.T) {
 		t.Errorf("Expected 2-4 requests over 3 seconds, got %d", allowedCount)
 	}
 }
+
+func TestSessionRateLimiterIsolatesSessions(t *testing.T) {
+	sl := NewSessionRateLimiter(3)
+
+	for i := 0; i < 3; i++ {
+		if !sl.Allow("chatty") {
+			t.Fatalf("Request %d for chatty session should be allowed", i+1)
+		}
+	}
+	if sl.Allow("chatty") {
+		t.Error("Chatty session should be throttled after its burst")
+	}
+
+	if !sl.Allow("quiet") {
+		t.Error("Other session should not be affected by chatty session")
+	}
+	if sl.Len() != 2 {
+		t.Errorf("Expected 2 tracked sessions, got %d", sl.Len())
+	}
+}
+
+func TestSessionRateLimiterGC(t *testing.T) {
+	sl := NewSessionRateLimiter(10)
+	sl.Allow("old-1")
+	sl.Allow("old-2")
+
+	// Age the buckets past the TTL
+	sl.mu.Lock()
+	past := time.Now().Add(-2 * sessionBucketTTL)
+	for _, bucket := range sl.buckets {
+		bucket.lastUsed = past
+	}
+	sl.lastSweep = past
+	sl.mu.Unlock()
+
+	sl.Allow("new")
+
+	if sl.Len() != 1 {
+		t.Errorf("Expected idle session buckets to be collected, got %d tracked", sl.Len())
+	}
+}
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/errorhandler"
+)
+
+// Reasons a notification is skipped without being attempted, see DeliveryReceipt.Skipped
+const (
+	SkipDisabled   = "disabled"    // webhooks are disabled in config or by config.DisabledEnv
+	SkipMuted      = "muted"       // the status is in mutedStatuses
+	SkipQuietHours = "quiet_hours" // sent during quiet hours
+	SkipStale      = "stale"       // the event is older than maxEventAge
+)
+
+// DeliveryReceipt describes the outcome of a single Send
+type DeliveryReceipt struct {
+	Status       analyzer.Status
+	SessionID    string
+	RequestID    string               // empty when no request was made
+	Latency      time.Duration        // from the call until the final outcome
+	Err          error                // the error Send returned, nil on success or when skipped
+	Skipped      string               // why nothing was attempted, e.g. SkipMuted; empty otherwise
+	Destinations []DestinationOutcome // routed destinations in order, then the fallbacks that were tried
+
+	start time.Time
+}
+
+// DestinationOutcome is the outcome of a notification at one destination
+type DestinationOutcome struct {
+	Destination string        // destination name, or the route name when the notification was rejected before fan-out
+	Fallback    bool          // a fallback tried after the routed destinations failed
+	Rejected    bool          // dropped by the rate limiter or open circuit breaker before a request was made
+	Latency     time.Duration // time spent on this destination, including retries
+	Err         error         // nil on success
+}
+
+// WithDeliveryCallback registers fn to receive a receipt for every Send
+// fn is called exactly once per Send, SendAsync or Update, after retries and fallbacks, with the outcome
+// at each destination. Skipped sends (disabled, muted, quiet hours, stale) get a receipt with Skipped set.
+// A batched send gets its receipt when the batch is delivered, carrying the combined delivery's outcome.
+// Each call runs in its own goroutine, so a slow callback never delays sending; Shutdown waits for pending calls.
+func WithDeliveryCallback(fn func(DeliveryReceipt)) Option {
+	return func(s *Sender) {
+		s.onDelivery = fn
+	}
+}
+
+// newReceipt starts the receipt of a send
+func newReceipt(status analyzer.Status, sessionID string) *DeliveryReceipt {
+	return &DeliveryReceipt{Status: status, SessionID: sessionID, start: time.Now()}
+}
+
+// addOutcome records the outcome at a destination
+func (r *DeliveryReceipt) addOutcome(outcome DestinationOutcome) {
+	r.Destinations = append(r.Destinations, outcome)
+}
+
+// finish completes the receipt with the send's result, reports it and returns err
+func (s *Sender) finish(receipt *DeliveryReceipt, err error) error {
+	receipt.Err = err
+	receipt.Latency = time.Since(receipt.start)
+	if s.audit != nil {
+		s.audit.Record(*receipt)
+	}
+	s.notifyDelivery(*receipt)
+	return err
+}
+
+// skip reports a send that was not attempted
+func (s *Sender) skip(receipt *DeliveryReceipt, reason string) error {
+	receipt.Skipped = reason
+	return s.finish(receipt, nil)
+}
+
+// notifyDelivery hands a receipt to the delivery callback, if any
+func (s *Sender) notifyDelivery(receipt DeliveryReceipt) {
+	if s.onDelivery == nil {
+		return
+	}
+	s.wg.Add(1)
+	errorhandler.SafeGo(func() {
+		defer s.wg.Done()
+		s.onDelivery(receipt)
+	})
+}
@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// collectReceipts returns a delivery callback and a channel receiving its receipts
+func collectReceipts() (func(DeliveryReceipt), chan DeliveryReceipt) {
+	ch := make(chan DeliveryReceipt, 10)
+	return func(r DeliveryReceipt) { ch <- r }, ch
+}
+
+// nextReceipt waits for a receipt, failing the test after a timeout
+func nextReceipt(t *testing.T, ch chan DeliveryReceipt) DeliveryReceipt {
+	t.Helper()
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for delivery receipt")
+		return DeliveryReceipt{}
+	}
+}
+
+func TestSenderDeliveryCallbackSuccess(t *testing.T) {
+	callback, receipts := collectReceipts()
+	transport := &fakeTransport{statuses: []int{http.StatusServiceUnavailable, http.StatusOK}}
+	sender := New(newTestConfig("https://hooks.example.com/webhook"), WithTransport(transport), WithDeliveryCallback(callback))
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "msg", "session-1"); err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+
+	r := nextReceipt(t, receipts)
+	if r.Err != nil || r.Status != analyzer.StatusTaskComplete || r.SessionID != "session-1" || r.Skipped != "" {
+		t.Errorf("Unexpected receipt: %+v", r)
+	}
+	if r.RequestID == "" || r.Latency <= 0 {
+		t.Errorf("Expected request ID and latency, got %+v", r)
+	}
+	if len(r.Destinations) != 1 || r.Destinations[0].Destination != "default" || r.Destinations[0].Err != nil || r.Destinations[0].Latency <= 0 {
+		t.Errorf("Expected a successful outcome for the default destination, got %+v", r.Destinations)
+	}
+
+	// Retries are not reported separately
+	if err := sender.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if len(receipts) != 0 {
+		t.Errorf("Expected exactly one receipt, got %d more", len(receipts))
+	}
+}
+
+func TestSenderDeliveryCallbackFailureAsync(t *testing.T) {
+	callback, receipts := collectReceipts()
+	cfg := newTestConfig("https://hooks.example.com/webhook")
+	cfg.Notifications.Webhook.Retry.Enabled = false
+	transport := &fakeTransport{statuses: []int{http.StatusInternalServerError}}
+	sender := New(cfg, WithTransport(transport), WithDeliveryCallback(callback))
+
+	sender.SendAsync(analyzer.StatusQuestion, "msg", "session-2")
+	if err := sender.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	r := nextReceipt(t, receipts)
+	var sendErr *SendError
+	if !errors.As(r.Err, &sendErr) || sendErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected receipt with the 500 error, got %+v", r)
+	}
+	if r.Status != analyzer.StatusQuestion {
+		t.Errorf("Expected question status, got %s", r.Status)
+	}
+	if len(receipts) != 0 {
+		t.Errorf("Expected exactly one receipt, got %d more", len(receipts))
+	}
+}
+
+func TestSenderDeliveryCallbackRejected(t *testing.T) {
+	callback, receipts := collectReceipts()
+	cfg := newTestConfig("https://hooks.example.com/webhook")
+	cfg.Notifications.Webhook.RateLimit.Enabled = true
+	cfg.Notifications.Webhook.RateLimit.RequestsPerMinute = 1
+	transport := &fakeTransport{statuses: []int{http.StatusOK}}
+	sender := New(cfg, WithTransport(transport), WithDeliveryCallback(callback))
+
+	_ = sender.Send(analyzer.StatusTaskComplete, "first", "session-1")
+	if err := sender.Send(analyzer.StatusTaskComplete, "second", "session-1"); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("Expected rate limit error, got %v", err)
+	}
+
+	first, second := nextReceipt(t, receipts), nextReceipt(t, receipts)
+	// Callbacks run concurrently, so receipts may arrive in either order
+	if first.Err != nil {
+		first, second = second, first
+	}
+	if first.Err != nil || !errors.Is(second.Err, ErrRateLimitExceeded) {
+		t.Errorf("Expected one success and one rate-limited receipt, got %+v and %+v", first, second)
+	}
+	if second.RequestID != "" || len(second.Destinations) != 1 {
+		t.Fatalf("Expected rejected receipt without request ID, got %+v", second)
+	}
+	if outcome := second.Destinations[0]; outcome.Destination != "default" || !outcome.Rejected {
+		t.Errorf("Expected a rejected outcome for the default route, got %+v", outcome)
+	}
+}
+
+func TestSenderDeliveryCallbackMultipleDestinations(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer working.Close()
+
+	callback, receipts := collectReceipts()
+	cfg := newTestConfig("")
+	cfg.Notifications.Webhook.Destinations = []config.WebhookDestination{
+		{Name: "broken", URL: failing.URL, Format: "json"},
+		{Name: "working", URL: working.URL, Format: "json"},
+	}
+	sender := New(cfg, WithDeliveryCallback(callback))
+
+	err := sender.Send(analyzer.StatusTaskComplete, "msg", "session-1")
+	if err == nil {
+		t.Fatal("Expected the broken destination's error")
+	}
+	if err := sender.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	r := nextReceipt(t, receipts)
+	if len(receipts) != 0 {
+		t.Errorf("Expected one receipt for the send, got %d more", len(receipts))
+	}
+	if r.Err == nil || r.Err.Error() != err.Error() {
+		t.Errorf("Expected the receipt to carry the send's error %v, got %v", err, r.Err)
+	}
+	if len(r.Destinations) != 2 {
+		t.Fatalf("Expected an outcome per destination, got %+v", r.Destinations)
+	}
+	if broken := r.Destinations[0]; broken.Destination != "broken" || broken.Err == nil || broken.Rejected {
+		t.Errorf("Expected a failed outcome for broken, got %+v", broken)
+	}
+	if ok := r.Destinations[1]; ok.Destination != "working" || ok.Err != nil {
+		t.Errorf("Expected a successful outcome for working, got %+v", ok)
+	}
+}
+
+func TestSenderDeliveryCallbackFallback(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backup.Close()
+
+	callback, receipts := collectReceipts()
+	cfg := newTestConfig(primary.URL)
+	cfg.Notifications.Webhook.Fallbacks = []config.WebhookDestination{
+		{Name: "broken", Preset: "custom", Format: "json", URL: "ftp://invalid"},
+		{Name: "backup", Preset: "custom", Format: "json", URL: backup.URL},
+	}
+	sender := New(cfg, WithDeliveryCallback(callback))
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "msg", "session-1"); err != nil {
+		t.Fatalf("Expected fallback delivery to succeed, got %v", err)
+	}
+	if err := sender.Shutdown(time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	r := nextReceipt(t, receipts)
+	if len(receipts) != 0 {
+		t.Errorf("Expected one receipt for the send, got %d more", len(receipts))
+	}
+	if r.Err != nil || r.RequestID == "" {
+		t.Errorf("Expected a successful receipt with request ID, got %+v", r)
+	}
+
+	want := []struct {
+		name     string
+		fallback bool
+		failed   bool
+	}{
+		{"default", false, true},
+		{"broken", true, true},
+		{"backup", true, false},
+	}
+	if len(r.Destinations) != len(want) {
+		t.Fatalf("Expected %d outcomes, got %+v", len(want), r.Destinations)
+	}
+	for i, w := range want {
+		got := r.Destinations[i]
+		if got.Destination != w.name || got.Fallback != w.fallback || (got.Err != nil) != w.failed {
+			t.Errorf("Outcome %d: expected %s (fallback %v, failed %v), got %+v", i, w.name, w.fallback, w.failed, got)
+		}
+	}
+}
+
+func TestSenderDeliveryCallbackSkipped(t *testing.T) {
+	callback, receipts := collectReceipts()
+	cfg := newTestConfig("https://hooks.example.com/webhook")
+	cfg.Notifications.MutedStatuses = []string{"review_complete"}
+	transport := &fakeTransport{statuses: []int{http.StatusOK}}
+	sender := New(cfg, WithTransport(transport), WithDeliveryCallback(callback))
+
+	if err := sender.Send(analyzer.StatusReviewComplete, "msg", "session-1"); err != nil {
+		t.Fatalf("Expected muted send to succeed silently, got %v", err)
+	}
+	r := nextReceipt(t, receipts)
+	if r.Skipped != SkipMuted || r.Err != nil || len(r.Destinations) != 0 || r.RequestID != "" {
+		t.Errorf("Expected a muted receipt without outcomes, got %+v", r)
+	}
+
+	cfg.Notifications.Webhook.Enabled = false
+	if err := sender.Send(analyzer.StatusTaskComplete, "msg", "session-1"); err != nil {
+		t.Fatalf("Expected disabled send to succeed silently, got %v", err)
+	}
+	if r := nextReceipt(t, receipts); r.Skipped != SkipDisabled {
+		t.Errorf("Expected a disabled receipt, got %+v", r)
+	}
+	if calls := transport.calls.Load(); calls != 0 {
+		t.Errorf("Expected no requests for skipped sends, got %d", calls)
+	}
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// redactedValue replaces secrets in verbose logs
+const redactedValue = "***"
+
+// sensitiveHeaders are always hidden in verbose logs
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+	"X-Gotify-Key",
+}
+
+// tokenPatterns match credentials embedded in webhook URLs and bodies
+// The first group, if any, is kept so the log still shows which endpoint was called
+var tokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(/bot)\d+:[A-Za-z0-9_-]+`),                                     // Telegram bot token
+	regexp.MustCompile(`(hooks\.slack\.com/services/)[A-Za-z0-9/]+`),                   // Slack incoming webhook
+	regexp.MustCompile(`(/api/webhooks/\d+/)[A-Za-z0-9_-]+`),                           // Discord webhook token
+	regexp.MustCompile(`(xox[abpr]-)[A-Za-z0-9-]+`),                                    // Slack API token
+	regexp.MustCompile(`(?i)([?&](?:token|key|secret|access_token|api_key)=)[^&\s"]+`), // query credentials
+}
+
+// redactor hides secrets in logged requests and responses
+type redactor struct {
+	headers map[string]bool // canonical names of headers whose values are hidden
+}
+
+// newRedactor returns a redactor hiding sensitiveHeaders and the extra header names
+func newRedactor(extra []string) *redactor {
+	r := &redactor{headers: make(map[string]bool, len(sensitiveHeaders)+len(extra))}
+	for _, name := range sensitiveHeaders {
+		r.headers[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range extra {
+		r.headers[http.CanonicalHeaderKey(name)] = true
+	}
+	return r
+}
+
+// header formats request headers as "Name: value" pairs sorted by name, hiding sensitive values
+func (r *redactor) header(h http.Header) string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(h.Values(name), ", ")
+		if r.headers[name] {
+			value = redactedValue
+		}
+		pairs = append(pairs, fmt.Sprintf("%s: %s", name, value))
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// text hides known token patterns and the destination's configured credentials in s
+func (r *redactor) text(dest destination, s string) string {
+	for _, pattern := range tokenPatterns {
+		s = pattern.ReplaceAllString(s, "${1}"+redactedValue)
+	}
+
+	// Credentials sent in the body (Pushover token, PagerDuty routing key) or sensitive headers
+	secrets := []string{dest.Token, dest.RoutingKey}
+	for name, value := range dest.Headers {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			secrets = append(secrets, value)
+		}
+	}
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, redactedValue)
+		}
+	}
+	return s
+}
+
+// url returns targetURL with embedded credentials hidden
+func (r *redactor) url(dest destination, targetURL string) string {
+	if u, err := url.Parse(targetURL); err == nil && u.User != nil {
+		u.User = url.User(redactedValue)
+		targetURL = u.String()
+	}
+	return r.text(dest, targetURL)
+}
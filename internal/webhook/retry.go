@@ -2,13 +2,29 @@ package webhook
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"net"
 	"net/http"
+	"strconv"
+	"syscall"
 	"time"
 )
 
+// JitterMode controls how backoff delays are randomized
+type JitterMode string
+
+const (
+	// JitterEqual keeps half the backoff and randomizes the other half (default)
+	JitterEqual JitterMode = "equal"
+	// JitterFull randomizes the whole backoff between 0 and the computed delay
+	JitterFull JitterMode = "full"
+	// JitterNone uses the exact exponential backoff
+	JitterNone JitterMode = "none"
+)
+
 // RetryConfig holds retry configuration
 type RetryConfig struct {
 	Enabled        bool
@@ -16,8 +32,18 @@ type RetryConfig struct {
 	InitialBackoff time.Duration
 	MaxBackoff     time.Duration
 	Multiplier     float64
+	Jitter         JitterMode    // empty means JitterEqual
+	MaxElapsedTime time.Duration // total time budget across attempts, 0 means no limit
+	QuickRetries   int           // extra fixed-delay retries for DNS and connection-refused errors, 0 disables
+	QuickDelay     time.Duration // delay before each quick retry
 }
 
+// Quick retry defaults, used when quick retries are enabled without explicit values
+const (
+	DefaultQuickRetries = 2
+	DefaultQuickDelay   = 200 * time.Millisecond
+)
+
 // DefaultRetryConfig returns sensible defaults for retry
 func DefaultRetryConfig() RetryConfig {
 	return RetryConfig{
@@ -26,6 +52,7 @@ func DefaultRetryConfig() RetryConfig {
 		InitialBackoff: 1 * time.Second,
 		MaxBackoff:     10 * time.Second,
 		Multiplier:     2.0,
+		Jitter:         JitterEqual,
 	}
 }
 
@@ -34,8 +61,9 @@ type RetryableFunc func(ctx context.Context) error
 
 // Retryer handles retry logic with exponential backoff
 type Retryer struct {
-	config RetryConfig
-	rand   *rand.Rand
+	config  RetryConfig
+	rand    *rand.Rand
+	metrics *Metrics // receives retry counts and backoff time per Do call, nil disables
 }
 
 // NewRetryer creates a new Retryer
@@ -46,17 +74,41 @@ func NewRetryer(config RetryConfig) *Retryer {
 	}
 }
 
+// SetMetrics makes Do report how many retries each call needed and how long it backed off
+func (r *Retryer) SetMetrics(m *Metrics) {
+	r.metrics = m
+}
+
+// retryUsage counts the retries and backoff time of a single Do call
+type retryUsage struct {
+	retries int
+	backoff time.Duration
+}
+
 // Do executes the function with retry logic
-// Returns error if all retries are exhausted
+// Returns error if all retries are exhausted or MaxElapsedTime would be exceeded
 func (r *Retryer) Do(ctx context.Context, fn RetryableFunc) error {
 	if !r.config.Enabled {
 		return fn(ctx)
 	}
 
+	var usage retryUsage
+	defer func() {
+		if r.metrics != nil && usage.retries > 0 {
+			r.metrics.RecordRetries(usage.retries, usage.backoff)
+		}
+	}()
+
+	start := time.Now()
+	quickLeft := r.config.QuickRetries
 	var lastErr error
 	for attempt := 1; attempt <= r.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			usage.retries++
+		}
+
 		// Execute the function
-		err := fn(ctx)
+		err := r.callWithQuickRetry(ctx, fn, &quickLeft, &usage)
 
 		// Success!
 		if err == nil {
@@ -80,14 +132,19 @@ func (r *Retryer) Do(ctx context.Context, fn RetryableFunc) error {
 			return fmt.Errorf("context cancelled: %w", ctx.Err())
 		}
 
-		// Calculate backoff with jitter
+		// Calculate backoff with jitter, unless the server told us how long to wait
 		backoff := r.calculateBackoff(attempt)
+		if retryAfter := retryAfterFromError(err); retryAfter > 0 {
+			backoff = retryAfter
+		}
+
+		// Stop early if waiting for the next attempt would exceed the time budget
+		if r.config.MaxElapsedTime > 0 && time.Since(start)+backoff > r.config.MaxElapsedTime {
+			return fmt.Errorf("retry deadline (%v) exceeded after %d attempts: %w", r.config.MaxElapsedTime, attempt, lastErr)
+		}
 
 		// Sleep before next retry
-		select {
-		case <-time.After(backoff):
-			// Continue to next attempt
-		case <-ctx.Done():
+		if !usage.wait(ctx, backoff) {
 			return fmt.Errorf("context cancelled during backoff: %w", ctx.Err())
 		}
 	}
@@ -95,6 +152,43 @@ func (r *Retryer) Do(ctx context.Context, fn RetryableFunc) error {
 	return fmt.Errorf("max retry attempts (%d) exhausted: %w", r.config.MaxAttempts, lastErr)
 }
 
+// callWithQuickRetry runs fn, retrying DNS and connection-refused failures after the short
+// fixed QuickDelay while quick retries are left. These failures are often momentary
+// (e.g. container DNS warming up), so waiting the full exponential backoff wastes time.
+// quickLeft is shared across attempts so the quick budget applies to the whole Do call.
+func (r *Retryer) callWithQuickRetry(ctx context.Context, fn RetryableFunc, quickLeft *int, usage *retryUsage) error {
+	err := fn(ctx)
+	for err != nil && *quickLeft > 0 && isConnectionError(err) {
+		*quickLeft--
+		if !usage.wait(ctx, r.config.QuickDelay) {
+			return err
+		}
+		usage.retries++
+		err = fn(ctx)
+	}
+	return err
+}
+
+// wait sleeps for d, adding the time actually waited to the backoff total
+// Returns false if ctx was cancelled first
+func (u *retryUsage) wait(ctx context.Context, d time.Duration) bool {
+	start := time.Now()
+	defer func() { u.backoff += time.Since(start) }()
+
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isConnectionError reports whether err is a DNS resolution failure or a refused connection
+func isConnectionError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
 // calculateBackoff calculates backoff duration with exponential growth and jitter
 func (r *Retryer) calculateBackoff(attempt int) time.Duration {
 	// Exponential backoff: initialBackoff * (multiplier ^ (attempt - 1))
@@ -105,33 +199,40 @@ func (r *Retryer) calculateBackoff(attempt int) time.Duration {
 		backoff = float64(r.config.MaxBackoff)
 	}
 
-	// Add jitter: random value between 0 and 25% of backoff
-	// This prevents thundering herd problem
-	jitter := r.rand.Float64() * backoff * 0.25
-	backoff += jitter
+	// Add jitter so simultaneous hooks don't retry in lockstep (thundering herd)
+	switch r.config.Jitter {
+	case JitterNone:
+	case JitterFull:
+		backoff = r.rand.Float64() * backoff
+	default:
+		backoff = backoff/2 + r.rand.Float64()*backoff/2
+	}
 
 	return time.Duration(backoff)
 }
 
 // isRetryable determines if an error is retryable
-// Permanent errors (4xx except 429) should not be retried
-// Temporary errors (5xx, network errors, timeouts) should be retried
+// Permanent errors (4xx except 408 and 429) should not be retried
+// Temporary errors (408, 429, 5xx, network errors, timeouts) should be retried
 func (r *Retryer) isRetryable(err error) bool {
 	if err == nil {
 		return false
 	}
 
+	// The sender has already classified the failure
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		return sendErr.Retryable
+	}
+
 	// Check for HTTPError
-	if httpErr, ok := err.(*HTTPError); ok {
-		// 4xx Client Errors (except 429 Too Many Requests) are permanent
-		if httpErr.StatusCode >= 400 && httpErr.StatusCode < 500 {
-			return httpErr.StatusCode == 429 // Only 429 is retryable
-		}
+	if httpErr, ok := err.(*HTTPError); ok && httpErr.StatusCode >= 400 {
+		return isRetryableStatus(httpErr.StatusCode)
+	}
 
-		// 5xx Server Errors are retryable
-		if httpErr.StatusCode >= 500 {
-			return true
-		}
+	// A logically rejected request (e.g. Telegram "ok": false) won't succeed on retry
+	if _, ok := err.(*ResponseMismatchError); ok {
+		return false
 	}
 
 	// Network errors, timeouts are retryable
@@ -139,11 +240,46 @@ func (r *Retryer) isRetryable(err error) bool {
 	return true
 }
 
+// isRetryableStatus reports whether an HTTP error status is temporary
+// 4xx Client Errors (except 408 Request Timeout and 429 Too Many Requests) are permanent,
+// 5xx Server Errors are retryable
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// retryAfterFromError returns the server-requested delay carried by an HTTP error, or 0
+func retryAfterFromError(err error) time.Duration {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.RetryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+// Returns 0 if the header is missing, malformed, or in the past
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
 // HTTPError represents an HTTP error response
 type HTTPError struct {
 	StatusCode int
 	Status     string
 	Body       string
+	RetryAfter time.Duration // delay requested by the server via Retry-After, 0 if none
 }
 
 func (e *HTTPError) Error() string {
@@ -164,5 +300,6 @@ func NewHTTPError(resp *http.Response, body string) *HTTPError {
 		StatusCode: resp.StatusCode,
 		Status:     resp.Status,
 		Body:       body,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
 	}
 }
@@ -3,9 +3,20 @@ package webhook
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
 )
 
 func TestRetrySuccess(t *testing.T) {
@@ -64,6 +75,48 @@ func TestRetryMaxAttemptsExhausted(t *testing.T) {
 	}
 }
 
+func TestRetryMaxElapsedTime(t *testing.T) {
+	config := RetryConfig{
+		Enabled:        true,
+		MaxAttempts:    10,
+		InitialBackoff: 20 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		Multiplier:     2.0,
+		Jitter:         JitterNone,
+		MaxElapsedTime: 50 * time.Millisecond,
+	}
+	retryer := NewRetryer(config)
+
+	attempts := 0
+	fn := func(ctx context.Context) error {
+		attempts++
+		return &HTTPError{StatusCode: 503, Body: "Service Unavailable"}
+	}
+
+	start := time.Now()
+	err := retryer.Do(context.Background(), fn)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("Expected error after deadline, got nil")
+	}
+	if !strings.Contains(err.Error(), "retry deadline") {
+		t.Errorf("Expected deadline error, got: %v", err)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Errorf("Expected last error to be wrapped, got: %v", err)
+	}
+
+	// Attempts at ~0ms, 20ms, 40ms; waiting until 60ms would exceed the 50ms budget
+	if attempts >= config.MaxAttempts {
+		t.Errorf("Expected deadline to stop retries before %d attempts, got %d", config.MaxAttempts, attempts)
+	}
+	if elapsed > config.MaxElapsedTime {
+		t.Errorf("Expected retry to stop within %v, took %v", config.MaxElapsedTime, elapsed)
+	}
+}
+
 func TestRetryPermanentError(t *testing.T) {
 	config := RetryConfig{
 		Enabled:        true,
@@ -147,9 +200,9 @@ func TestRetryBackoffProgression(t *testing.T) {
 		t.Errorf("Expected 4 attempts, got %d", attempts)
 	}
 
-	// Total time should be at least initial + 2*initial + 4*initial = 7*initial
-	// But with jitter it could be less, so we check for at least 5*initial
-	minExpected := 5 * config.InitialBackoff
+	// Total backoff is initial + 2*initial + 4*initial = 7*initial
+	// Equal jitter keeps at least half of each delay, so expect at least 3.5*initial
+	minExpected := 7 * config.InitialBackoff / 2
 	if elapsed < minExpected {
 		t.Errorf("Expected at least %v elapsed time, got %v", minExpected, elapsed)
 	}
@@ -200,11 +253,17 @@ func TestIsRetryable(t *testing.T) {
 		{"503 error", &HTTPError{StatusCode: 503, Body: "Service Unavailable"}, true},
 		{"504 error", &HTTPError{StatusCode: 504, Body: "Gateway Timeout"}, true},
 		{"429 error", &HTTPError{StatusCode: 429, Body: "Too Many Requests"}, true},
+		{"408 error", &HTTPError{StatusCode: 408, Body: "Request Timeout"}, true},
 		{"400 error", &HTTPError{StatusCode: 400, Body: "Bad Request"}, false},
 		{"401 error", &HTTPError{StatusCode: 401, Body: "Unauthorized"}, false},
+		{"403 error", &HTTPError{StatusCode: 403, Body: "Forbidden"}, false},
 		{"404 error", &HTTPError{StatusCode: 404, Body: "Not Found"}, false},
+		{"422 error", &HTTPError{StatusCode: 422, Body: "Unprocessable Entity"}, false},
 		{"Network error", errors.New("connection refused"), true},
 		{"Context timeout", context.DeadlineExceeded, true},
+		{"SendError retryable", &SendError{StatusCode: 503, Retryable: true, Err: errors.New("unavailable")}, true},
+		{"SendError permanent", &SendError{StatusCode: 503, Retryable: false, Err: errors.New("unavailable")}, false},
+		{"Wrapped SendError", fmt.Errorf("attempt: %w", &SendError{StatusCode: 400, Err: errors.New("bad request")}), false},
 	}
 
 	for _, tt := range tests {
@@ -217,6 +276,62 @@ func TestIsRetryable(t *testing.T) {
 	}
 }
 
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		header   string
+		expected time.Duration
+	}{
+		{"Empty", "", 0},
+		{"Seconds", "3", 3 * time.Second},
+		{"Zero seconds", "0", 0},
+		{"Negative seconds", "-5", 0},
+		{"HTTP date", "Wed, 15 Jan 2025 10:30:10 GMT", 10 * time.Second},
+		{"HTTP date in the past", "Wed, 15 Jan 2025 10:29:00 GMT", 0},
+		{"Garbage", "soon", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.header, now); got != tt.expected {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.header, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	config := RetryConfig{
+		Enabled:        true,
+		MaxAttempts:    2,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     100 * time.Millisecond,
+		Multiplier:     2.0,
+	}
+	retryer := NewRetryer(config)
+
+	var attemptTimes []time.Time
+	err := retryer.Do(context.Background(), func(ctx context.Context) error {
+		attemptTimes = append(attemptTimes, time.Now())
+		if len(attemptTimes) == 1 {
+			return &HTTPError{StatusCode: 429, Body: "Too Many Requests", RetryAfter: 300 * time.Millisecond}
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected success after retry, got: %v", err)
+	}
+	if len(attemptTimes) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(attemptTimes))
+	}
+	if wait := attemptTimes[1].Sub(attemptTimes[0]); wait < 300*time.Millisecond {
+		t.Errorf("Expected to wait at least Retry-After (300ms), waited %v", wait)
+	}
+}
+
 func TestCalculateBackoff(t *testing.T) {
 	config := RetryConfig{
 		Enabled:        true,
@@ -242,8 +357,205 @@ func TestCalculateBackoff(t *testing.T) {
 
 	// Backoff should not exceed max
 	backoff10 := retryer.calculateBackoff(10)
-	// Max backoff + 25% jitter = 1.25s
-	if backoff10 > config.MaxBackoff+250*time.Millisecond {
-		t.Errorf("Backoff should not exceed max+jitter: got %v", backoff10)
+	if backoff10 > config.MaxBackoff {
+		t.Errorf("Backoff should not exceed max: got %v", backoff10)
+	}
+}
+
+func TestCalculateBackoffJitter(t *testing.T) {
+	tests := []struct {
+		jitter JitterMode
+		minPct float64 // lower bound as fraction of the exponential backoff
+	}{
+		{JitterEqual, 0.5},
+		{JitterFull, 0},
+		{JitterNone, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.jitter), func(t *testing.T) {
+			config := RetryConfig{
+				Enabled:        true,
+				MaxAttempts:    5,
+				InitialBackoff: 100 * time.Millisecond,
+				MaxBackoff:     1 * time.Second,
+				Multiplier:     2.0,
+				Jitter:         tt.jitter,
+			}
+			retryer := NewRetryer(config)
+			retryer.rand = rand.New(rand.NewSource(42))
+
+			for attempt := 1; attempt <= 5; attempt++ {
+				base := config.InitialBackoff << (attempt - 1)
+				if base > config.MaxBackoff {
+					base = config.MaxBackoff
+				}
+				minBackoff := time.Duration(float64(base) * tt.minPct)
+
+				for i := 0; i < 100; i++ {
+					backoff := retryer.calculateBackoff(attempt)
+					if backoff < minBackoff || backoff > base {
+						t.Fatalf("attempt %d: backoff %v outside [%v, %v]", attempt, backoff, minBackoff, base)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRetryQuickRetryOnConnectionError(t *testing.T) {
+	retryer := NewRetryer(RetryConfig{
+		Enabled:        true,
+		MaxAttempts:    3,
+		InitialBackoff: 5 * time.Second,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2.0,
+		QuickRetries:   2,
+		QuickDelay:     20 * time.Millisecond,
+	})
+
+	attempts := 0
+	fn := func(ctx context.Context) error {
+		attempts++
+		if attempts <= 2 {
+			return &net.DNSError{Err: "no such host", Name: "hooks.example.com", IsTemporary: true}
+		}
+		return nil
+	}
+
+	start := time.Now()
+	if err := retryer.Do(context.Background(), fn); err != nil {
+		t.Fatalf("Expected success after quick retries, got %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	// Two quick delays, far below the 5s regular backoff
+	if elapsed < 40*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected quick retry timing around 40ms, got %v", elapsed)
+	}
+}
+
+func TestRetryQuickRetryFallsBackToBackoff(t *testing.T) {
+	retryer := NewRetryer(RetryConfig{
+		Enabled:        true,
+		MaxAttempts:    2,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2.0,
+		Jitter:         JitterNone,
+		QuickRetries:   1,
+		QuickDelay:     10 * time.Millisecond,
+	})
+
+	attempts := 0
+	fn := func(ctx context.Context) error {
+		attempts++
+		return fmt.Errorf("dial: %w", syscall.ECONNREFUSED)
+	}
+
+	start := time.Now()
+	if err := retryer.Do(context.Background(), fn); err == nil {
+		t.Fatal("Expected error after retries are exhausted")
+	}
+	elapsed := time.Since(start)
+
+	// Initial call, one quick retry, then one regular retry after the full backoff
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if elapsed < 110*time.Millisecond {
+		t.Errorf("Expected the regular backoff once quick retries ran out, got %v", elapsed)
+	}
+}
+
+func TestRetryQuickRetryOnlyForConnectionErrors(t *testing.T) {
+	retryer := NewRetryer(RetryConfig{
+		Enabled:        true,
+		MaxAttempts:    2,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2.0,
+		Jitter:         JitterNone,
+		QuickRetries:   3,
+		QuickDelay:     time.Millisecond,
+	})
+
+	attempts := 0
+	start := time.Now()
+	_ = retryer.Do(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return &HTTPError{StatusCode: 503}
+	})
+
+	if attempts != 2 || time.Since(start) < 100*time.Millisecond {
+		t.Errorf("Expected 503s to use only the regular schedule, got %d attempts in %v", attempts, time.Since(start))
+	}
+}
+
+// dnsFailingTransport fails the first `failures` requests with a DNS error, then delegates
+type dnsFailingTransport struct {
+	failures int32
+	calls    atomic.Int32
+	next     http.RoundTripper
+}
+
+func (t *dnsFailingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.calls.Add(1) <= t.failures {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: req.URL.Hostname(), IsTemporary: true}
+	}
+	return t.next.RoundTrip(req)
+}
+
+func TestSenderQuickRetryOnDNSFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Retry = config.RetryConfig{
+		Enabled:        true,
+		MaxAttempts:    3,
+		InitialBackoff: "5s",
+		QuickRetry:     config.QuickRetryConfig{Enabled: true, Attempts: 2, Delay: "50ms"},
+	}
+	transport := &dnsFailingTransport{failures: 1, next: http.DefaultTransport}
+	sender := New(cfg, WithTransport(transport))
+
+	start := time.Now()
+	if err := sender.Send(analyzer.StatusTaskComplete, "msg", "session-1"); err != nil {
+		t.Fatalf("Expected send to succeed after a quick retry, got %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if transport.calls.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %d", transport.calls.Load())
+	}
+	if elapsed < 50*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Expected the 50ms quick retry delay instead of the 5s backoff, got %v", elapsed)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"dns", &net.DNSError{Err: "no such host"}, true},
+		{"wrapped dns", newSendError("req", "default", 0, true, fmt.Errorf("HTTP request failed: %w", &net.DNSError{})), true},
+		{"connection refused", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, true},
+		{"http 503", &HTTPError{StatusCode: 503}, false},
+		{"timeout", context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isConnectionError(tt.err); got != tt.want {
+				t.Errorf("isConnectionError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
 	}
 }
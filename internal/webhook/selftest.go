@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/google/uuid"
+)
+
+// SelfTestStatus is the status of self-test notifications
+const SelfTestStatus analyzer.Status = "self_test"
+
+// selfTestSessionID is the session ID carried by self-test notifications
+const selfTestSessionID = "self-test"
+
+// selfTestMessage is the body of self-test notifications
+const selfTestMessage = "This is a test notification from claude-notifications. If you can read this, webhooks are working."
+
+// selfTestStatusInfo is used when the config has no entry for SelfTestStatus
+var selfTestStatusInfo = config.StatusInfo{Title: "🧪 Test Notification"}
+
+// SelfTest sends a test notification to every configured destination and reports any failure
+// It exercises the URL, headers, formatter, signing and response validation, but skips
+// muting, quiet hours, batching, rate limiting, the circuit breaker and retries,
+// so the test always fires and the first failure is reported as-is
+func (s *Sender) SelfTest(ctx context.Context) error {
+	if config.NotificationsDisabled() {
+		return fmt.Errorf("notifications are disabled by %s", config.DisabledEnv)
+	}
+	if !s.cfg.IsWebhookEnabled() {
+		return errors.New("webhooks are disabled in config")
+	}
+	if s.initErr != nil {
+		return fmt.Errorf("webhook sender misconfigured: %w", s.initErr)
+	}
+	if len(s.destinations) == 0 {
+		return errors.New("no webhook destinations configured")
+	}
+
+	requestID := uuid.New().String()
+	details := resolveDetails(Details{})
+
+	var errs []error
+	for _, dest := range s.destinations {
+		logging.Info("[%s] Sending self-test webhook to %s", requestID, dest.Name)
+		if err := s.selfTestDestination(ctx, requestID, dest, details); err != nil {
+			errs = append(errs, &DestinationError{Destination: dest.Name, Err: err})
+		}
+	}
+
+	return joinDestinationErrors(errs)
+}
+
+// selfTestDestination sends the test notification to a single destination
+func (s *Sender) selfTestDestination(ctx context.Context, requestID string, dest destination, details Details) error {
+	payload, contentType, err := s.buildPayload(dest, SelfTestStatus, selfTestMessage, selfTestSessionID, details)
+	if err != nil {
+		return fmt.Errorf("failed to build payload: %w", err)
+	}
+	if err := validateURL(dest.URL); err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	_, err = s.sendHTTPRequest(ctx, requestID, selfTestSessionID, dest, payload, contentType)
+	return err
+}
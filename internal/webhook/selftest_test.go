@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestSenderSelfTest(t *testing.T) {
+	requests := atomic.Int32{}
+	var body map[string]interface{}
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Headers = map[string]string{"Authorization": "Bearer secret"}
+	// Suppression that would drop a normal notification
+	cfg.Notifications.MutedStatuses = []string{"task_complete"}
+	cfg.Notifications.Webhook.QuietHours = config.QuietHoursConfig{Enabled: true, Start: "00:00", End: "00:00"}
+	cfg.Notifications.Webhook.Batch = config.BatchConfig{Enabled: true, Window: "1h"}
+	sender := New(cfg)
+
+	if err := sender.SelfTest(context.Background()); err != nil {
+		t.Fatalf("Expected self-test to succeed, got %v", err)
+	}
+
+	if requests.Load() != 1 {
+		t.Fatalf("Expected 1 self-test request, got %d", requests.Load())
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Expected configured headers on self-test request, got %q", auth)
+	}
+	if body["status"] != string(SelfTestStatus) {
+		t.Errorf("Expected self-test status, got %v", body["status"])
+	}
+	if msg, _ := body["message"].(string); !strings.Contains(msg, "test notification") {
+		t.Errorf("Expected clearly labeled test message, got %q", msg)
+	}
+}
+
+func TestSenderSelfTestReportsFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("invalid token"))
+	}))
+	defer server.Close()
+
+	sender := New(newTestConfig(server.URL))
+
+	err := sender.SelfTest(context.Background())
+	if err == nil {
+		t.Fatal("Expected self-test to fail")
+	}
+
+	var sendErr *SendError
+	if !errors.As(err, &sendErr) || sendErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected SendError with status 401, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "destination default") || !strings.Contains(err.Error(), "invalid token") {
+		t.Errorf("Expected descriptive error, got %q", err.Error())
+	}
+	if stats := sender.GetMetrics(); stats.TotalRequests != 0 {
+		t.Errorf("Expected self-test not to be counted in metrics, got %d", stats.TotalRequests)
+	}
+}
+
+func TestSenderSelfTestDisabled(t *testing.T) {
+	cfg := newTestConfig("https://example.com/webhook")
+	cfg.Notifications.Webhook.Enabled = false
+
+	if err := New(cfg).SelfTest(context.Background()); err == nil {
+		t.Error("Expected self-test to fail when webhooks are disabled")
+	}
+}
+
+func TestSenderSelfTestDisabledByEnv(t *testing.T) {
+	t.Setenv(config.DisabledEnv, "1")
+	cfg := newTestConfig("https://example.com/webhook")
+
+	err := New(cfg).SelfTest(context.Background())
+	if err == nil {
+		t.Fatal("Expected self-test to fail when notifications are disabled by the environment")
+	}
+	if !strings.Contains(err.Error(), config.DisabledEnv) {
+		t.Errorf("Expected error to name %s, got %v", config.DisabledEnv, err)
+	}
+}
@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// Signer computes HMAC signatures for outgoing webhook payloads
+type Signer struct {
+	secret  []byte
+	header  string
+	newHash func() hash.Hash
+}
+
+// NewSigner creates a signer from config
+// Returns nil if no secret is configured (signing disabled)
+func NewSigner(cfg config.SigningConfig) *Signer {
+	if cfg.Secret == "" {
+		return nil
+	}
+
+	header := cfg.Header
+	if header == "" {
+		header = "X-Signature"
+	}
+
+	newHash := sha256.New
+	if cfg.Algorithm == "sha1" {
+		newHash = sha1.New
+	}
+
+	return &Signer{
+		secret:  []byte(cfg.Secret),
+		header:  header,
+		newHash: newHash,
+	}
+}
+
+// Sign returns the hex-encoded HMAC of the payload
+func (s *Signer) Sign(payload []byte) string {
+	mac := hmac.New(s.newHash, s.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Header returns the name of the header carrying the signature
+func (s *Signer) Header() string {
+	return s.header
+}
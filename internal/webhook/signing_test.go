@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+func TestNewSignerDisabledWithoutSecret(t *testing.T) {
+	if signer := NewSigner(config.SigningConfig{}); signer != nil {
+		t.Error("Expected nil signer when no secret is configured")
+	}
+}
+
+func TestSignerSign(t *testing.T) {
+	payload := []byte("The quick brown fox jumps over the lazy dog")
+
+	tests := []struct {
+		name      string
+		algorithm string
+		expected  string
+	}{
+		{"default is sha256", "", "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+		{"sha256", "sha256", "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"},
+		{"sha1", "sha1", "de7c9b85b8b78aa6bc8a7a36f70a90701c9db4d9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := NewSigner(config.SigningConfig{Secret: "key", Algorithm: tt.algorithm})
+			if got := signer.Sign(payload); got != tt.expected {
+				t.Errorf("Expected signature %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSignerDefaultHeader(t *testing.T) {
+	signer := NewSigner(config.SigningConfig{Secret: "key"})
+	if signer.Header() != "X-Signature" {
+		t.Errorf("Expected default header X-Signature, got %s", signer.Header())
+	}
+}
+
+func TestSenderSendSignsPayload(t *testing.T) {
+	var signature, customSignature string
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Hub-Signature")
+		customSignature = r.Header.Get("X-Signature")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Signing = config.SigningConfig{
+		Secret: "shared-secret",
+		Header: "X-Hub-Signature",
+	}
+	sender := New(cfg)
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	expected := NewSigner(cfg.Notifications.Webhook.Signing).Sign(body)
+	if signature != expected {
+		t.Errorf("Expected signature %s, got %s", expected, signature)
+	}
+	if customSignature != "" {
+		t.Errorf("Expected no X-Signature header, got %s", customSignature)
+	}
+}
+
+func TestSenderSendWithoutSigning(t *testing.T) {
+	var signature string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Signature")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sender := New(newTestConfig(server.URL))
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if signature != "" {
+		t.Errorf("Expected no signature header, got %s", signature)
+	}
+}
@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/platform"
+	"github.com/google/uuid"
+)
+
+const (
+	// spoolReplayDelay is how old a spooled entry must be before another process replays it.
+	// Younger entries may still be in flight in the process that spooled them.
+	spoolReplayDelay = 30 * time.Second
+
+	spoolPrefix        = "spool-"
+	spoolSuffix        = ".json"
+	spoolClaimSuffix   = ".claimed"
+	defaultSpoolMaxAge = time.Hour
+)
+
+// spoolSubdir is the spool directory inside the state directory
+const spoolSubdir = "spool"
+
+// DefaultSpoolDir returns the default directory for spooled webhook notifications,
+// inside stateDir (state.dir) so one setting moves all on-disk state
+// An empty stateDir uses the default state directory
+func DefaultSpoolDir(stateDir string) string {
+	if stateDir == "" {
+		stateDir = filepath.Join(platform.TempDir(), platform.StateSubdir)
+	}
+	return filepath.Join(stateDir, spoolSubdir)
+}
+
+// legacySpoolDir is where older versions kept the spool by default
+func legacySpoolDir() string {
+	return filepath.Join(platform.TempDir(), "claude-notifications-spool")
+}
+
+// Spool persists pending async notifications on disk so they survive process exit
+// Entries are written before sending and removed once the send completes,
+// giving at-least-once delivery when a hook process is terminated early
+type Spool struct {
+	dir    string
+	maxAge time.Duration
+}
+
+// spoolEntry is a notification waiting to be delivered
+type spoolEntry struct {
+	Status    analyzer.Status `json:"status"`
+	Message   string          `json:"message"`
+	SessionID string          `json:"session_id"`
+	CWD       string          `json:"cwd,omitempty"`
+	CreatedAt int64           `json:"created_at"`
+
+	path string // location on disk, set when enqueued or claimed
+}
+
+// NewSpool creates a spool in dir, creating the directory if needed
+// Entries older than maxAge are discarded instead of replayed
+func NewSpool(dir string, maxAge time.Duration) (*Spool, error) {
+	if maxAge <= 0 {
+		maxAge = defaultSpoolMaxAge
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	return &Spool{
+		dir:    dir,
+		maxAge: maxAge,
+	}, nil
+}
+
+// Enqueue writes a notification to the spool atomically (temp file + rename)
+func (sp *Spool) Enqueue(status analyzer.Status, message, sessionID, cwd string) (*spoolEntry, error) {
+	entry := &spoolEntry{
+		Status:    status,
+		Message:   message,
+		SessionID: sessionID,
+		CWD:       cwd,
+		CreatedAt: platform.CurrentTimestamp(),
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize spool entry: %w", err)
+	}
+
+	path := filepath.Join(sp.dir, spoolPrefix+uuid.New().String()+spoolSuffix)
+	tmpPath := path + ".tmp"
+
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write spool entry: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to commit spool entry: %w", err)
+	}
+
+	entry.path = path
+	return entry, nil
+}
+
+// Remove deletes a delivered entry from the spool
+func (sp *Spool) Remove(entry *spoolEntry) error {
+	if entry.path == "" {
+		return nil
+	}
+	if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove spool entry: %w", err)
+	}
+	return nil
+}
+
+// Claim takes ownership of undelivered entries left behind by earlier processes
+// Each entry is claimed by an atomic rename, so concurrent processes never replay the same entry.
+// Expired entries are discarded. Claimed entries abandoned by a crashed replay are removed once expired.
+func (sp *Spool) Claim() ([]*spoolEntry, error) {
+	matches, err := filepath.Glob(filepath.Join(sp.dir, spoolPrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*spoolEntry
+	for _, path := range matches {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // Claimed or removed by another process
+		}
+		age := time.Since(info.ModTime())
+
+		if age > sp.maxAge {
+			_ = os.Remove(path)
+			continue
+		}
+
+		// Skip temp files, claimed entries, and entries possibly still in flight
+		if !strings.HasSuffix(path, spoolSuffix) || age < spoolReplayDelay {
+			continue
+		}
+
+		claimedPath := path + spoolClaimSuffix
+		if err := os.Rename(path, claimedPath); err != nil {
+			continue // Another process claimed it first
+		}
+
+		data, err := os.ReadFile(claimedPath)
+		if err != nil {
+			continue
+		}
+
+		var entry spoolEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			_ = os.Remove(claimedPath) // Corrupt entry can never be delivered
+			continue
+		}
+		entry.path = claimedPath
+		entries = append(entries, &entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,263 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// ageSpoolEntry backdates an entry so it becomes eligible for replay
+func ageSpoolEntry(t *testing.T, entry *spoolEntry, age time.Duration) {
+	t.Helper()
+	old := time.Now().Add(-age)
+	if err := os.Chtimes(entry.path, old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+}
+
+func spoolFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, spoolPrefix+"*"))
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	return matches
+}
+
+func TestSpoolEnqueueRemove(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := NewSpool(dir, time.Hour)
+	if err != nil {
+		t.Fatalf("NewSpool failed: %v", err)
+	}
+
+	entry, err := spool.Enqueue(analyzer.StatusTaskComplete, "Done", "session-1", "")
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if files := spoolFiles(t, dir); len(files) != 1 {
+		t.Fatalf("expected 1 spool file, got %d", len(files))
+	}
+
+	if err := spool.Remove(entry); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if files := spoolFiles(t, dir); len(files) != 0 {
+		t.Errorf("expected empty spool, got %v", files)
+	}
+}
+
+func TestSpoolClaim(t *testing.T) {
+	dir := t.TempDir()
+	spool, _ := NewSpool(dir, time.Hour)
+
+	fresh, _ := spool.Enqueue(analyzer.StatusQuestion, "Fresh", "session-fresh", "")
+	stale, _ := spool.Enqueue(analyzer.StatusTaskComplete, "Stale", "session-stale", "")
+	expired, _ := spool.Enqueue(analyzer.StatusTaskComplete, "Expired", "session-expired", "")
+	ageSpoolEntry(t, stale, time.Minute)
+	ageSpoolEntry(t, expired, 2*time.Hour)
+
+	entries, err := spool.Claim()
+	if err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 claimed entry, got %d", len(entries))
+	}
+	if entries[0].SessionID != "session-stale" || entries[0].Message != "Stale" || entries[0].Status != analyzer.StatusTaskComplete {
+		t.Errorf("unexpected claimed entry: %+v", entries[0])
+	}
+
+	// Fresh entry may still be in flight and must stay untouched
+	if _, err := os.Stat(fresh.path); err != nil {
+		t.Errorf("fresh entry should remain: %v", err)
+	}
+	if _, err := os.Stat(expired.path); !os.IsNotExist(err) {
+		t.Error("expired entry should be discarded")
+	}
+
+	// Claimed entry is not handed out again
+	again, _ := spool.Claim()
+	if len(again) != 0 {
+		t.Errorf("expected no entries on second claim, got %d", len(again))
+	}
+}
+
+func TestSpoolClaimConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	spool, _ := NewSpool(dir, time.Hour)
+
+	for i := 0; i < 10; i++ {
+		entry, _ := spool.Enqueue(analyzer.StatusTaskComplete, "msg", "session", "")
+		ageSpoolEntry(t, entry, time.Minute)
+	}
+
+	var claimed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			other, _ := NewSpool(dir, time.Hour)
+			entries, _ := other.Claim()
+			atomic.AddInt32(&claimed, int32(len(entries)))
+		}()
+	}
+	wg.Wait()
+
+	if claimed != 10 {
+		t.Errorf("expected each entry claimed exactly once (10), got %d", claimed)
+	}
+}
+
+func newSpoolTestConfig(url, dir string) *config.Config {
+	cfg := newTestConfig(url)
+	cfg.Notifications.Webhook.Spool = config.SpoolConfig{
+		Enabled: true,
+		Dir:     dir,
+	}
+	return cfg
+}
+
+func TestSenderReplaysSpool(t *testing.T) {
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Simulate a previous process that exited before delivering
+	dir := t.TempDir()
+	spool, _ := NewSpool(dir, time.Hour)
+	entry, _ := spool.Enqueue(analyzer.StatusTaskComplete, "Left behind", "session-old", "")
+	ageSpoolEntry(t, entry, time.Minute)
+
+	sender := New(newSpoolTestConfig(server.URL, dir))
+	if err := sender.Shutdown(2 * time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if atomic.LoadInt32(&received) != 1 {
+		t.Errorf("expected spooled notification to be replayed once, got %d", received)
+	}
+	if files := spoolFiles(t, dir); len(files) != 0 {
+		t.Errorf("expected spool to be empty after replay, got %v", files)
+	}
+}
+
+func TestSenderSpoolFollowsStateDir(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	stateDir := t.TempDir()
+	cfg := newSpoolTestConfig(server.URL, "")
+	cfg.State.Dir = stateDir
+	sender := New(cfg)
+
+	sender.SendAsync(analyzer.StatusTaskComplete, "Test", "session-123")
+	if files := spoolFiles(t, filepath.Join(stateDir, "spool")); len(files) != 1 {
+		t.Errorf("Expected the notification spooled under state.dir, got %d files", len(files))
+	}
+	if err := sender.Shutdown(2 * time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+}
+
+func TestSenderSendAsyncSpool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	sender := New(newSpoolTestConfig(server.URL, dir))
+
+	sender.SendAsync(analyzer.StatusTaskComplete, "Test", "session-123")
+	if files := spoolFiles(t, dir); len(files) != 1 {
+		t.Fatalf("expected notification to be spooled before sending, got %d files", len(files))
+	}
+
+	if err := sender.Shutdown(2 * time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if files := spoolFiles(t, dir); len(files) != 0 {
+		t.Errorf("expected delivered notification to be removed from spool, got %v", files)
+	}
+}
+
+func TestSenderSendAsyncSpoolKeptOnShutdownTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	dir := t.TempDir()
+	sender := New(newSpoolTestConfig(server.URL, dir))
+
+	sender.SendAsync(analyzer.StatusTaskComplete, "Test", "session-123")
+	time.Sleep(20 * time.Millisecond) // Let request start
+
+	if err := sender.Shutdown(50 * time.Millisecond); err == nil {
+		t.Fatal("expected shutdown timeout")
+	}
+
+	// Wait for the interrupted goroutine to settle
+	time.Sleep(100 * time.Millisecond)
+
+	if files := spoolFiles(t, dir); len(files) != 1 {
+		t.Errorf("expected interrupted notification to stay spooled, got %d files", len(files))
+	}
+}
+
+func TestSenderSendAsyncSpoolKeptUntilBatchFlush(t *testing.T) {
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	cfg := newSpoolTestConfig(server.URL, dir)
+	cfg.Notifications.Webhook.Batch = config.BatchConfig{Enabled: true, Window: "1h"}
+	sender := New(cfg)
+
+	sender.SendAsync(analyzer.StatusTaskComplete, "One", "session-123")
+	sender.SendAsync(analyzer.StatusTaskComplete, "Two", "session-123")
+	time.Sleep(50 * time.Millisecond) // Let both sends join the batch
+
+	// The batch is still waiting for its window, so both entries must survive a process exit
+	if files := spoolFiles(t, dir); len(files) != 2 {
+		t.Fatalf("expected batched notifications to stay spooled until flushed, got %d files", len(files))
+	}
+
+	if err := sender.Shutdown(2 * time.Second); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if atomic.LoadInt32(&received) != 1 {
+		t.Errorf("expected one combined request, got %d", received)
+	}
+	if files := spoolFiles(t, dir); len(files) != 0 {
+		t.Errorf("expected delivered batch to be removed from spool, got %v", files)
+	}
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// templateData holds the fields available to payload templates
+type templateData struct {
+	Status    string
+	Title     string
+	Message   string
+	SessionID string
+	Timestamp string // RFC3339
+	Project   string // project name derived from the session's working directory
+	GitBranch string // empty when not in a git repository
+	GitCommit string // short commit hash
+	Priority  string // min, low, default, high or urgent
+}
+
+// templateFuncs are helper functions available to payload templates
+var templateFuncs = template.FuncMap{
+	// json encodes a value as JSON, e.g. {"text": {{json .Message}}}
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// parsePayloadTemplate parses a payload template and checks it executes against templateData
+// so that references to unknown fields fail at construction rather than at send time
+func parsePayloadTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload template: %w", err)
+	}
+
+	if err := tmpl.Execute(io.Discard, templateData{}); err != nil {
+		return nil, fmt.Errorf("invalid payload template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// parseFooterTemplate parses a footer template, checked against templateData like payload templates
+func parseFooterTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name + "-footer").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid footer template: %w", err)
+	}
+
+	if err := tmpl.Execute(io.Discard, templateData{}); err != nil {
+		return nil, fmt.Errorf("invalid footer template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// renderFooter executes a footer template, trimming surrounding whitespace
+func renderFooter(tmpl *template.Template, data templateData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render footer template: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// renderPayloadTemplate executes a payload template
+func renderPayloadTemplate(tmpl *template.Template, data templateData) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render payload template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
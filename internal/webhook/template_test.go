@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+)
+
+func TestRenderPayloadTemplate(t *testing.T) {
+	tmpl, err := parsePayloadTemplate("test", `{"text": {{json .Message}}, "status": "{{.Status}}", "title": {{json .Title}}}`)
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %v", err)
+	}
+
+	data, err := renderPayloadTemplate(tmpl, templateData{
+		Status:  "task_complete",
+		Title:   "Task Complete",
+		Message: `Fixed "quoted" bug`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected render error: %v", err)
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("Rendered template should be valid JSON: %v (%s)", err, data)
+	}
+	if payload["text"] != `Fixed "quoted" bug` {
+		t.Errorf("Expected escaped message, got %q", payload["text"])
+	}
+	if payload["status"] != "task_complete" || payload["title"] != "Task Complete" {
+		t.Errorf("Unexpected payload: %v", payload)
+	}
+}
+
+func TestRenderPayloadTemplateGitBranch(t *testing.T) {
+	tmpl, err := parsePayloadTemplate("test", `{"branch": {{json .GitBranch}}, "session": {{json .SessionID}}}`)
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %v", err)
+	}
+
+	data, err := renderPayloadTemplate(tmpl, templateData{SessionID: "session-123", GitBranch: "feature/login"})
+	if err != nil {
+		t.Fatalf("Unexpected render error: %v", err)
+	}
+
+	if string(data) != `{"branch": "feature/login", "session": "session-123"}` {
+		t.Errorf("Unexpected rendered payload: %s", data)
+	}
+}
+
+func TestParsePayloadTemplateInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"syntax error", `{"text": {{.Message}`},
+		{"unknown field", `{"text": {{.Body}}}`},
+		{"unknown function", `{"text": {{yaml .Message}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parsePayloadTemplate("test", tt.text); err == nil {
+				t.Error("Expected error for malformed template")
+			}
+		})
+	}
+}
+
+func TestSenderTemplateFormat(t *testing.T) {
+	var received map[string]string
+	var contentType string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Format = "template"
+	cfg.Notifications.Webhook.Template = `{"msg": {{json .Message}}, "branch": {{json .GitBranch}}}`
+
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+
+	details := Details{CWD: newGitRepo(t, "feature/login")}
+	if err := sender.SendWithDetails(analyzer.StatusTaskComplete, "Done", "session-123", details); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Expected application/json, got %s", contentType)
+	}
+	if received["msg"] != "Done" {
+		t.Errorf("Expected msg 'Done', got %v", received)
+	}
+	if received["branch"] != "feature/login" {
+		t.Errorf("Expected branch 'feature/login', got %q", received["branch"])
+	}
+}
+
+func TestNewSenderMalformedTemplate(t *testing.T) {
+	cfg := newTestConfig("https://example.com/webhook")
+	cfg.Notifications.Webhook.Format = "template"
+	cfg.Notifications.Webhook.Template = `{"text": {{.Nope}}}`
+
+	if _, err := NewSender(cfg); err == nil || !strings.Contains(err.Error(), "invalid payload template") {
+		t.Fatalf("Expected construction error for malformed template, got %v", err)
+	}
+
+	// New never fails, but the sender refuses to send
+	sender := New(cfg)
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123"); err == nil {
+		t.Error("Expected Send to return construction error")
+	}
+}
+
+func TestSenderFooterTemplateSlack(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "slack"
+	cfg.Notifications.Webhook.FooterTemplate = `{{.Project}} on {{.GitBranch}}`
+
+	sender, err := NewSender(cfg)
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+
+	details := Details{Project: "my-app", GitBranch: "feature/login"}
+	if err := sender.SendWithDetails(analyzer.StatusTaskComplete, "Done", "session-123", details); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	attachments, _ := received["attachments"].([]interface{})
+	if len(attachments) != 1 {
+		t.Fatalf("Expected one attachment, got %v", received)
+	}
+	attachment := attachments[0].(map[string]interface{})
+	if attachment["footer"] != "my-app on feature/login" {
+		t.Errorf("Expected footer from template, got %v", attachment["footer"])
+	}
+	if _, ok := attachment["fields"]; ok {
+		t.Errorf("Expected the footer template to replace the project and branch fields, got %v", attachment["fields"])
+	}
+}
+
+func TestFooterTemplateHidesFooter(t *testing.T) {
+	cfg := newTestConfig("https://example.com/webhook")
+	cfg.Notifications.Webhook.Preset = "slack"
+	cfg.Notifications.Webhook.SlackBlocks = true
+	cfg.Notifications.Webhook.FooterTemplate = `{{/* no footer */}}`
+
+	data, _, err := New(cfg).Preview(analyzer.StatusTaskComplete, "Done", "session-123")
+	if err != nil {
+		t.Fatalf("Preview failed: %v", err)
+	}
+	if strings.Contains(string(data), "context") || strings.Contains(string(data), "session-123") {
+		t.Errorf("Expected an empty footer to leave out the context block, got %s", data)
+	}
+}
+
+func TestNewSenderMalformedFooterTemplate(t *testing.T) {
+	cfg := newTestConfig("https://example.com/webhook")
+	cfg.Notifications.Webhook.Preset = "slack"
+	cfg.Notifications.Webhook.FooterTemplate = `{{.Session}}`
+
+	if _, err := NewSender(cfg); err == nil || !strings.Contains(err.Error(), "invalid footer template") {
+		t.Fatalf("Expected construction error for malformed footer template, got %v", err)
+	}
+}
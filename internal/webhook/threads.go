@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"encoding/json"
+
+	"github.com/777genius/claude-notifications/internal/logging"
+)
+
+// ThreadStore remembers which thread a session's notifications reply in, per destination
+// state.Manager implements it, so threads survive across hook processes
+type ThreadStore interface {
+	ThreadID(sessionID, destination string) (string, error)
+	SetThreadID(sessionID, destination, threadID string) error
+}
+
+// WithThreadStore enables threaded replies for destinations configured with slackThreads
+// Without a store every notification starts a new thread
+func WithThreadStore(store ThreadStore) Option {
+	return func(s *Sender) {
+		s.threads = store
+	}
+}
+
+// threaded reports whether notifications to the destination reply in a per-session thread
+func (d destination) threaded() bool {
+	return d.Preset == "slack" && d.SlackThreads
+}
+
+// threadID returns the session's thread for the destination, or empty string to start one
+func (s *Sender) threadID(dest destination, sessionID string) string {
+	if s.threads == nil || !dest.threaded() {
+		return ""
+	}
+	threadID, err := s.threads.ThreadID(sessionID, dest.Name)
+	if err != nil {
+		logging.Warn("Failed to load thread for session %s: %v", sessionID, err)
+		return ""
+	}
+	return threadID
+}
+
+// rememberThread stores the ts of a session's first Slack message so later ones reply under it
+// body is the chat.postMessage response, e.g. {"ok": true, "channel": "C123", "ts": "1700000000.000100"}
+func (s *Sender) rememberThread(dest destination, sessionID string, body []byte) {
+	if s.threads == nil || !dest.threaded() {
+		return
+	}
+
+	var resp struct {
+		TS string `json:"ts"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.TS == "" {
+		logging.Warn("No message ts in Slack response for %s, next notification starts a new thread", dest.Name)
+		return
+	}
+	if err := s.threads.SetThreadID(sessionID, dest.Name, resp.TS); err != nil {
+		logging.Warn("Failed to save thread for session %s: %v", sessionID, err)
+	}
+}
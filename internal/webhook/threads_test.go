@@ -0,0 +1,151 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/state"
+)
+
+// fakeSlackAPI mimics chat.postMessage, answering each post with a new message ts
+type fakeSlackAPI struct {
+	mu       sync.Mutex
+	payloads []map[string]interface{}
+	reply    string // response body override, e.g. an {"ok": false} error
+}
+
+func (f *fakeSlackAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var payload map[string]interface{}
+	_ = json.Unmarshal(body, &payload)
+
+	f.mu.Lock()
+	f.payloads = append(f.payloads, payload)
+	n := len(f.payloads)
+	reply := f.reply
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if reply == "" {
+		reply = fmt.Sprintf(`{"ok": true, "channel": "C123", "ts": "1700000000.00010%d"}`, n)
+	}
+	_, _ = io.WriteString(w, reply)
+}
+
+func (f *fakeSlackAPI) payload(i int) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.payloads[i]
+}
+
+func newSlackThreadsSender(t *testing.T, url string, store ThreadStore) *Sender {
+	t.Helper()
+	cfg := newTestConfig(url)
+	cfg.Notifications.Webhook.Preset = "slack"
+	cfg.Notifications.Webhook.ChatID = "C123"
+	cfg.Notifications.Webhook.SlackThreads = true
+	cfg.Notifications.Webhook.Retry.Enabled = false
+	return New(cfg, WithThreadStore(store))
+}
+
+func TestSenderSlackThreads(t *testing.T) {
+	api := &fakeSlackAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	store := state.NewManagerWithStore(state.NewMemoryStore())
+	sender := newSlackThreadsSender(t, server.URL, store)
+
+	for i := 0; i < 3; i++ {
+		if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-1"); err != nil {
+			t.Fatalf("Send %d failed: %v", i, err)
+		}
+	}
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-2"); err != nil {
+		t.Fatalf("Send for second session failed: %v", err)
+	}
+
+	first := api.payload(0)
+	if first["channel"] != "C123" {
+		t.Errorf("Expected channel C123, got %v", first["channel"])
+	}
+	if _, ok := first["thread_ts"]; ok {
+		t.Errorf("Expected the first message to start a thread, got thread_ts %v", first["thread_ts"])
+	}
+
+	// Replies thread under the first message, not under each other
+	for i := 1; i < 3; i++ {
+		if got := api.payload(i)["thread_ts"]; got != "1700000000.000101" {
+			t.Errorf("Expected send %d to reply in thread 1700000000.000101, got %v", i, got)
+		}
+	}
+
+	if _, ok := api.payload(3)["thread_ts"]; ok {
+		t.Errorf("Expected another session to start its own thread, got %v", api.payload(3)["thread_ts"])
+	}
+
+	// The thread is kept in session state, so a new process continues it
+	sessionState, err := store.Load("session-1")
+	if err != nil || sessionState == nil {
+		t.Fatalf("Expected session state, got %v, %v", sessionState, err)
+	}
+	if got := sessionState.Threads["default"]; got != "1700000000.000101" {
+		t.Errorf("Expected stored thread 1700000000.000101, got %q", got)
+	}
+
+	if err := newSlackThreadsSender(t, server.URL, store).Send(analyzer.StatusQuestion, "Which one?", "session-1"); err != nil {
+		t.Fatalf("Send from new sender failed: %v", err)
+	}
+	if got := api.payload(4)["thread_ts"]; got != "1700000000.000101" {
+		t.Errorf("Expected new sender to reply in the stored thread, got %v", got)
+	}
+}
+
+func TestSenderSlackThreadsAPIError(t *testing.T) {
+	api := &fakeSlackAPI{reply: `{"ok": false, "error": "channel_not_found"}`}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	store := state.NewManagerWithStore(state.NewMemoryStore())
+	sender := newSlackThreadsSender(t, server.URL, store)
+
+	// chat.postMessage reports errors in a 200 body
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-1"); err == nil {
+		t.Error("Expected an {\"ok\": false} response to fail the send")
+	}
+	if threadID, _ := store.ThreadID("session-1", "default"); threadID != "" {
+		t.Errorf("Expected no thread stored after a failed send, got %q", threadID)
+	}
+}
+
+func TestSenderSlackWithoutThreads(t *testing.T) {
+	api := &fakeSlackAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	store := state.NewManagerWithStore(state.NewMemoryStore())
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "slack"
+	sender := New(cfg, WithThreadStore(store))
+
+	for i := 0; i < 2; i++ {
+		if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-1"); err != nil {
+			t.Fatalf("Send %d failed: %v", i, err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		payload := api.payload(i)
+		if _, ok := payload["thread_ts"]; ok {
+			t.Errorf("Expected no thread_ts without slackThreads, got %v", payload["thread_ts"])
+		}
+		if _, ok := payload["channel"]; ok {
+			t.Errorf("Expected no channel without slackThreads, got %v", payload["channel"])
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/config"
+)
+
+// writeServerCA writes the httptest TLS server's certificate as a CA bundle
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+	return path
+}
+
+// writeClientCert generates a self-signed client certificate and returns its cert/key paths and pool
+func writeClientCert(t *testing.T) (string, string, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "claude-notifications-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "client.pem")
+	keyPath := filepath.Join(dir, "client-key.pem")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("Failed to write cert: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return certPath, keyPath, pool
+}
+
+func newTLSTestConfig(url string) *config.Config {
+	cfg := newTestConfig(url)
+	cfg.Notifications.Webhook.Retry.Enabled = false
+	return cfg
+}
+
+func TestSenderTLSCustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Without the CA the private certificate is rejected
+	if err := New(newTLSTestConfig(server.URL)).Send(analyzer.StatusTaskComplete, "msg", "session-1"); err == nil {
+		t.Fatal("Expected certificate verification to fail without the CA")
+	}
+
+	cfg := newTLSTestConfig(server.URL)
+	cfg.Notifications.Webhook.TLS = config.TLSConfig{CAFile: writeServerCA(t, server)}
+	if err := New(cfg).Send(analyzer.StatusTaskComplete, "msg", "session-1"); err != nil {
+		t.Fatalf("Expected request trusted via custom CA to succeed, got %v", err)
+	}
+}
+
+func TestSenderTLSClientCertificate(t *testing.T) {
+	certPath, keyPath, clientCAs := writeClientCert(t)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	cfg := newTLSTestConfig(server.URL)
+	cfg.Notifications.Webhook.TLS = config.TLSConfig{CAFile: writeServerCA(t, server)}
+	if err := New(cfg).Send(analyzer.StatusTaskComplete, "msg", "session-1"); err == nil {
+		t.Fatal("Expected server to reject a client without a certificate")
+	}
+
+	cfg.Notifications.Webhook.TLS.CertFile = certPath
+	cfg.Notifications.Webhook.TLS.KeyFile = keyPath
+	if err := New(cfg).Send(analyzer.StatusTaskComplete, "msg", "session-1"); err != nil {
+		t.Fatalf("Expected mutual TLS request to succeed, got %v", err)
+	}
+}
+
+func TestSenderTLSInsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTLSTestConfig(server.URL)
+	cfg.Notifications.Webhook.TLS = config.TLSConfig{InsecureSkipVerify: true}
+	if err := New(cfg).Send(analyzer.StatusTaskComplete, "msg", "session-1"); err != nil {
+		t.Fatalf("Expected insecure request to succeed, got %v", err)
+	}
+}
+
+func TestNewTLSConfigErrors(t *testing.T) {
+	if tlsConfig, err := newTLSConfig(config.TLSConfig{}); tlsConfig != nil || err != nil {
+		t.Errorf("Expected no TLS config by default, got %v, %v", tlsConfig, err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.TLSConfig
+		want string
+	}{
+		{"missing CA file", config.TLSConfig{CAFile: "/nonexistent/ca.pem"}, "failed to read webhook TLS CA file"},
+		{"cert without key", config.TLSConfig{CertFile: "/tmp/cert.pem"}, "must be set together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTLSConfig(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Construction errors surface from NewSender
+	cfg := newTLSTestConfig("https://example.com/webhook")
+	cfg.Notifications.Webhook.TLS = config.TLSConfig{CAFile: "/nonexistent/ca.pem"}
+	if _, err := NewSender(cfg); err == nil {
+		t.Error("Expected NewSender to fail with an unreadable CA file")
+	}
+}
@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/google/uuid"
+)
+
+// Update replaces the session's last notification instead of posting a new one
+// See UpdateWithDetails
+func (s *Sender) Update(status analyzer.Status, message, sessionID string) error {
+	return s.UpdateWithDetails(status, message, sessionID, Details{})
+}
+
+// UpdateWithDetails edits the session's last notification in place, using the message ID
+// recorded via messageRefPath (see WithMessageRefStore), e.g. Telegram editMessageText or Discord PATCH.
+// Without a recorded ID, for a destination that can't edit, or if the edit fails,
+// a new notification is sent with SendWithDetails instead.
+// Edits skip batching and the circuit breaker, but not muting, quiet hours or rate limits.
+func (s *Sender) UpdateWithDetails(status analyzer.Status, message, sessionID string, details Details) error {
+	receipt := newReceipt(status, sessionID)
+	if s.disabled(status) {
+		return s.skip(receipt, SkipDisabled)
+	}
+
+	if s.initErr != nil {
+		return s.finish(receipt, s.initErr)
+	}
+
+	dest, ref, ok := s.lastMessage(sessionID)
+	if !ok {
+		return s.SendWithDetails(status, message, sessionID, details)
+	}
+	editor, ok := dest.formatter.(EditFormatter)
+	if !ok {
+		logging.Debug("Destination %s can't edit messages, sending a new one", dest.Name)
+		return s.SendWithDetails(status, message, sessionID, details)
+	}
+
+	if reason := s.skipReason(status, details); reason != "" {
+		return s.skip(receipt, reason)
+	}
+
+	if _, err := s.allowRate(receipt, dest.Name, []destination{dest}); err != nil {
+		return s.finish(receipt, err)
+	}
+
+	receipt.RequestID = uuid.New().String()
+	err := s.editMessage(receipt, dest, editor, ref, message, resolveDetails(details))
+	if err == nil {
+		return s.finish(receipt, nil)
+	}
+
+	// The new message's send reports its own receipt
+	logging.Warn("[%s] Editing message %s on %s failed, sending a new one: %v", receipt.RequestID, ref, dest.Name, err)
+	return s.SendWithDetails(status, message, sessionID, details)
+}
+
+// lastMessage returns the destination and message ID of the session's last recorded notification
+func (s *Sender) lastMessage(sessionID string) (destination, string, bool) {
+	if s.messageRefs == nil {
+		return destination{}, "", false
+	}
+
+	name, ref, err := s.messageRefs.LastMessageRef(sessionID)
+	if err != nil {
+		logging.Warn("Failed to load last message for session %s: %v", sessionID, err)
+		return destination{}, "", false
+	}
+	if ref == "" {
+		return destination{}, "", false
+	}
+
+	for _, dests := range [][]destination{s.destinations, s.fallbacks} {
+		for _, dest := range dests {
+			if dest.Name == name {
+				return dest, ref, true
+			}
+		}
+	}
+	return destination{}, "", false
+}
+
+// editMessage sends the edit request for message ref with retries, records metrics
+// and adds the outcome to receipt on success
+func (s *Sender) editMessage(receipt *DeliveryReceipt, dest destination, editor EditFormatter, ref, message string, details Details) error {
+	requestID, status, sessionID := receipt.RequestID, receipt.Status, receipt.SessionID
+
+	payload, contentType, err := s.buildPayload(dest, status, message, sessionID, details)
+	if err != nil {
+		return fmt.Errorf("failed to build payload: %w", err)
+	}
+
+	method, editURL, body, err := editor.EditRequest(dest.URL, ref, payload)
+	if err != nil {
+		return fmt.Errorf("failed to build edit request: %w", err)
+	}
+	if err := validateURL(editURL); err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+
+	s.metrics.RecordRequest()
+	start := time.Now()
+
+	err = s.retry.Do(s.ctx, func(ctx context.Context) error {
+		_, err := s.sendRequest(ctx, requestID, sessionID, dest, method, editURL, body, contentType)
+		return err
+	})
+
+	latency := time.Since(start)
+	if err != nil {
+		s.metrics.RecordFailure()
+		s.metrics.RecordDestinationFailure(dest.Name)
+		return err
+	}
+
+	s.metrics.RecordSuccess(status, latency)
+	s.metrics.RecordDestinationSuccess(dest.Name, latency)
+	logging.Info("[%s] Edited message %s on %s (latency: %v)", requestID, ref, dest.Name, latency)
+	receipt.addOutcome(DestinationOutcome{Destination: dest.Name, Fallback: dest.fallback, Latency: latency})
+	return nil
+}
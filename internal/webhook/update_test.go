@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/777genius/claude-notifications/internal/analyzer"
+	"github.com/777genius/claude-notifications/internal/state"
+)
+
+// recordedRequest is a request seen by a fake endpoint
+type recordedRequest struct {
+	method string
+	path   string
+	query  string
+	body   map[string]interface{}
+}
+
+// fakeEditEndpoint records requests and answers each with the given status and body
+type fakeEditEndpoint struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+	respond  func(r *http.Request) (int, string)
+}
+
+func (f *fakeEditEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	var body map[string]interface{}
+	_ = json.Unmarshal(data, &body)
+
+	f.mu.Lock()
+	f.requests = append(f.requests, recordedRequest{method: r.Method, path: r.URL.Path, query: r.URL.RawQuery, body: body})
+	f.mu.Unlock()
+
+	status, reply := f.respond(r)
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, reply)
+}
+
+func (f *fakeEditEndpoint) request(i int) recordedRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[i]
+}
+
+func (f *fakeEditEndpoint) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+func newTelegramUpdateSender(url string, store MessageRefStore) *Sender {
+	cfg := newTestConfig(url + "/bot123/sendMessage")
+	cfg.Notifications.Webhook.Preset = "telegram"
+	cfg.Notifications.Webhook.ChatID = "1"
+	cfg.Notifications.Webhook.MessageRefPath = "result.message_id"
+	cfg.Notifications.Webhook.Retry.Enabled = false
+	return New(cfg, WithMessageRefStore(store))
+}
+
+func TestSenderUpdateTelegram(t *testing.T) {
+	endpoint := &fakeEditEndpoint{respond: func(r *http.Request) (int, string) {
+		return http.StatusOK, `{"ok": true, "result": {"message_id": 42}}`
+	}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	store := state.NewManagerWithStore(state.NewMemoryStore())
+	sender := newTelegramUpdateSender(server.URL, store)
+
+	if err := sender.Send(analyzer.StatusPlanReady, "Plan ready", "session-1"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := sender.Update(analyzer.StatusTaskComplete, "All done", "session-1"); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	if endpoint.count() != 2 {
+		t.Fatalf("Expected a send and an edit, got %d requests", endpoint.count())
+	}
+	edit := endpoint.request(1)
+	if edit.method != http.MethodPost || edit.path != "/bot123/editMessageText" {
+		t.Errorf("Expected POST /bot123/editMessageText, got %s %s", edit.method, edit.path)
+	}
+	if edit.body["message_id"] != float64(42) {
+		t.Errorf("Expected message_id 42, got %v", edit.body["message_id"])
+	}
+	if edit.body["chat_id"] != "1" {
+		t.Errorf("Expected chat_id 1, got %v", edit.body["chat_id"])
+	}
+	if text, _ := edit.body["text"].(string); !strings.Contains(text, "All done") {
+		t.Errorf("Expected the edit to carry the new text, got %q", text)
+	}
+}
+
+func TestSenderUpdateDiscord(t *testing.T) {
+	endpoint := &fakeEditEndpoint{respond: func(r *http.Request) (int, string) {
+		return http.StatusOK, `{"id": "1234567890123456789", "channel_id": "42"}`
+	}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	store := state.NewManagerWithStore(state.NewMemoryStore())
+	cfg := newTestConfig(server.URL + "/api/webhooks/1/abc?wait=true")
+	cfg.Notifications.Webhook.Preset = "discord"
+	cfg.Notifications.Webhook.MessageRefPath = "id"
+	sender := New(cfg, WithMessageRefStore(store))
+
+	if err := sender.Send(analyzer.StatusQuestion, "Which one?", "session-1"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := sender.Update(analyzer.StatusTaskComplete, "All done", "session-1"); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	edit := endpoint.request(1)
+	if edit.method != http.MethodPatch || edit.path != "/api/webhooks/1/abc/messages/1234567890123456789" {
+		t.Errorf("Expected PATCH to the stored message, got %s %s", edit.method, edit.path)
+	}
+	if edit.query != "" {
+		t.Errorf("Expected wait to be dropped from the edit URL, got %q", edit.query)
+	}
+	if _, ok := edit.body["username"]; ok {
+		t.Error("Expected username to be left out of the edit")
+	}
+	if _, ok := edit.body["embeds"]; !ok {
+		t.Error("Expected the edit to carry the new embed")
+	}
+}
+
+func TestSenderUpdateFallsBackToSend(t *testing.T) {
+	editFails := false
+	var mu sync.Mutex
+	endpoint := &fakeEditEndpoint{respond: func(r *http.Request) (int, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if editFails && r.URL.Path == "/bot123/editMessageText" {
+			return http.StatusBadRequest, `{"ok": false, "description": "message to edit not found"}`
+		}
+		return http.StatusOK, `{"ok": true, "result": {"message_id": 42}}`
+	}}
+	server := httptest.NewServer(endpoint)
+	defer server.Close()
+
+	store := state.NewManagerWithStore(state.NewMemoryStore())
+	sender := newTelegramUpdateSender(server.URL, store)
+
+	// No message recorded yet
+	if err := sender.Update(analyzer.StatusTaskComplete, "Done", "session-1"); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if got := endpoint.request(0).path; got != "/bot123/sendMessage" {
+		t.Errorf("Expected a new message without a stored ref, got %s", got)
+	}
+
+	// The edit is rejected
+	mu.Lock()
+	editFails = true
+	mu.Unlock()
+	if err := sender.Update(analyzer.StatusTaskComplete, "Done again", "session-1"); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if endpoint.count() != 3 {
+		t.Fatalf("Expected a failed edit followed by a new message, got %d requests", endpoint.count())
+	}
+	if got := endpoint.request(1).path; got != "/bot123/editMessageText" {
+		t.Errorf("Expected an edit attempt, got %s", got)
+	}
+	if got := endpoint.request(2).path; got != "/bot123/sendMessage" {
+		t.Errorf("Expected a new message after the failed edit, got %s", got)
+	}
+
+	// Without a store Update is a plain send
+	if err := newTelegramUpdateSender(server.URL, nil).Update(analyzer.StatusTaskComplete, "Done", "session-1"); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if got := endpoint.request(3).path; got != "/bot123/sendMessage" {
+		t.Errorf("Expected a new message without a store, got %s", got)
+	}
+}
+
+func TestTelegramEditRequestURL(t *testing.T) {
+	f := &TelegramFormatter{ChatID: "1"}
+	if _, _, _, err := f.EditRequest("https://example.com/hook", "42", []byte(`{}`)); err == nil {
+		t.Error("Expected an error for a URL that isn't a sendMessage call")
+	}
+}
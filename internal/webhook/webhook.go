@@ -4,29 +4,54 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"runtime/debug"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/777genius/claude-notifications/internal/analyzer"
 	"github.com/777genius/claude-notifications/internal/config"
 	"github.com/777genius/claude-notifications/internal/errorhandler"
 	"github.com/777genius/claude-notifications/internal/logging"
+	"github.com/777genius/claude-notifications/internal/platform"
 	"github.com/google/uuid"
 )
 
+// defaultUserAgent is sent when no userAgent is configured
+const defaultUserAgent = "claude-notifications/1.0"
+
+// defaultMaxConcurrency bounds async sends in flight when no maxConcurrency is configured
+const defaultMaxConcurrency = 4
+
+// defaultHTTPTimeout bounds each request when no timeout is configured
+const defaultHTTPTimeout = 10 * time.Second
+
 // Sender sends webhook notifications with professional patterns
 type Sender struct {
 	cfg            *config.Config
 	client         *http.Client
 	retry          *Retryer
-	circuitBreaker *CircuitBreaker
-	rateLimiter    *RateLimiter
+	sessionLimiter *SessionRateLimiter
+	quietHours     *QuietHours
+	maxEventAge    time.Duration // notifications about older events are dropped, 0 disables
+	batcher        *Batcher
 	metrics        *Metrics
-	formatters     map[string]Formatter
+	signer         *Signer
+	spool          *Spool
+	destinations   []destination
+	fallbacks      []destination // tried in order when no routed destination delivers
+	initErr        error         // construction error returned by Send (see NewSender)
+	onDelivery     func(DeliveryReceipt)
+	audit          *AuditLog       // records every delivery receipt, nil unless auditLog is set
+	threads        ThreadStore     // per-session threads for slackThreads destinations, nil to disable
+	messageRefs    MessageRefStore // last message IDs for messageRefPath destinations, nil to disable
+	redactor       *redactor       // set with verboseLogging to log requests and responses
+	asyncSlots     chan struct{}   // semaphore bounding async sends in flight, see maxConcurrency
 
 	// Graceful shutdown
 	wg     sync.WaitGroup
@@ -35,134 +60,517 @@ type Sender struct {
 }
 
 // New creates a new professional webhook sender
-func New(cfg *config.Config) *Sender {
+// Construction errors (e.g. a malformed payload template) are logged and returned by every Send;
+// use NewSender to handle them up front.
+func New(cfg *config.Config, opts ...Option) *Sender {
+	s, err := newSender(cfg, opts...)
+	if err != nil {
+		logging.Error("Webhook sender misconfigured: %v", err)
+		s.initErr = err
+	}
+	return s
+}
+
+// NewSender creates a new webhook sender, failing if the configuration can't be used
+func NewSender(cfg *config.Config, opts ...Option) (*Sender, error) {
+	s, err := newSender(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ValidateConfig checks the webhook settings a sender is built from, naming the offending field
+// (e.g. a missing URL or Telegram chat_id, an unknown preset, an unparseable retry backoff)
+// Nothing is checked while webhooks are disabled
+func ValidateConfig(cfg *config.Config) error {
+	if !cfg.IsWebhookEnabled() {
+		return nil
+	}
+	if err := cfg.Notifications.Webhook.Validate(); err != nil {
+		return fmt.Errorf("invalid webhook config: %w", err)
+	}
+	return nil
+}
+
+// newSender builds the sender; on error the returned sender is usable but must not send
+func newSender(cfg *config.Config, opts ...Option) (*Sender, error) {
+	configErr := ValidateConfig(cfg)
+
 	// Create base HTTP client with timeout
+	timeout, timeoutErr := parseHTTPTimeout(cfg.Notifications.Webhook.Timeout)
+	transport := newTransport(cfg.Notifications.Webhook.Proxy)
+	tlsConfig, tlsErr := newTLSConfig(cfg.Notifications.Webhook.TLS)
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
 	client := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout:   timeout,
+		Transport: transport,
 	}
 
 	// Parse retry config
 	retryConfig := parseRetryConfig(cfg.Notifications.Webhook.Retry)
 	retry := NewRetryer(retryConfig)
+	metrics := NewMetrics()
+	retry.SetMetrics(metrics)
 
-	// Parse circuit breaker config
-	cbCfg := cfg.Notifications.Webhook.CircuitBreaker
-	var circuitBreaker *CircuitBreaker
-	if cbCfg.Enabled {
-		timeout, _ := time.ParseDuration(cbCfg.Timeout)
-		if timeout == 0 {
-			timeout = 30 * time.Second
-		}
-		circuitBreaker = NewCircuitBreaker(cbCfg.FailureThreshold, cbCfg.SuccessThreshold, timeout)
+	// Create the per-session rate limiter, destinations get their own limiters below
+	rlCfg := cfg.Notifications.Webhook.RateLimit
+	var sessionLimiter *SessionRateLimiter
+	if rlCfg.Enabled && rlCfg.PerSessionRequestsPerMinute > 0 {
+		sessionLimiter = NewSessionRateLimiter(rlCfg.PerSessionRequestsPerMinute)
 	}
 
-	// Create rate limiter
-	var rateLimiter *RateLimiter
-	if cfg.Notifications.Webhook.RateLimit.Enabled {
-		rateLimiter = NewRateLimiter(cfg.Notifications.Webhook.RateLimit.RequestsPerMinute)
+	// Resolve destinations with their formatters and templates
+	var destinations []destination
+	quietHours, initErr := NewQuietHours(cfg.Notifications.Webhook.QuietHours)
+	if configErr != nil {
+		initErr = configErr
+	}
+	if initErr == nil {
+		initErr = timeoutErr
+	}
+	if initErr == nil {
+		initErr = tlsErr
+	}
+	for _, dest := range cfg.Notifications.Webhook.GetDestinations() {
+		d, err := newDestination(dest)
+		if err != nil && initErr == nil {
+			initErr = fmt.Errorf("webhook destination %s: %w", dest.Name, err)
+		}
+		d.breaker = newDestinationBreaker(cfg.Notifications.Webhook.CircuitBreaker)
+		d.limiter = newDestinationLimiter(rlCfg)
+		destinations = append(destinations, d)
+	}
+	var fallbacks []destination
+	for _, dest := range cfg.Notifications.Webhook.Fallbacks {
+		d, err := newDestination(dest)
+		if err != nil && initErr == nil {
+			initErr = fmt.Errorf("webhook fallback %s: %w", dest.Name, err)
+		}
+		d.fallback = true
+		d.limiter = newDestinationLimiter(rlCfg)
+		fallbacks = append(fallbacks, d)
 	}
 
-	// Create formatters
-	formatters := map[string]Formatter{
-		"slack":    &SlackFormatter{},
-		"discord":  &DiscordFormatter{},
-		"telegram": &TelegramFormatter{ChatID: cfg.Notifications.Webhook.ChatID},
-		"lark":     &LarkFormatter{},
+	maxEventAge, _ := time.ParseDuration(cfg.Notifications.Webhook.MaxEventAge)
+
+	var audit *AuditLog
+	if path := cfg.Notifications.Webhook.AuditLog; path != "" {
+		audit = NewAuditLog(path)
 	}
 
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Sender{
+	s := &Sender{
 		cfg:            cfg,
 		client:         client,
 		retry:          retry,
-		circuitBreaker: circuitBreaker,
-		rateLimiter:    rateLimiter,
-		metrics:        NewMetrics(),
-		formatters:     formatters,
+		sessionLimiter: sessionLimiter,
+		quietHours:     quietHours,
+		maxEventAge:    maxEventAge,
+		metrics:        metrics,
+		signer:         NewSigner(cfg.Notifications.Webhook.Signing),
+		destinations:   destinations,
+		fallbacks:      fallbacks,
+		audit:          audit,
+		asyncSlots:     make(chan struct{}, maxConcurrency(cfg.Notifications.Webhook.MaxConcurrency)),
 		ctx:            ctx,
 		cancel:         cancel,
 	}
+	if cfg.Notifications.Webhook.VerboseLogging {
+		s.redactor = newRedactor(cfg.Notifications.Webhook.RedactHeaders)
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if batchCfg := cfg.Notifications.Webhook.Batch; batchCfg.Enabled {
+		window, _ := time.ParseDuration(batchCfg.Window)
+		s.batcher = NewBatcher(window, batchCfg.Separator, s.flushBatch)
+	}
+
+	// Misconfigured destinations only matter if webhooks will actually be sent
+	if initErr != nil && cfg.IsWebhookEnabled() {
+		return s, initErr
+	}
+
+	// Open spool and replay notifications left behind by earlier processes
+	spoolCfg := cfg.Notifications.Webhook.Spool
+	if spoolCfg.Enabled {
+		dir := spoolCfg.Dir
+		if dir == "" {
+			dir = DefaultSpoolDir(cfg.State.Dir)
+		}
+		maxAge, _ := time.ParseDuration(spoolCfg.MaxAge)
+
+		spool, err := NewSpool(dir, maxAge)
+		if err != nil {
+			logging.Warn("Webhook spool disabled: %v", err)
+		} else {
+			if spoolCfg.Dir == "" {
+				// Older versions spooled to their own temp directory
+				_ = platform.MigrateFiles(legacySpoolDir(), dir, spoolPrefix+"*"+spoolSuffix)
+			}
+			s.spool = spool
+			s.replaySpool()
+		}
+	}
+
+	return s, nil
 }
 
 // Send sends a webhook notification with full professional stack
 func (s *Sender) Send(status analyzer.Status, message, sessionID string) error {
+	return s.SendWithDetails(status, message, sessionID, Details{})
+}
+
+// SendWithDetails sends a webhook notification enriched with session details
+// such as the git branch and commit of details.CWD
+func (s *Sender) SendWithDetails(status analyzer.Status, message, sessionID string, details Details) error {
+	_, err := s.send(status, message, sessionID, details, nil)
+	return err
+}
+
+// send sends a notification, handing its spool entry (nil if not spooled) to the batch when batched
+// Returns batched=true when the batch took the notification, and with it the removal of the entry
+func (s *Sender) send(status analyzer.Status, message, sessionID string, details Details, entry *spoolEntry) (batched bool, err error) {
+	receipt := newReceipt(status, sessionID)
+	if s.disabled(status) {
+		return false, s.skip(receipt, SkipDisabled)
+	}
+
+	if s.initErr != nil {
+		return false, s.finish(receipt, s.initErr)
+	}
+
+	if reason := s.skipReason(status, details); reason != "" {
+		return false, s.skip(receipt, reason)
+	}
+
+	// Batched notifications are delivered together when the window closes, and get their receipts then
+	if s.batcher != nil {
+		// Count the open batch as in flight so Shutdown waits for it
+		s.wg.Add(1)
+		if !s.batcher.Add(status, message, sessionID, details, entry) {
+			s.wg.Done()
+		}
+		logging.Debug("Batched %s webhook for session %s", status, sessionID)
+		return true, nil
+	}
+
+	return false, s.finish(receipt, s.deliver(receipt, message, details))
+}
+
+// disabled reports whether webhooks are turned off, by config or by config.DisabledEnv
+func (s *Sender) disabled(status analyzer.Status) bool {
+	if config.NotificationsDisabled() {
+		logging.Debug("Notifications disabled by %s, skipping %s webhook", config.DisabledEnv, status)
+		return true
+	}
 	if !s.cfg.IsWebhookEnabled() {
 		logging.Debug("Webhooks disabled, skipping")
-		return nil
+		return true
+	}
+	return false
+}
+
+// skipReason returns why a notification is dropped without being attempted, or "" to send it
+// Skipped notifications don't consume rate limit or count as failures
+func (s *Sender) skipReason(status analyzer.Status, details Details) string {
+	if s.cfg.IsStatusMuted(string(status)) {
+		logging.Debug("Status %s is muted, skipping webhook", status)
+		return SkipMuted
+	}
+
+	if s.quietHours != nil && s.quietHours.Contains(time.Now()) {
+		logging.Debug("Quiet hours active, skipping %s webhook", status)
+		return SkipQuietHours
+	}
+
+	if s.isStale(details) {
+		logging.Debug("Event for %s webhook is %v old, older than maxEventAge %v, skipping", status, time.Since(details.EventTime).Round(time.Second), s.maxEventAge)
+		return SkipStale
+	}
+	return ""
+}
+
+// isStale reports whether the event behind a notification is older than maxEventAge
+// Events without a timestamp are never stale
+func (s *Sender) isStale(details Details) bool {
+	return s.maxEventAge > 0 && !details.EventTime.IsZero() && time.Since(details.EventTime) > s.maxEventAge
+}
+
+// flushBatch delivers a combined batch once its window closes, then removes its spooled entries
+// Each batched notification gets a receipt with the combined delivery's outcome, the audit log records it once
+func (s *Sender) flushBatch(status analyzer.Status, message, sessionID string, details Details, batched []analyzer.Status, spooled []*spoolEntry) {
+	defer s.wg.Done()
+	defer errorhandler.HandlePanic()
+
+	receipt := newReceipt(status, sessionID)
+	err := s.deliver(receipt, message, details)
+	if err != nil {
+		errorhandler.HandleError(err, "Batched webhook send failed")
+	}
+	s.removeSpooled(spooled...)
+
+	receipt.Err = err
+	receipt.Latency = time.Since(receipt.start)
+	if s.audit != nil {
+		s.audit.Record(*receipt)
+	}
+	for _, batchedStatus := range batched {
+		r := *receipt
+		r.Status = batchedStatus
+		s.notifyDelivery(r)
+	}
+}
+
+// allowRate takes a token from the session's rate limiter and from each destination's limiter
+// Returns the destinations with capacity left, or ErrRateLimitExceeded, after recording
+// the rejection under route, if the session or every destination is exhausted
+func (s *Sender) allowRate(receipt *DeliveryReceipt, route string, dests []destination) ([]destination, error) {
+	// Check per-session rate limit first so a chatty session doesn't drain the destinations' buckets
+	if s.sessionLimiter != nil && !s.sessionLimiter.Allow(receipt.SessionID) {
+		s.metrics.RecordRateLimited()
+		logging.Warn("Session rate limit exceeded for %s, dropping webhook", receipt.SessionID)
+		receipt.addOutcome(DestinationOutcome{Destination: route, Rejected: true, Err: ErrRateLimitExceeded})
+		return nil, ErrRateLimitExceeded
 	}
 
-	// Check rate limit (non-blocking check)
-	if s.rateLimiter != nil && !s.rateLimiter.Allow() {
+	// Check each destination's rate limit (non-blocking check), a busy endpoint doesn't hold back the others
+	allowed := make([]destination, 0, len(dests))
+	var limited []destination
+	for _, dest := range dests {
+		if dest.limiter != nil && !dest.limiter.Allow() {
+			limited = append(limited, dest)
+			continue
+		}
+		allowed = append(allowed, dest)
+	}
+	if len(allowed) == 0 {
 		s.metrics.RecordRateLimited()
 		logging.Warn("Rate limit exceeded, dropping webhook")
-		return ErrRateLimitExceeded
+		receipt.addOutcome(DestinationOutcome{Destination: route, Rejected: true, Err: ErrRateLimitExceeded})
+		return nil, ErrRateLimitExceeded
+	}
+	for _, dest := range limited {
+		s.metrics.RecordRateLimited()
+		logging.Warn("Rate limit exceeded for %s, skipping it", dest.Name)
+		receipt.addOutcome(DestinationOutcome{Destination: dest.Name, Rejected: true, Err: ErrRateLimitExceeded})
 	}
 
-	// Check circuit breaker
-	if s.circuitBreaker != nil && s.circuitBreaker.GetState() == StateOpen {
+	return allowed, nil
+}
+
+// deliver applies rate limiting and the circuit breakers, then fans out to the routed destinations
+// The outcome at each destination is added to receipt
+func (s *Sender) deliver(receipt *DeliveryReceipt, message string, details Details) error {
+	status := receipt.Status
+	route, destinations := s.route(status)
+
+	destinations, err := s.allowRate(receipt, route, destinations)
+	if err != nil {
+		return err
+	}
+
+	// Skip the send when every routed destination's circuit is open
+	if circuitsOpen(destinations) {
 		s.metrics.RecordCircuitOpen()
-		logging.Warn("Circuit breaker is open, skipping webhook")
-		return ErrCircuitOpen
+		logging.Warn("Circuit breaker is open for %s, skipping webhook", route)
+		receipt.addOutcome(DestinationOutcome{Destination: route, Rejected: true, Err: ErrCircuitOpen})
+		if len(s.fallbacks) == 0 {
+			return ErrCircuitOpen
+		}
+		receipt.RequestID = uuid.New().String()
+		return s.deliverFallback(receipt, message, resolveDetails(details), ErrCircuitOpen)
 	}
 
 	// Generate request ID for tracing
 	requestID := uuid.New().String()
+	receipt.RequestID = requestID
+
+	details = resolveDetails(details)
+
+	logging.Info("[%s] Routing %s notification to %s", requestID, status, route)
+
+	// Fan out to every selected destination, one failing endpoint doesn't stop the others
+	var errs []error
+	for _, dest := range destinations {
+		if err := s.sendToDestination(receipt, dest, message, details); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Update circuit breaker states in metrics
+	s.recordCircuitStates()
+
+	// Nothing delivered, hand over to the fallbacks
+	if len(errs) > 0 && len(errs) == len(destinations) && len(s.fallbacks) > 0 {
+		return s.deliverFallback(receipt, message, details, joinDestinationErrors(errs))
+	}
+
+	return joinDestinationErrors(errs)
+}
+
+// deliverFallback tries each fallback destination in order until one delivers
+// Fallbacks out of rate limit capacity are skipped.
+// Returns nil once a fallback succeeds, otherwise primaryErr joined with every fallback error
+func (s *Sender) deliverFallback(receipt *DeliveryReceipt, message string, details Details, primaryErr error) error {
+	requestID, status := receipt.RequestID, receipt.Status
+	logging.Warn("[%s] Primary webhook delivery failed, trying %d fallback(s): %v", requestID, len(s.fallbacks), primaryErr)
+
+	errs := []error{primaryErr}
+	for _, dest := range s.fallbacks {
+		if dest.limiter != nil && !dest.limiter.Allow() {
+			s.metrics.RecordRateLimited()
+			logging.Warn("[%s] Rate limit exceeded for fallback %s, skipping it", requestID, dest.Name)
+			receipt.addOutcome(DestinationOutcome{Destination: dest.Name, Fallback: true, Rejected: true, Err: ErrRateLimitExceeded})
+			errs = append(errs, &DestinationError{Destination: dest.Name, Err: ErrRateLimitExceeded})
+			continue
+		}
+		err := s.sendToDestination(receipt, dest, message, details)
+		if err == nil {
+			s.metrics.RecordFallbackDelivery()
+			logging.Info("[%s] Delivered %s notification via fallback %s", requestID, status, dest.Name)
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// circuitsOpen reports whether every destination's circuit breaker is open
+// Breakers whose open timeout has passed move to half-open here and let a probe through
+func circuitsOpen(dests []destination) bool {
+	for _, dest := range dests {
+		if dest.breaker == nil || dest.breaker.getState() != StateOpen {
+			return false
+		}
+	}
+	return len(dests) > 0
+}
+
+// recordCircuitStates copies each destination's breaker state into the metrics
+// The overall state is the worst of them: open, then half-open, then closed
+func (s *Sender) recordCircuitStates() {
+	overall := StateClosed
+	for _, dest := range s.destinations {
+		if dest.breaker == nil {
+			continue
+		}
+		state := dest.breaker.GetState()
+		s.metrics.UpdateDestinationCircuitBreakerState(dest.Name, state)
+		if state == StateOpen || (state == StateHalfOpen && overall == StateClosed) {
+			overall = state
+		}
+	}
+	s.metrics.UpdateCircuitBreakerState(overall)
+}
+
+// route selects the destinations for a status using the configured routing table
+// Unmapped statuses fall back to every destination
+func (s *Sender) route(status analyzer.Status) (string, []destination) {
+	if name, ok := s.cfg.Notifications.Webhook.Routes[string(status)]; ok {
+		for _, dest := range s.destinations {
+			if dest.Name == name {
+				return name, []destination{dest}
+			}
+		}
+	}
+	return "default", s.destinations
+}
+
+// sendToDestination delivers a notification to a single destination, records metrics
+// and adds the outcome to receipt
+func (s *Sender) sendToDestination(receipt *DeliveryReceipt, dest destination, message string, details Details) error {
+	requestID, status, sessionID := receipt.RequestID, receipt.Status, receipt.SessionID
 
 	// Record metrics
 	s.metrics.RecordRequest()
 	start := time.Now()
 
 	// Execute with retry and circuit breaker
-	err := s.sendWithRetryAndCircuitBreaker(requestID, status, message, sessionID)
+	err := s.sendWithRetryAndCircuitBreaker(requestID, dest, status, message, sessionID, details)
 
 	// Record result
 	latency := time.Since(start)
 	if err != nil {
 		s.metrics.RecordFailure()
-		logging.Error("[%s] Webhook failed after retries: %v (latency: %v)", requestID, err, latency)
-	} else {
-		s.metrics.RecordSuccess(status, latency)
-		logging.Info("[%s] Webhook sent successfully (latency: %v)", requestID, latency)
-	}
+		s.metrics.RecordDestinationFailure(dest.Name)
+		var formatErr *FormatError
+		if errors.As(err, &formatErr) {
+			s.metrics.RecordFormatFailure()
+		}
+		logging.Error("[%s] Webhook to %s failed after retries: %v (latency: %v)", requestID, dest.Name, err, latency)
 
-	// Update circuit breaker state in metrics
-	if s.circuitBreaker != nil {
-		s.metrics.UpdateCircuitBreakerState(s.circuitBreaker.GetState())
+		// Failures before any HTTP attempt (bad payload or URL, open circuit) carry no status code
+		var sendErr *SendError
+		if !errors.As(err, &sendErr) {
+			err = newSendError(requestID, dest.Name, 0, false, err)
+		}
+		destErr := &DestinationError{Destination: dest.Name, Err: err}
+		receipt.addOutcome(DestinationOutcome{Destination: dest.Name, Fallback: dest.fallback, Latency: latency, Err: destErr})
+		return destErr
 	}
 
-	return err
+	s.metrics.RecordSuccess(status, latency)
+	s.metrics.RecordDestinationSuccess(dest.Name, latency)
+	logging.Info("[%s] Webhook sent successfully to %s (latency: %v)", requestID, dest.Name, latency)
+	receipt.addOutcome(DestinationOutcome{Destination: dest.Name, Fallback: dest.fallback, Latency: latency})
+	return nil
 }
 
 // sendWithRetryAndCircuitBreaker executes the webhook with retry and circuit breaker
-func (s *Sender) sendWithRetryAndCircuitBreaker(requestID string, status analyzer.Status, message, sessionID string) error {
-	webhookCfg := s.cfg.Notifications.Webhook
+func (s *Sender) sendWithRetryAndCircuitBreaker(requestID string, dest destination, status analyzer.Status, message, sessionID string, details Details) error {
+	// The console preset has no endpoint, so there is nothing to retry or trip the breaker
+	if dest.isConsole() {
+		return s.writeConsole(dest, status, message, sessionID, details)
+	}
+
+	// Threaded destinations reply under the session's first message
+	details.ThreadID = s.threadID(dest, sessionID)
 
 	// Build payload
-	payload, contentType, err := s.buildPayload(status, message, sessionID)
+	payload, contentType, err := s.buildPayload(dest, status, message, sessionID, details)
 	if err != nil {
 		return fmt.Errorf("failed to build payload: %w", err)
 	}
 
 	// Validate URL
-	if err := validateURL(webhookCfg.URL); err != nil {
+	if err := validateURL(dest.URL); err != nil {
 		return fmt.Errorf("invalid webhook URL: %w", err)
 	}
 
 	// Create request function for retry
+	// A successful response may carry a thread or message ID worth keeping for the session
 	sendFn := func(ctx context.Context) error {
-		return s.sendHTTPRequest(ctx, requestID, webhookCfg.URL, payload, contentType, webhookCfg.Headers)
+		body, err := s.sendHTTPRequest(ctx, requestID, sessionID, dest, payload, contentType)
+		if err != nil || s.cfg.Notifications.Webhook.DryRun {
+			return err
+		}
+		if details.ThreadID == "" {
+			s.rememberThread(dest, sessionID, body)
+		}
+		s.rememberMessageRef(dest, sessionID, body)
+		return nil
 	}
 
-	// Execute with circuit breaker and retry
+	// Execute with the destination's circuit breaker and retry
+	// Fallbacks have no breaker, they are the last resort when the primary destinations fail
 	var executeErr error
-	if s.circuitBreaker != nil {
+	if dest.breaker != nil {
 		// Wrap with circuit breaker
-		executeErr = s.circuitBreaker.Execute(s.ctx, func() error {
+		executeErr = dest.breaker.Execute(s.ctx, func() error {
 			// Execute with retry
 			return s.retry.Do(s.ctx, sendFn)
 		})
+		// Rejected without sending, e.g. while a half-open probe is in flight
+		if errors.Is(executeErr, ErrCircuitOpen) {
+			s.metrics.RecordCircuitOpen()
+		}
 	} else {
 		// Just retry without circuit breaker
 		executeErr = s.retry.Do(s.ctx, sendFn)
@@ -171,27 +579,80 @@ func (s *Sender) sendWithRetryAndCircuitBreaker(requestID string, status analyze
 	return executeErr
 }
 
-// buildPayload builds the webhook payload based on preset
-func (s *Sender) buildPayload(status analyzer.Status, message, sessionID string) ([]byte, string, error) {
-	webhookCfg := s.cfg.Notifications.Webhook
-	statusInfo, _ := s.cfg.GetStatusInfo(string(status))
+// runFormatter calls the destination's formatter, turning an error or a panic into a FormatError
+// so a broken formatter fails its own delivery instead of crashing the send
+func runFormatter(dest destination, status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (payload interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			payload = nil
+			err = &FormatError{Preset: dest.Preset, Err: fmt.Errorf("panic: %v", r)}
+			errorhandler.HandleError(err, "Recovered from formatter panic")
+			logging.Debug("Formatter panic stack:\n%s", debug.Stack())
+		}
+	}()
+
+	payload, err = dest.formatter.Format(status, message, sessionID, statusInfo, details)
+	if err != nil {
+		return nil, &FormatError{Preset: dest.Preset, Err: err}
+	}
+	return payload, nil
+}
+
+// formatPayload builds the webhook payload based on the destination preset
+func (s *Sender) formatPayload(dest destination, status analyzer.Status, message, sessionID string, details Details) ([]byte, string, error) {
+	statusInfo, ok := s.cfg.GetStatusInfo(string(status))
+	if !ok && status == SelfTestStatus {
+		statusInfo = selfTestStatusInfo
+	}
+	statusInfo.Title = statusInfo.TitlePrefix + statusInfo.Title
+	tmplData := templateData{
+		Status:    string(status),
+		Title:     statusInfo.Title,
+		Message:   message,
+		SessionID: sessionID,
+		Timestamp: time.Now().Format(time.RFC3339),
+		Project:   details.Project,
+		GitBranch: details.GitBranch,
+		GitCommit: details.GitCommit,
+		Priority:  resolvePriority(status, statusInfo, details).String(),
+	}
 
 	// Use formatter if available
-	if formatter, ok := s.formatters[webhookCfg.Preset]; ok {
-		payload, err := formatter.Format(status, message, sessionID, statusInfo)
+	if dest.formatter != nil {
+		if dest.footer != nil {
+			footer, err := renderFooter(dest.footer, tmplData)
+			if err != nil {
+				return nil, "", err
+			}
+			details.footer = &footer
+		}
+		payload, err := runFormatter(dest, status, message, sessionID, statusInfo, details)
 		if err != nil {
 			return nil, "", err
 		}
+		if encoder, ok := dest.formatter.(BodyFormatter); ok {
+			return encoder.EncodeBody(payload)
+		}
+		if text, ok := payload.(string); ok {
+			return []byte(text), "text/plain", nil
+		}
 		data, err := json.Marshal(payload)
 		return data, "application/json", err
 	}
 
+	// User-supplied template
+	if dest.template != nil {
+		data, err := renderPayloadTemplate(dest.template, tmplData)
+		return data, "application/json", err
+	}
+
 	// Fallback to custom format
-	return s.buildCustomPayload(status, message, sessionID, webhookCfg.Format, statusInfo)
+	return s.buildCustomPayload(status, message, sessionID, dest.Format, dest.FieldMap, statusInfo, details)
 }
 
 // buildCustomPayload builds a custom webhook payload
-func (s *Sender) buildCustomPayload(status analyzer.Status, message, sessionID, format string, statusInfo config.StatusInfo) ([]byte, string, error) {
+// fieldMap renames the standard JSON fields; unmapped fields keep their names
+func (s *Sender) buildCustomPayload(status analyzer.Status, message, sessionID, format string, fieldMap map[string]string, statusInfo config.StatusInfo, details Details) ([]byte, string, error) {
 	if format == "text" {
 		text := fmt.Sprintf("[%s] %s", status, message)
 		return []byte(text), "text/plain", nil
@@ -206,65 +667,228 @@ func (s *Sender) buildCustomPayload(status analyzer.Status, message, sessionID,
 		"source":     "claude-notifications",
 		"title":      statusInfo.Title,
 	}
+	if details.Project != "" {
+		payload["project"] = details.Project
+	}
+	if details.GitBranch != "" {
+		payload["git_branch"] = details.GitBranch
+	}
+	if details.GitCommit != "" {
+		payload["git_commit"] = details.GitCommit
+	}
 
-	data, err := json.Marshal(payload)
+	data, err := json.Marshal(remapFields(payload, fieldMap))
 	return data, "application/json", err
 }
 
-// sendHTTPRequest sends the actual HTTP request
-func (s *Sender) sendHTTPRequest(ctx context.Context, requestID, url string, payload []byte, contentType string, headers map[string]string) error {
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+// remapFields returns payload with its keys renamed according to fieldMap
+func remapFields(payload map[string]interface{}, fieldMap map[string]string) map[string]interface{} {
+	if len(fieldMap) == 0 {
+		return payload
+	}
+	remapped := make(map[string]interface{}, len(payload))
+	for key, value := range payload {
+		if name, ok := fieldMap[key]; ok {
+			key = name
+		}
+		remapped[key] = value
+	}
+	return remapped
+}
+
+// sendHTTPRequest posts the payload to the destination and returns the response body on success
+// The body is nil in dry run mode
+func (s *Sender) sendHTTPRequest(ctx context.Context, requestID, sessionID string, dest destination, payload []byte, contentType string) ([]byte, error) {
+	return s.sendRequest(ctx, requestID, sessionID, dest, http.MethodPost, dest.URL, payload, contentType)
+}
+
+// sendRequest sends the payload to targetURL with the destination's headers, signing and response checks
+func (s *Sender) sendRequest(ctx context.Context, requestID, sessionID string, dest destination, method, targetURL string, payload []byte, contentType string) ([]byte, error) {
+	// Compress large JSON bodies; the signature below covers the bytes on the wire
+	reqBody := payload
+	compressed := shouldCompress(s.cfg.Notifications.Webhook.Compression, contentType, len(payload))
+	if compressed {
+		gz, err := gzipPayload(payload)
+		if err != nil {
+			return nil, newSendError(requestID, dest.Name, 0, false, fmt.Errorf("failed to compress payload: %w", err))
+		}
+		reqBody = gz
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, targetURL, bytes.NewReader(reqBody))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, newSendError(requestID, dest.Name, 0, false, fmt.Errorf("failed to create request: %w", err))
 	}
 
 	// Set headers
 	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("User-Agent", "claude-notifications/1.0")
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	userAgent := s.cfg.Notifications.Webhook.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
 	req.Header.Set("X-Request-ID", requestID)
+	if name := s.cfg.Notifications.Webhook.CorrelationHeader; name != "" {
+		req.Header.Set(name, sessionID)
+	}
+
+	// Set service headers required by the preset
+	if hf, ok := dest.formatter.(HeaderFormatter); ok {
+		for key, value := range hf.Headers() {
+			req.Header.Set(key, value)
+		}
+	}
 
 	// Set custom headers
-	for key, value := range headers {
+	for key, value := range dest.Headers {
 		req.Header.Set(key, value)
 	}
 
+	// Sign payload (after custom headers so the signature can't be overridden)
+	if s.signer != nil {
+		req.Header.Set(s.signer.Header(), s.signer.Sign(reqBody))
+	}
+
+	if s.redactor != nil {
+		logging.Debug("[%s] %s %s (%s) headers: %s body: %s", requestID, method, s.redactor.url(dest, targetURL), dest.Name,
+			s.redactor.header(req.Header), s.redactor.text(dest, string(payload)))
+	}
+
+	if s.cfg.Notifications.Webhook.DryRun {
+		logging.Info("[%s] Dry run, not sending %s payload to %s %s: %s", requestID, contentType, method, targetURL, payload)
+		return nil, nil
+	}
+
 	// Send request
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %w", err)
+		// Network errors and timeouts are worth retrying
+		return nil, newSendError(requestID, dest.Name, 0, true, fmt.Errorf("HTTP request failed: %w", err))
 	}
 	defer resp.Body.Close()
 
 	// Read response body (limited to 1MB)
 	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if s.redactor != nil {
+		logging.Debug("[%s] Response %d from %s: %s", requestID, resp.StatusCode, dest.Name, s.redactor.text(dest, string(body)))
+	}
 
 	// Check status code
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return NewHTTPError(resp, string(body))
+		return nil, newSendError(requestID, dest.Name, resp.StatusCode, isRetryableStatus(resp.StatusCode), NewHTTPError(resp, string(body)))
 	}
 
-	return nil
+	// Some APIs report failures in a 2xx body, a logical rejection won't succeed on retry
+	if dest.matcher != nil && !dest.matcher.Match(body) {
+		return nil, newSendError(requestID, dest.Name, resp.StatusCode, false, &ResponseMismatchError{StatusCode: resp.StatusCode, Body: string(body)})
+	}
+
+	return body, nil
 }
 
 // SendAsync sends a webhook asynchronously with graceful shutdown support
+// If the spool is enabled, the notification is persisted first so it can be
+// replayed by a later process if this one exits before delivery completes
 func (s *Sender) SendAsync(status analyzer.Status, message, sessionID string) {
+	s.SendAsyncWithDetails(status, message, sessionID, Details{})
+}
+
+// SendAsyncWithDetails is SendAsync with session details (see SendWithDetails)
+func (s *Sender) SendAsyncWithDetails(status analyzer.Status, message, sessionID string, details Details) {
+	var entry *spoolEntry
+	if s.spool != nil && s.cfg.IsWebhookEnabled() {
+		var err error
+		entry, err = s.spool.Enqueue(status, message, sessionID, details.CWD)
+		if err != nil {
+			logging.Warn("Failed to spool webhook, sending without persistence: %v", err)
+		}
+	}
+
+	s.sendAsync(status, message, sessionID, details, entry)
+}
+
+// sendAsync sends in a goroutine and settles the spool entry (if any) afterwards
+// At most maxConcurrency sends run at once; the rest queue until a slot frees up
+func (s *Sender) sendAsync(status analyzer.Status, message, sessionID string, details Details, entry *spoolEntry) {
 	s.wg.Add(1)
 	// Use SafeGo to protect against panics in async webhook sending
 	errorhandler.SafeGo(func() {
 		defer s.wg.Done()
 
-		if err := s.Send(status, message, sessionID); err != nil {
+		// Sends still queued when a shutdown times out are dropped, spooled ones stay for replay
+		select {
+		case s.asyncSlots <- struct{}{}:
+			defer func() { <-s.asyncSlots }()
+		case <-s.ctx.Done():
+			logging.Warn("Queued %s webhook for session %s dropped by shutdown", status, sessionID)
+			return
+		}
+
+		batched, err := s.send(status, message, sessionID, details, entry)
+		if err != nil {
 			errorhandler.HandleError(err, "Async webhook send failed")
 		}
+
+		// A batched entry stays in the spool until its batch is delivered
+		if entry != nil && !batched {
+			s.removeSpooled(entry)
+		}
 	})
 }
 
+// removeSpooled deletes settled notifications from the spool
+// Entries interrupted by shutdown are kept so a later process can replay them
+func (s *Sender) removeSpooled(entries ...*spoolEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	if s.ctx.Err() != nil {
+		logging.Warn("Webhook interrupted by shutdown, left in spool for replay")
+		return
+	}
+	for _, entry := range entries {
+		if err := s.spool.Remove(entry); err != nil {
+			logging.Warn("%v", err)
+		}
+	}
+}
+
+// maxConcurrency returns the configured async send limit, or defaultMaxConcurrency if unset
+func maxConcurrency(limit int) int {
+	if limit <= 0 {
+		return defaultMaxConcurrency
+	}
+	return limit
+}
+
+// replaySpool re-sends notifications that earlier processes spooled but never finished
+func (s *Sender) replaySpool() {
+	entries, err := s.spool.Claim()
+	if err != nil {
+		logging.Warn("Failed to read webhook spool: %v", err)
+		return
+	}
+
+	for _, entry := range entries {
+		logging.Info("Replaying spooled webhook for session %s (status: %s)", entry.SessionID, entry.Status)
+		s.sendAsync(entry.Status, entry.Message, entry.SessionID, Details{CWD: entry.CWD, EventTime: time.Unix(entry.CreatedAt, 0)}, entry)
+	}
+}
+
 // Shutdown gracefully shuts down the webhook sender
 // Waits for in-flight requests to complete (with timeout)
 // Only cancels context if timeout is reached
 func (s *Sender) Shutdown(timeout time.Duration) error {
 	logging.Info("Shutting down webhook sender...")
 
+	// Don't hold open batches for the rest of their window
+	if s.batcher != nil {
+		s.batcher.FlushAll()
+	}
+
 	// Wait for in-flight requests with timeout
 	// Do NOT cancel context immediately - let requests complete gracefully
 	done := make(chan struct{})
@@ -292,8 +916,250 @@ func (s *Sender) GetMetrics() Stats {
 	return s.metrics.GetStats()
 }
 
+// DumpMetricsJSON returns the current metrics as indented JSON, e.g. to save a snapshot at shutdown
+func (s *Sender) DumpMetricsJSON() ([]byte, error) {
+	return json.MarshalIndent(s.GetMetrics(), "", "  ")
+}
+
+// ResetMetrics zeroes the metrics so the next snapshot only covers later sends
+// The circuit breakers themselves are not reset; their current states are kept in the metrics
+func (s *Sender) ResetMetrics() {
+	s.metrics.Reset()
+	s.recordCircuitStates()
+}
+
 // Helper functions
 
+// parseHTTPTimeout parses the configured request timeout, defaulting to defaultHTTPTimeout
+// An invalid value falls back to the default and is reported as an error
+func parseHTTPTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultHTTPTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return defaultHTTPTimeout, fmt.Errorf("invalid webhook timeout: %q", value)
+	}
+	return timeout, nil
+}
+
+// newTransport creates the HTTP transport, routing requests through proxyURL when set
+// Without an explicit proxy, HTTPS_PROXY/HTTP_PROXY/NO_PROXY from the environment are respected.
+// SOCKS5 proxies are supported via the socks5:// scheme.
+func newTransport(proxyURL string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			logging.Warn("Invalid webhook proxy URL, falling back to environment: %v", err)
+			return transport
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	return transport
+}
+
+// destination is a resolved webhook endpoint with its formatter
+type destination struct {
+	config.WebhookDestination
+	formatter Formatter          // nil for custom payloads
+	template  *template.Template // set for the "template" format
+	footer    *template.Template // nil for the formatters' default footer
+	matcher   *successMatcher    // nil unless successMatch is configured
+	breaker   *CircuitBreaker    // this destination's own breaker, nil when disabled and for fallbacks
+	limiter   *RateLimiter       // this destination's own rate limit, nil when disabled
+	fallback  bool               // only used when the routed destinations fail
+}
+
+// newDestination resolves a configured destination with its formatter, template and matcher
+// On error the destination is still returned, so the sender can be built and report the error on Send
+func newDestination(dest config.WebhookDestination) (destination, error) {
+	var errs []error
+	if err := resolveDestinationEnv(&dest); err != nil {
+		errs = append(errs, err)
+	}
+	d := destination{
+		WebhookDestination: dest,
+		formatter:          newFormatter(dest),
+	}
+	if d.formatter == nil && dest.Format == "template" {
+		tmpl, err := parsePayloadTemplate(dest.Name, dest.Template)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		d.template = tmpl
+	}
+	if dest.FooterTemplate != "" {
+		tmpl, err := parseFooterTemplate(dest.Name, dest.FooterTemplate)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		d.footer = tmpl
+	}
+	successMatch := dest.SuccessMatch
+	if d.threaded() && successMatch == (config.SuccessMatchConfig{}) {
+		// chat.postMessage reports errors as {"ok": false} with a 200 status
+		successMatch = config.SuccessMatchConfig{JSONPath: "ok", Equals: "true"}
+	}
+	matcher, err := newSuccessMatcher(successMatch)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	d.matcher = matcher
+	if len(errs) > 0 {
+		return d, errs[0]
+	}
+	return d, nil
+}
+
+// resolveDestinationEnv interpolates ${VAR} references in the URL and header values
+// from the environment, so secrets can stay out of the config file
+// A reference to an unset variable is an error rather than an empty string
+func resolveDestinationEnv(dest *config.WebhookDestination) error {
+	resolvedURL, err := platform.ExpandEnvStrict(dest.URL)
+	if err != nil {
+		return fmt.Errorf("url: %w", err)
+	}
+	dest.URL = resolvedURL
+
+	if len(dest.Headers) == 0 {
+		return nil
+	}
+	headers := make(map[string]string, len(dest.Headers))
+	for key, value := range dest.Headers {
+		resolved, err := platform.ExpandEnvStrict(value)
+		if err != nil {
+			return fmt.Errorf("header %s: %w", key, err)
+		}
+		headers[key] = resolved
+	}
+	dest.Headers = headers
+	return nil
+}
+
+// newDestinationBreaker returns a circuit breaker for one destination, nil when disabled
+func newDestinationBreaker(cfg config.CircuitBreakerConfig) *CircuitBreaker {
+	if !cfg.Enabled {
+		return nil
+	}
+	timeout, _ := time.ParseDuration(cfg.Timeout)
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	cb := NewCircuitBreaker(cfg.FailureThreshold, cfg.SuccessThreshold, timeout)
+	cb.SetMaxHalfOpenProbes(cfg.MaxHalfOpenProbes)
+	maxTimeout, _ := time.ParseDuration(cfg.MaxTimeout)
+	resetAfter, _ := time.ParseDuration(cfg.ResetAfter)
+	cb.SetBackoff(maxTimeout, resetAfter)
+	return cb
+}
+
+// newDestinationLimiter returns a rate limiter for one destination, nil when disabled
+func newDestinationLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	if !cfg.Enabled {
+		return nil
+	}
+	return NewRateLimiter(cfg.RequestsPerMinute)
+}
+
+// newFormatter returns the formatter for a destination's preset
+// Returns nil if the preset has no formatter (custom)
+func newFormatter(dest config.WebhookDestination) Formatter {
+	formatters := map[string]Formatter{
+		"slack":      newSlackFormatter(dest),
+		"discord":    &DiscordFormatter{ButtonURL: dest.DiscordButtonURL, Mention: dest.Mention, MentionStatuses: dest.MentionStatuses},
+		"telegram":   &TelegramFormatter{ChatID: dest.ChatID},
+		"lark":       &LarkFormatter{},
+		"teams":      &TeamsFormatter{},
+		"googlechat": &GoogleChatFormatter{},
+		"matrix":     &MatrixFormatter{},
+		"ntfy":       &NtfyFormatter{Topic: dest.Topic},
+		"pushover":   &PushoverFormatter{Token: dest.Token, User: dest.User},
+		"rocketchat": &RocketChatFormatter{},
+		"pagerduty":  &PagerDutyFormatter{RoutingKey: dest.RoutingKey},
+		"opsgenie":   &OpsGenieFormatter{APIKey: dest.Token},
+		"gotify":     &GotifyFormatter{Token: dest.Token},
+		"wecom":      &WeComFormatter{},
+		"zulip":      &ZulipFormatter{Stream: dest.Stream, Topic: dest.Topic},
+		"mattermost": &MattermostFormatter{},
+		"console":    &ConsoleFormatter{},
+	}
+
+	return formatters[dest.Preset]
+}
+
+// FormatError reports a preset formatter that returned an error or panicked while building a payload
+type FormatError struct {
+	Preset string
+	Err    error
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("%s formatter failed: %v", e.Preset, e.Err)
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// DestinationError is returned when delivery to a specific destination fails
+type DestinationError struct {
+	Destination string
+	Err         error
+}
+
+func (e *DestinationError) Error() string {
+	return fmt.Sprintf("destination %s: %v", e.Destination, e.Err)
+}
+
+func (e *DestinationError) Unwrap() error {
+	return e.Err
+}
+
+// SendError describes a failed delivery to a destination
+// Use errors.As on the error returned by Send to inspect it
+type SendError struct {
+	Destination string
+	RequestID   string
+	StatusCode  int  // HTTP status code, 0 if no response was received
+	Retryable   bool // whether sending again may succeed
+	Err         error
+}
+
+func newSendError(requestID, destination string, statusCode int, retryable bool, err error) *SendError {
+	return &SendError{
+		Destination: destination,
+		RequestID:   requestID,
+		StatusCode:  statusCode,
+		Retryable:   retryable,
+		Err:         err,
+	}
+}
+
+func (e *SendError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// joinDestinationErrors aggregates per-destination errors
+// A single failure is returned as-is so callers can inspect it directly
+func joinDestinationErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
+	}
+}
+
 // parseRetryConfig converts config.RetryConfig to webhook.RetryConfig
 func parseRetryConfig(cfg config.RetryConfig) RetryConfig {
 	initialBackoff, _ := time.ParseDuration(cfg.InitialBackoff)
@@ -306,13 +1172,30 @@ func parseRetryConfig(cfg config.RetryConfig) RetryConfig {
 		maxBackoff = 10 * time.Second
 	}
 
-	return RetryConfig{
+	maxElapsedTime, _ := time.ParseDuration(cfg.MaxElapsedTime)
+
+	retryConfig := RetryConfig{
 		Enabled:        cfg.Enabled,
 		MaxAttempts:    cfg.MaxAttempts,
 		InitialBackoff: initialBackoff,
 		MaxBackoff:     maxBackoff,
 		Multiplier:     2.0,
+		Jitter:         JitterMode(cfg.Jitter),
+		MaxElapsedTime: maxElapsedTime,
 	}
+
+	if quick := cfg.QuickRetry; quick.Enabled {
+		retryConfig.QuickRetries = quick.Attempts
+		if retryConfig.QuickRetries == 0 {
+			retryConfig.QuickRetries = DefaultQuickRetries
+		}
+		retryConfig.QuickDelay = DefaultQuickDelay
+		if delay, err := time.ParseDuration(quick.Delay); err == nil && quick.Delay != "" {
+			retryConfig.QuickDelay = delay
+		}
+	}
+
+	return retryConfig
 }
 
 // validateURL validates the webhook URL
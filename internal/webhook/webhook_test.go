@@ -2,16 +2,21 @@ package webhook
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/777genius/claude-notifications/internal/analyzer"
 	"github.com/777genius/claude-notifications/internal/config"
+	"github.com/777genius/claude-notifications/internal/logging"
 )
 
 func newTestConfig(url string) *config.Config {
@@ -47,6 +52,71 @@ func newTestConfig(url string) *config.Config {
 	}
 }
 
+func TestSenderStatusStyleOverrides(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "telegram"
+	cfg.Notifications.Webhook.ChatID = "123"
+	cfg.Statuses["task_complete"] = config.StatusInfo{Title: "Task Complete", Emoji: "🎉", TitlePrefix: "[prod] "}
+
+	if err := New(cfg).Send(analyzer.StatusTaskComplete, "Done", "session-1"); err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+
+	text, _ := body["text"].(string)
+	if !strings.HasPrefix(text, "<b>🎉 [prod] Task Complete</b>") {
+		t.Errorf("Expected overridden emoji and title prefix, got %q", text)
+	}
+	if strings.Contains(text, "✅") {
+		t.Errorf("Expected built-in emoji to be replaced, got %q", text)
+	}
+}
+
+func TestSenderCustomStatus(t *testing.T) {
+	status := analyzer.Status("deploy_finished")
+	if err := analyzer.RegisterStatus(status, config.StatusInfo{Title: "Deploy Finished", Emoji: "🚀", Color: "#8e44ad"}); err != nil {
+		t.Fatalf("Failed to register status: %v", err)
+	}
+	t.Cleanup(func() { config.UnregisterStatus(string(status)) })
+
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "slack"
+	if err := New(cfg).Send(status, "Deployed v1.2.3", "session-1"); err != nil {
+		t.Fatalf("Expected Slack send to succeed, got %v", err)
+	}
+	attachments, _ := body["attachments"].([]interface{})
+	if len(attachments) != 1 {
+		t.Fatalf("Expected 1 Slack attachment, got %v", body["attachments"])
+	}
+	attachment := attachments[0].(map[string]interface{})
+	if attachment["color"] != "#8e44ad" || attachment["title"] != "Deploy Finished" {
+		t.Errorf("Expected registered color and title, got %v / %v", attachment["color"], attachment["title"])
+	}
+
+	cfg = newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "telegram"
+	cfg.Notifications.Webhook.ChatID = "123"
+	if err := New(cfg).Send(status, "Deployed v1.2.3", "session-1"); err != nil {
+		t.Fatalf("Expected Telegram send to succeed, got %v", err)
+	}
+	if text, _ := body["text"].(string); !strings.HasPrefix(text, "<b>🚀 Deploy Finished</b>") {
+		t.Errorf("Expected registered emoji and title, got %q", text)
+	}
+}
+
 func TestSenderSendSuccess(t *testing.T) {
 	// Mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -73,6 +143,397 @@ func TestSenderSendSuccess(t *testing.T) {
 	}
 }
 
+func TestSenderDryRun(t *testing.T) {
+	logDir := t.TempDir()
+	if _, err := logging.InitLogger(logDir); err != nil {
+		t.Fatalf("InitLogger failed: %v", err)
+	}
+	defer logging.Close()
+
+	requests := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.DryRun = true
+	sender := New(cfg)
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Dry run message", "session-123"); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+
+	if requests.Load() != 0 {
+		t.Errorf("Expected no HTTP requests in dry run, got %d", requests.Load())
+	}
+
+	stats := sender.GetMetrics()
+	if stats.SuccessfulRequests != 1 {
+		t.Errorf("Expected dry run to count as a successful request, got %d", stats.SuccessfulRequests)
+	}
+
+	content, err := os.ReadFile(filepath.Join(logDir, "notification-debug.log"))
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(content), "Dry run") || !strings.Contains(string(content), server.URL) || !strings.Contains(string(content), "Dry run message") {
+		t.Errorf("Expected payload and URL to be logged, got:\n%s", content)
+	}
+}
+
+func TestSenderSendErrorClassification(t *testing.T) {
+	tests := []struct {
+		name             string
+		statusCode       int
+		retryable        bool
+		expectedAttempts int32
+	}{
+		{"400 is permanent", http.StatusBadRequest, false, 1},
+		{"403 is permanent", http.StatusForbidden, false, 1},
+		{"503 is retryable", http.StatusServiceUnavailable, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := atomic.Int32{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			cfg := newTestConfig(server.URL)
+			cfg.Notifications.Webhook.CircuitBreaker.Enabled = false
+			sender := New(cfg)
+
+			err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123")
+
+			var sendErr *SendError
+			if !errors.As(err, &sendErr) {
+				t.Fatalf("Expected SendError, got %T: %v", err, err)
+			}
+			if sendErr.StatusCode != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, sendErr.StatusCode)
+			}
+			if sendErr.Retryable != tt.retryable {
+				t.Errorf("Expected retryable %v, got %v", tt.retryable, sendErr.Retryable)
+			}
+			if sendErr.Destination != "default" {
+				t.Errorf("Expected destination 'default', got %q", sendErr.Destination)
+			}
+			if sendErr.RequestID == "" {
+				t.Error("Expected request ID to be set")
+			}
+			if attempts.Load() != tt.expectedAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.expectedAttempts, attempts.Load())
+			}
+		})
+	}
+}
+
+func TestSender429HonorsRetryAfter(t *testing.T) {
+	var attemptTimes []time.Time
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attemptTimes = append(attemptTimes, time.Now())
+		first := len(attemptTimes) == 1
+		mu.Unlock()
+
+		if first {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	sender := New(cfg)
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123"); err != nil {
+		t.Fatalf("Expected success after Retry-After, got: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attemptTimes) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(attemptTimes))
+	}
+	// Configured backoff is at most 100ms, so waiting a second means Retry-After was used
+	if wait := attemptTimes[1].Sub(attemptTimes[0]); wait < time.Second {
+		t.Errorf("Expected to wait Retry-After (1s), waited %v", wait)
+	}
+}
+
+func TestSenderSendErrorBeforeRequest(t *testing.T) {
+	cfg := newTestConfig("ftp://example.com/hook")
+	sender := New(cfg)
+
+	err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123")
+
+	var sendErr *SendError
+	if !errors.As(err, &sendErr) {
+		t.Fatalf("Expected SendError, got %T: %v", err, err)
+	}
+	if sendErr.StatusCode != 0 || sendErr.Retryable {
+		t.Errorf("Expected non-retryable error without status code, got %+v", sendErr)
+	}
+}
+
+func TestNewSenderInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(w *config.WebhookConfig)
+		wantErr string
+	}{
+		{"empty URL", func(w *config.WebhookConfig) { w.URL = "" }, "webhook URL is required"},
+		{"unknown preset", func(w *config.WebhookConfig) { w.Preset = "smoke-signals" }, "invalid webhook preset: smoke-signals"},
+		{"telegram without chat_id", func(w *config.WebhookConfig) { w.Preset = "telegram" }, "chat_id is required for Telegram webhook"},
+		{"bad initial backoff", func(w *config.WebhookConfig) { w.Retry.InitialBackoff = "1 second" }, "invalid webhook retry initialBackoff: 1 second"},
+		{"bad max backoff", func(w *config.WebhookConfig) { w.Retry.MaxBackoff = "-5s" }, "invalid webhook retry maxBackoff: -5s"},
+		{"zero retry attempts", func(w *config.WebhookConfig) { w.Retry.MaxAttempts = 0 }, "webhook retry maxAttempts must be >= 1"},
+		{"bad circuit breaker timeout", func(w *config.WebhookConfig) { w.CircuitBreaker.Timeout = "later" }, "invalid webhook circuitBreaker timeout: later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig("https://example.com/webhook")
+			tt.modify(&cfg.Notifications.Webhook)
+
+			if err := ValidateConfig(cfg); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected ValidateConfig error containing %q, got %v", tt.wantErr, err)
+			}
+			if _, err := NewSender(cfg); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected NewSender error containing %q, got %v", tt.wantErr, err)
+			}
+			if err := New(cfg).Send(analyzer.StatusTaskComplete, "msg", "session-123"); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected Send to return the config error containing %q, got %v", tt.wantErr, err)
+			}
+
+			// Disabled webhooks are never checked
+			cfg.Notifications.Webhook.Enabled = false
+			if err := ValidateConfig(cfg); err != nil {
+				t.Errorf("Expected no error with webhooks disabled, got %v", err)
+			}
+		})
+	}
+}
+
+// panickingFormatter simulates a buggy preset formatter
+type panickingFormatter struct{}
+
+func (panickingFormatter) Format(status analyzer.Status, message, sessionID string, statusInfo config.StatusInfo, details Details) (interface{}, error) {
+	panic("index out of range")
+}
+
+func TestSenderRecoversFormatterPanic(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "slack"
+	sender := New(cfg)
+	sender.destinations[0].formatter = panickingFormatter{}
+
+	err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123")
+
+	var formatErr *FormatError
+	if !errors.As(err, &formatErr) {
+		t.Fatalf("Expected FormatError, got %T: %v", err, err)
+	}
+	if formatErr.Preset != "slack" || !strings.Contains(err.Error(), "slack formatter failed: panic: index out of range") {
+		t.Errorf("Expected the error to name the preset and the panic, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no HTTP request for an unformattable payload, got %d", requests.Load())
+	}
+
+	stats := sender.GetMetrics()
+	if stats.FormatFailures != 1 || stats.FailedRequests != 1 {
+		t.Errorf("Expected one format failure, got %d format and %d total failures", stats.FormatFailures, stats.FailedRequests)
+	}
+
+	// The circuit breaker only counts failed HTTP deliveries
+	if state := sender.destinations[0].breaker.GetState(); state != StateClosed {
+		t.Errorf("Expected the breaker to stay closed, got %v", state)
+	}
+}
+
+func TestSenderEnvInterpolation(t *testing.T) {
+	var auth, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		path = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_WEBHOOK_TOKEN", "secret-token")
+	t.Setenv("TEST_WEBHOOK_PATH", "bot123")
+
+	cfg := newTestConfig(server.URL + "/${TEST_WEBHOOK_PATH}/sendMessage")
+	cfg.Notifications.Webhook.Headers = map[string]string{"Authorization": "Bearer ${TEST_WEBHOOK_TOKEN}"}
+	if err := New(cfg).Send(analyzer.StatusTaskComplete, "msg", "session-1"); err != nil {
+		t.Fatalf("Expected send to succeed, got %v", err)
+	}
+
+	if auth != "Bearer secret-token" {
+		t.Errorf("Expected header resolved from the environment, got %q", auth)
+	}
+	if path != "/bot123/sendMessage" {
+		t.Errorf("Expected URL resolved from the environment, got %q", path)
+	}
+	if cfg.Notifications.Webhook.Headers["Authorization"] != "Bearer ${TEST_WEBHOOK_TOKEN}" {
+		t.Error("Expected config headers to be left untouched")
+	}
+}
+
+func TestSenderEnvInterpolationMissingVariable(t *testing.T) {
+	cfg := newTestConfig("https://example.com/webhook")
+	cfg.Notifications.Webhook.Headers = map[string]string{"Authorization": "Bearer ${TEST_WEBHOOK_UNSET_TOKEN}"}
+
+	_, err := NewSender(cfg)
+	if err == nil || !strings.Contains(err.Error(), "TEST_WEBHOOK_UNSET_TOKEN is not set") {
+		t.Errorf("Expected missing variable error, got %v", err)
+	}
+
+	cfg = newTestConfig("https://example.com/${TEST_WEBHOOK_UNSET_PATH}")
+	if _, err := NewSender(cfg); err == nil || !strings.Contains(err.Error(), "url") {
+		t.Errorf("Expected missing URL variable error, got %v", err)
+	}
+}
+
+func TestSenderHTTPTimeout(t *testing.T) {
+	cfg := newTestConfig("https://example.com/webhook")
+	if timeout := New(cfg).client.Timeout; timeout != defaultHTTPTimeout {
+		t.Errorf("Expected default timeout %v, got %v", defaultHTTPTimeout, timeout)
+	}
+
+	cfg.Notifications.Webhook.Timeout = "45s"
+	if timeout := New(cfg).client.Timeout; timeout != 45*time.Second {
+		t.Errorf("Expected configured timeout 45s, got %v", timeout)
+	}
+
+	cfg.Notifications.Webhook.Timeout = "soon"
+	if _, err := NewSender(cfg); err == nil || !strings.Contains(err.Error(), "invalid webhook timeout") {
+		t.Errorf("Expected invalid timeout error, got %v", err)
+	}
+}
+
+func TestSenderHTTPTimeoutApplied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Timeout = "50ms"
+	cfg.Notifications.Webhook.Retry.Enabled = false
+	sender := New(cfg)
+
+	err := sender.Send(analyzer.StatusTaskComplete, "slow", "session-123")
+	if err == nil {
+		t.Fatal("Expected request to time out")
+	}
+	var sendErr *SendError
+	if !errors.As(err, &sendErr) || !sendErr.Retryable {
+		t.Errorf("Expected retryable SendError for timeout, got %v", err)
+	}
+}
+
+func TestSenderMutedStatus(t *testing.T) {
+	requests := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.MutedStatuses = []string{"question"}
+	sender := New(cfg)
+
+	if err := sender.Send(analyzer.StatusQuestion, "Muted message", "session-123"); err != nil {
+		t.Fatalf("Expected muted status to be skipped without error, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no HTTP request for muted status, got %d", requests.Load())
+	}
+
+	stats := sender.GetMetrics()
+	if stats.TotalRequests != 0 || stats.FailedRequests != 0 {
+		t.Errorf("Expected muted status not to be counted, got total=%d failed=%d", stats.TotalRequests, stats.FailedRequests)
+	}
+
+	// Other statuses are still delivered
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123"); err != nil {
+		t.Fatalf("Expected success, got error: %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 HTTP request for unmuted status, got %d", requests.Load())
+	}
+}
+
+func TestSenderSuccessMatchLogicalFailure(t *testing.T) {
+	attempts := atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.SuccessMatch = config.SuccessMatchConfig{JSONPath: "ok", Equals: "true"}
+	sender := New(cfg)
+
+	err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123")
+	if err == nil {
+		t.Fatal("Expected error for 200 response reporting failure")
+	}
+
+	var mismatchErr *ResponseMismatchError
+	if !errors.As(err, &mismatchErr) {
+		t.Fatalf("Expected ResponseMismatchError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), "chat not found") {
+		t.Errorf("Expected response body in error, got %v", err)
+	}
+
+	// Logical rejections are not retried
+	if attempts.Load() != 1 {
+		t.Errorf("Expected 1 attempt, got %d", attempts.Load())
+	}
+	if stats := sender.GetMetrics(); stats.FailedRequests != 1 {
+		t.Errorf("Expected 1 failed request, got %d", stats.FailedRequests)
+	}
+}
+
+func TestSenderSuccessMatchSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":42}}`))
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.SuccessMatch = config.SuccessMatchConfig{JSONPath: "ok", Equals: "true"}
+	sender := New(cfg)
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123"); err != nil {
+		t.Errorf("Expected success, got error: %v", err)
+	}
+}
+
 func TestSenderSendWithRetry(t *testing.T) {
 	attempts := atomic.Int32{}
 
@@ -178,6 +639,37 @@ func TestSenderSendRateLimit(t *testing.T) {
 	}
 }
 
+func TestSenderSendPerSessionRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.RateLimit.Enabled = true
+	cfg.Notifications.Webhook.RateLimit.RequestsPerMinute = 60
+	cfg.Notifications.Webhook.RateLimit.PerSessionRequestsPerMinute = 2
+	sender := New(cfg)
+
+	for i := 0; i < 2; i++ {
+		if err := sender.Send(analyzer.StatusTaskComplete, "Test", "chatty"); err != nil {
+			t.Fatalf("Send %d for chatty session failed: %v", i+1, err)
+		}
+	}
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Test", "chatty"); err != ErrRateLimitExceeded {
+		t.Errorf("Expected chatty session to be throttled, got: %v", err)
+	}
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Test", "quiet"); err != nil {
+		t.Errorf("Expected other session to proceed, got: %v", err)
+	}
+
+	if stats := sender.GetMetrics(); stats.RateLimitedRequests != 1 {
+		t.Errorf("Expected 1 rate limited request, got %d", stats.RateLimitedRequests)
+	}
+}
+
 func TestSenderSendSlackFormat(t *testing.T) {
 	var receivedPayload map[string]interface{}
 
@@ -275,6 +767,38 @@ func TestSenderSendTelegramFormat(t *testing.T) {
 	}
 }
 
+func TestSenderCustomPayloadFieldMap(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.FieldMap = map[string]string{
+		"status":     "event",
+		"message":    "text",
+		"session_id": "sid",
+	}
+	if err := New(cfg).Send(analyzer.StatusTaskComplete, "All done", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if body["event"] != "task_complete" || body["text"] != "All done" || body["sid"] != "session-123" {
+		t.Errorf("Expected remapped fields, got %v", body)
+	}
+	for _, old := range []string{"status", "message", "session_id"} {
+		if _, ok := body[old]; ok {
+			t.Errorf("Expected %s to be renamed, got %v", old, body)
+		}
+	}
+	// Unmapped fields keep their names
+	if body["source"] != "claude-notifications" || body["timestamp"] == nil {
+		t.Errorf("Expected unmapped fields unchanged, got %v", body)
+	}
+}
+
 func TestSenderSendCustomHeaders(t *testing.T) {
 	var receivedHeaders http.Header
 
@@ -316,6 +840,80 @@ func TestSenderSendCustomHeaders(t *testing.T) {
 	}
 }
 
+func TestSenderUserAgentAndCorrelationHeader(t *testing.T) {
+	var receivedHeaders http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.UserAgent = "my-tool/2.3"
+	cfg.Notifications.Webhook.CorrelationHeader = "X-Correlation-ID"
+	sender := New(cfg)
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if got := receivedHeaders.Get("User-Agent"); got != "my-tool/2.3" {
+		t.Errorf("Expected configured User-Agent, got %q", got)
+	}
+	if got := receivedHeaders.Get("X-Correlation-ID"); got != "session-123" {
+		t.Errorf("Expected correlation header to carry session ID, got %q", got)
+	}
+	if receivedHeaders.Get("X-Request-ID") == "" {
+		t.Error("X-Request-ID not set")
+	}
+
+	// Custom headers still win
+	cfg.Notifications.Webhook.Headers = map[string]string{
+		"User-Agent":       "override/1.0",
+		"X-Correlation-ID": "fixed",
+	}
+	sender = New(cfg)
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Test", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if got := receivedHeaders.Get("User-Agent"); got != "override/1.0" {
+		t.Errorf("Expected custom User-Agent header to override, got %q", got)
+	}
+	if got := receivedHeaders.Get("X-Correlation-ID"); got != "fixed" {
+		t.Errorf("Expected custom correlation header to override, got %q", got)
+	}
+}
+
+func TestSenderGotifyAuthHeader(t *testing.T) {
+	var receivedHeaders http.Header
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedHeaders = r.Header.Clone()
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "gotify"
+	cfg.Notifications.Webhook.Token = "app-token"
+	sender := New(cfg)
+
+	if err := sender.Send(analyzer.StatusQuestion, "Which database?", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if got := receivedHeaders.Get("X-Gotify-Key"); got != "app-token" {
+		t.Errorf("Expected X-Gotify-Key header 'app-token', got %q", got)
+	}
+	if received["priority"] != float64(8) {
+		t.Errorf("Expected priority 8 for question, got %v", received["priority"])
+	}
+}
+
 func TestSenderSendDisabled(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Error("Server should not be called when webhooks disabled")
@@ -578,6 +1176,81 @@ func TestSenderSendAsyncWithShutdown(t *testing.T) {
 	}
 }
 
+func TestSenderSendAsyncMaxConcurrency(t *testing.T) {
+	var inFlight, peak, received atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			max := peak.Load()
+			if n <= max || peak.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+		received.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.MaxConcurrency = 2
+	sender := New(cfg)
+
+	// A burst well over the limit queues instead of opening a connection per send
+	numRequests := 12
+	for i := 0; i < numRequests; i++ {
+		sender.SendAsync(analyzer.StatusTaskComplete, "Burst message", "session-123")
+	}
+
+	if err := sender.Shutdown(5 * time.Second); err != nil {
+		t.Fatalf("Shutdown should drain the queue, got: %v", err)
+	}
+
+	if got := received.Load(); got != int32(numRequests) {
+		t.Errorf("Expected %d requests to be received, got %d", numRequests, got)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", got)
+	}
+}
+
+func TestSenderShutdownDropsQueuedSends(t *testing.T) {
+	var received atomic.Int32
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.MaxConcurrency = 1
+	cfg.Notifications.Webhook.Retry.Enabled = false
+	sender := New(cfg)
+
+	for i := 0; i < 3; i++ {
+		sender.SendAsync(analyzer.StatusTaskComplete, "Stuck message", "session-123")
+	}
+
+	if err := sender.Shutdown(100 * time.Millisecond); err == nil {
+		t.Fatal("Expected shutdown to time out while a send is stuck")
+	}
+	sender.wg.Wait()
+
+	// Only the send holding the slot reached the server, the queued ones were dropped
+	if got := received.Load(); got != 1 {
+		t.Errorf("Expected 1 request to reach the server, got %d", got)
+	}
+}
+
 // TestWebhookShutdownWaitsForRequests verifies that Shutdown actually waits
 // for in-flight requests to complete, not just returns immediately
 func TestWebhookShutdownWaitsForRequests(t *testing.T) {
@@ -620,3 +1293,353 @@ func TestWebhookShutdownWaitsForRequests(t *testing.T) {
 		t.Error("Request should have completed before Shutdown returned")
 	}
 }
+
+func TestSenderDropsStaleEvents(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.MaxEventAge = "10m"
+	sender := New(cfg)
+
+	stale := Details{EventTime: time.Now().Add(-15 * time.Minute)}
+	if err := sender.SendWithDetails(analyzer.StatusTaskComplete, "Old news", "session-123", stale); err != nil {
+		t.Fatalf("Expected a stale event to be skipped without error, got %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no HTTP request for a stale event, got %d", requests.Load())
+	}
+
+	fresh := Details{EventTime: time.Now().Add(-time.Minute)}
+	if err := sender.SendWithDetails(analyzer.StatusTaskComplete, "Fresh news", "session-123", fresh); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	// Events without a timestamp are never too old
+	if err := sender.Send(analyzer.StatusTaskComplete, "Undated news", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected the fresh and undated events to be delivered, got %d requests", requests.Load())
+	}
+}
+
+func TestSenderSendMultipleDestinations(t *testing.T) {
+	var slackPayload, telegramPayload map[string]interface{}
+
+	slackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &slackPayload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slackServer.Close()
+
+	telegramServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &telegramPayload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer telegramServer.Close()
+
+	cfg := newTestConfig("")
+	cfg.Notifications.Webhook.Destinations = []config.WebhookDestination{
+		{Name: "team", Preset: "slack", URL: slackServer.URL},
+		{Name: "me", Preset: "telegram", URL: telegramServer.URL, ChatID: "42"},
+	}
+	sender := New(cfg)
+
+	err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123")
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	// Each destination gets its own preset payload
+	if _, ok := slackPayload["attachments"]; !ok {
+		t.Errorf("Expected Slack attachments, got %v", slackPayload)
+	}
+	if telegramPayload["chat_id"] != "42" {
+		t.Errorf("Expected Telegram chat_id 42, got %v", telegramPayload["chat_id"])
+	}
+
+	stats := sender.GetMetrics()
+	if stats.SuccessfulRequests != 2 {
+		t.Errorf("Expected 2 successful requests, got %d", stats.SuccessfulRequests)
+	}
+	if stats.DestinationStats["team"].SuccessfulRequests != 1 {
+		t.Errorf("Expected 1 success for team, got %d", stats.DestinationStats["team"].SuccessfulRequests)
+	}
+	if stats.DestinationStats["me"].SuccessfulRequests != 1 {
+		t.Errorf("Expected 1 success for me, got %d", stats.DestinationStats["me"].SuccessfulRequests)
+	}
+}
+
+func TestSenderSendMultipleDestinationsPartialFailure(t *testing.T) {
+	received := atomic.Int32{}
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failingServer.Close()
+
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer okServer.Close()
+
+	cfg := newTestConfig("")
+	cfg.Notifications.Webhook.CircuitBreaker.Enabled = false
+	cfg.Notifications.Webhook.Destinations = []config.WebhookDestination{
+		{Name: "broken", URL: failingServer.URL, Format: "json"},
+		{Name: "working", URL: okServer.URL, Format: "json"},
+	}
+	sender := New(cfg)
+
+	err := sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123")
+	if err == nil {
+		t.Fatal("Expected error from failing destination")
+	}
+
+	// Failing endpoint must not stop the others
+	if received.Load() != 1 {
+		t.Errorf("Expected working destination to receive 1 request, got %d", received.Load())
+	}
+
+	var destErr *DestinationError
+	if !errors.As(err, &destErr) || destErr.Destination != "broken" {
+		t.Errorf("Expected DestinationError for 'broken', got %v", err)
+	}
+
+	stats := sender.GetMetrics()
+	if stats.DestinationStats["broken"].FailedRequests != 1 {
+		t.Errorf("Expected 1 failure for broken, got %d", stats.DestinationStats["broken"].FailedRequests)
+	}
+	if stats.DestinationStats["working"].SuccessfulRequests != 1 {
+		t.Errorf("Expected 1 success for working, got %d", stats.DestinationStats["working"].SuccessfulRequests)
+	}
+}
+
+func TestSenderPerDestinationCircuitBreaker(t *testing.T) {
+	brokenHits := atomic.Int32{}
+	workingHits := atomic.Int32{}
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		brokenHits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failingServer.Close()
+
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		workingHits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer okServer.Close()
+
+	cfg := newTestConfig("")
+	cfg.Notifications.Webhook.Retry.Enabled = false
+	cfg.Notifications.Webhook.CircuitBreaker.Timeout = "1h"
+	cfg.Notifications.Webhook.Destinations = []config.WebhookDestination{
+		{Name: "broken", URL: failingServer.URL, Format: "json"},
+		{Name: "working", URL: okServer.URL, Format: "json"},
+	}
+	sender := New(cfg)
+
+	// Three failures open the broken destination's breaker
+	for i := 0; i < 5; i++ {
+		_ = sender.Send(analyzer.StatusTaskComplete, "Test message", "session-123")
+	}
+
+	if brokenHits.Load() != 3 {
+		t.Errorf("Expected the open breaker to stop requests to broken after 3, got %d", brokenHits.Load())
+	}
+	if workingHits.Load() != 5 {
+		t.Errorf("Expected working to keep receiving every notification, got %d", workingHits.Load())
+	}
+
+	stats := sender.GetMetrics()
+	if stats.BreakerStates["broken"] != StateOpen || stats.BreakerStates["working"] != StateClosed {
+		t.Errorf("Expected broken open and working closed, got %v", stats.BreakerStates)
+	}
+	if stats.CircuitBreakerState != StateOpen {
+		t.Errorf("Expected the overall state to report the open breaker, got %v", stats.CircuitBreakerState)
+	}
+	if stats.CircuitOpenRequests != 2 {
+		t.Errorf("Expected 2 circuit-open rejections, got %d", stats.CircuitOpenRequests)
+	}
+}
+
+func TestSenderPerDestinationRateLimit(t *testing.T) {
+	hits := map[string]*atomic.Int32{"a": {}, "b": {}}
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[name].Add(1)
+			w.WriteHeader(http.StatusOK)
+		}))
+	}
+	serverA, serverB := newServer("a"), newServer("b")
+	defer serverA.Close()
+	defer serverB.Close()
+
+	cfg := newTestConfig("")
+	cfg.Notifications.Webhook.RateLimit = config.RateLimitConfig{Enabled: true, RequestsPerMinute: 2}
+	cfg.Notifications.Webhook.Destinations = []config.WebhookDestination{
+		{Name: "a", URL: serverA.URL, Format: "json"},
+		{Name: "b", URL: serverB.URL, Format: "json"},
+	}
+	cfg.Notifications.Webhook.Routes = map[string]string{"question": "a"}
+	sender := New(cfg)
+
+	// Questions only go to a and use up its bucket
+	_ = sender.Send(analyzer.StatusQuestion, "Which one?", "session-123")
+	_ = sender.Send(analyzer.StatusQuestion, "Which one?", "session-123")
+
+	// b still has capacity; a is skipped without failing the send
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123"); err != nil {
+		t.Fatalf("Expected delivery to b, got %v", err)
+	}
+	if hits["a"].Load() != 2 || hits["b"].Load() != 1 {
+		t.Errorf("Expected 2 requests to a and 1 to b, got %d and %d", hits["a"].Load(), hits["b"].Load())
+	}
+
+	if err := sender.Send(analyzer.StatusQuestion, "Which one?", "session-123"); err != ErrRateLimitExceeded {
+		t.Errorf("Expected ErrRateLimitExceeded once a is exhausted, got %v", err)
+	}
+}
+
+func TestSenderSendRoutes(t *testing.T) {
+	slackReceived := atomic.Int32{}
+	telegramReceived := atomic.Int32{}
+
+	slackServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slackReceived.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slackServer.Close()
+
+	telegramServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		telegramReceived.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer telegramServer.Close()
+
+	cfg := newTestConfig("")
+	cfg.Notifications.Webhook.Destinations = []config.WebhookDestination{
+		{Name: "team", Preset: "slack", URL: slackServer.URL},
+		{Name: "me", Preset: "telegram", URL: telegramServer.URL, ChatID: "42"},
+	}
+	cfg.Notifications.Webhook.Routes = map[string]string{
+		"question":      "team",
+		"task_complete": "me",
+	}
+	sender := New(cfg)
+
+	if err := sender.Send(analyzer.StatusQuestion, "Question?", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if slackReceived.Load() != 1 || telegramReceived.Load() != 1 {
+		t.Errorf("Expected one request per routed destination, got slack=%d telegram=%d",
+			slackReceived.Load(), telegramReceived.Load())
+	}
+
+	// Unmapped status falls back to every destination
+	if err := sender.Send(analyzer.StatusPlanReady, "Plan", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if slackReceived.Load() != 2 || telegramReceived.Load() != 2 {
+		t.Errorf("Expected unmapped status to reach all destinations, got slack=%d telegram=%d",
+			slackReceived.Load(), telegramReceived.Load())
+	}
+}
+
+func TestSenderProxy(t *testing.T) {
+	tests := []struct {
+		name  string
+		proxy string
+	}{
+		{"http proxy", "http://proxy.corp.example:3128"},
+		{"socks5 proxy", "socks5://127.0.0.1:1080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig("https://hooks.example.com/webhook")
+			cfg.Notifications.Webhook.Proxy = tt.proxy
+			sender := New(cfg)
+
+			transport, ok := sender.client.Transport.(*http.Transport)
+			if !ok || transport.Proxy == nil {
+				t.Fatal("Expected transport with proxy func")
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "https://hooks.example.com/webhook", nil)
+			proxyURL, err := transport.Proxy(req)
+			if err != nil {
+				t.Fatalf("Proxy func failed: %v", err)
+			}
+			if proxyURL == nil || proxyURL.String() != tt.proxy {
+				t.Errorf("Expected proxy %s, got %v", tt.proxy, proxyURL)
+			}
+		})
+	}
+}
+
+func TestSenderProxyFromEnvironment(t *testing.T) {
+	sender := New(newTestConfig("https://hooks.example.com/webhook"))
+
+	transport, ok := sender.client.Transport.(*http.Transport)
+	if !ok || transport.Proxy == nil {
+		t.Fatal("Expected transport to fall back to environment proxy settings")
+	}
+}
+
+func TestSenderFormEncodedBody(t *testing.T) {
+	var contentType, topic string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse form body: %v", err)
+		}
+		topic = r.PostForm.Get("topic")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL)
+	cfg.Notifications.Webhook.Preset = "zulip"
+	cfg.Notifications.Webhook.Stream = "claude"
+
+	if err := New(cfg).Send(analyzer.StatusTaskComplete, "Done", "session-123"); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if contentType != "application/x-www-form-urlencoded" {
+		t.Errorf("Expected form content type, got %q", contentType)
+	}
+	if topic != "session-123" {
+		t.Errorf("Expected topic form value, got %q", topic)
+	}
+}
+
+func TestSenderDisabledByEnv(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv(config.DisabledEnv, "1")
+	sender := New(newTestConfig(server.URL))
+
+	if err := sender.Send(analyzer.StatusTaskComplete, "Done", "session-123"); err != nil {
+		t.Fatalf("Expected disabled send to succeed silently, got %v", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("Expected no HTTP requests while disabled, got %d", n)
+	}
+}